@@ -0,0 +1,12 @@
+-- migrate:up
+
+ALTER TABLE kelionės
+	ADD COLUMN pradžios_taško_platuma decimal,
+	ADD COLUMN pradžios_taško_ilguma decimal,
+	ADD COLUMN atstumas decimal,
+	ADD COLUMN laiko_kaina decimal,
+	ADD COLUMN atstumo_kaina decimal,
+	ADD COLUMN taikyti_limitai varchar (255);
+
+
+-- migrate:down
@@ -0,0 +1,13 @@
+-- migrate:up
+
+ALTER TABLE mokėjimai
+	ADD COLUMN sukurta timestamp with time zone NOT NULL DEFAULT now(),
+	ADD COLUMN fk_Kelione integer,
+	ADD CONSTRAINT apmoka FOREIGN KEY(fk_Kelione) REFERENCES kelionės (id);
+
+ALTER TABLE kelionės
+	ADD COLUMN apmokėjimo_būsena integer,
+	ADD FOREIGN KEY(apmokėjimo_būsena) REFERENCES mokėjimo_būsenos (id);
+
+
+-- migrate:down
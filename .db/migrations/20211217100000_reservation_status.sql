@@ -0,0 +1,36 @@
+-- migrate:up
+
+CREATE TABLE rezervacijų_būsenos
+(
+	id serial,
+	name char (11) NOT NULL,
+	PRIMARY KEY(id)
+);
+INSERT INTO rezervacijų_būsenos(id, name) VALUES (1, 'laukiama');
+INSERT INTO rezervacijų_būsenos(id, name) VALUES (2, 'aktyvi');
+INSERT INTO rezervacijų_būsenos(id, name) VALUES (3, 'kelionėje');
+INSERT INTO rezervacijų_būsenos(id, name) VALUES (4, 'užbaigta');
+INSERT INTO rezervacijų_būsenos(id, name) VALUES (5, 'atšaukta');
+INSERT INTO rezervacijų_būsenos(id, name) VALUES (6, 'pasibaigusi');
+
+ALTER TABLE rezervacijos
+	ADD COLUMN būsena integer NOT NULL DEFAULT 1,
+	ADD FOREIGN KEY(būsena) REFERENCES rezervacijų_būsenos (id);
+
+UPDATE rezervacijos SET būsena = 5 WHERE atšaukta IS NOT NULL;
+UPDATE rezervacijos r SET būsena = 4 FROM kelionės k WHERE k.fk_rezervacija = r.id AND k.pabaigos_laikas IS NOT NULL;
+UPDATE rezervacijos r SET būsena = 3 FROM kelionės k WHERE k.fk_rezervacija = r.id AND k.pabaigos_laikas IS NULL;
+
+-- Only one live reservation of a car or a user survives: a reservation in trip
+-- wins over the ones not started yet, otherwise the newest one wins.
+-- Older trips still in progress are ended and their reservations completed.
+UPDATE kelionės k SET pabaigos_laikas = now() FROM rezervacijos r WHERE k.fk_rezervacija = r.id AND k.pabaigos_laikas IS NULL AND r.būsena = 3 AND EXISTS (SELECT 1 FROM rezervacijos o WHERE o.id > r.id AND o.būsena = 3 AND (o.fk_automobilis = r.fk_automobilis OR o.fk_vartotojas = r.fk_vartotojas));
+UPDATE rezervacijos r SET būsena = 4 WHERE r.būsena = 3 AND EXISTS (SELECT 1 FROM rezervacijos o WHERE o.id > r.id AND o.būsena = 3 AND (o.fk_automobilis = r.fk_automobilis OR o.fk_vartotojas = r.fk_vartotojas));
+UPDATE rezervacijos r SET būsena = 5, atšaukta = now() WHERE r.būsena IN (1, 2) AND EXISTS (SELECT 1 FROM rezervacijos o WHERE o.id <> r.id AND (o.būsena = 3 OR (o.būsena IN (1, 2) AND o.id > r.id)) AND (o.fk_automobilis = r.fk_automobilis OR o.fk_vartotojas = r.fk_vartotojas));
+
+-- A car and a user can hold at most one pending, active or in-trip reservation
+CREATE UNIQUE INDEX rezervacijos_gyva_automobilio ON rezervacijos (fk_Automobilis) WHERE būsena IN (1, 2, 3);
+CREATE UNIQUE INDEX rezervacijos_gyva_vartotojo ON rezervacijos (fk_Vartotojas) WHERE būsena IN (1, 2, 3);
+
+
+-- migrate:down
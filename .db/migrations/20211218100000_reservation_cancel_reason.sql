@@ -0,0 +1,9 @@
+-- migrate:up
+
+ALTER TABLE rezervacijos
+	ADD COLUMN atšaukimo_priežastis varchar (255);
+
+UPDATE rezervacijos SET atšaukimo_priežastis = 'user' WHERE atšaukta IS NOT NULL;
+
+
+-- migrate:down
@@ -0,0 +1,7 @@
+-- migrate:up
+
+ALTER TABLE automobiliai
+	ADD COLUMN minimalus_amžius integer NOT NULL DEFAULT 0;
+
+
+-- migrate:down
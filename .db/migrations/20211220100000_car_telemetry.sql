@@ -0,0 +1,9 @@
+-- migrate:up
+
+ALTER TABLE automobiliai
+	ADD COLUMN kuro_lygis decimal,
+	ADD COLUMN rida decimal,
+	ADD COLUMN telemetrija_gauta timestamp;
+
+
+-- migrate:down
@@ -0,0 +1,16 @@
+-- migrate:up
+
+CREATE TABLE kelionių_taškai
+(
+	platuma decimal NOT NULL,
+	ilguma decimal NOT NULL,
+	užfiksuota timestamp with time zone NOT NULL,
+	id serial,
+	fk_Kelione integer NOT NULL,
+	PRIMARY KEY(id),
+	UNIQUE(fk_Kelione, užfiksuota),
+	CONSTRAINT turi_tašką FOREIGN KEY(fk_Kelione) REFERENCES kelionės (id)
+);
+
+
+-- migrate:down
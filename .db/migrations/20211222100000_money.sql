@@ -0,0 +1,25 @@
+-- migrate:up
+
+ALTER TABLE automobiliai
+	ALTER COLUMN minutės_kaina TYPE bigint USING ROUND(minutės_kaina * 100),
+	ALTER COLUMN valandos_kaina TYPE bigint USING ROUND(valandos_kaina * 100),
+	ALTER COLUMN paros_kaina TYPE bigint USING ROUND(paros_kaina * 100),
+	ALTER COLUMN kilometro_kaina TYPE bigint USING ROUND(kilometro_kaina * 100),
+	ADD COLUMN valiuta char(3) NOT NULL DEFAULT 'EUR';
+
+ALTER TABLE kelionės
+	ALTER COLUMN kaina TYPE bigint USING ROUND(kaina * 100),
+	ALTER COLUMN laiko_kaina TYPE bigint USING ROUND(laiko_kaina * 100),
+	ALTER COLUMN atstumo_kaina TYPE bigint USING ROUND(atstumo_kaina * 100),
+	ADD COLUMN valiuta char(3) NOT NULL DEFAULT 'EUR';
+
+ALTER TABLE vartotojai
+	ALTER COLUMN balansas TYPE bigint,
+	ADD COLUMN valiuta char(3) NOT NULL DEFAULT 'EUR';
+
+ALTER TABLE mokėjimai
+	ALTER COLUMN suma TYPE bigint USING ROUND(suma),
+	ADD COLUMN valiuta char(3) NOT NULL DEFAULT 'EUR';
+
+
+-- migrate:down
@@ -0,0 +1,76 @@
+-- migrate:up
+
+CREATE TABLE žurnalo_įrašai
+(
+	tipas varchar (20) NOT NULL,
+	aprašymas varchar (255) NOT NULL DEFAULT '',
+	sukurta timestamp with time zone NOT NULL DEFAULT now(),
+	id serial,
+	fk_Kelione integer,
+	fk_Mokejimas integer,
+	PRIMARY KEY(id),
+	CONSTRAINT apmokama FOREIGN KEY(fk_Kelione) REFERENCES kelionės (id),
+	CONSTRAINT papildoma FOREIGN KEY(fk_Mokejimas) REFERENCES mokėjimai (id)
+);
+
+CREATE TABLE žurnalo_eilutės
+(
+	sąskaita varchar (20) NOT NULL,
+	suma bigint NOT NULL CHECK (suma <> 0),
+	valiuta char(3) NOT NULL,
+	likutis bigint,
+	id serial,
+	fk_Irasas integer NOT NULL,
+	fk_Vartotojas integer,
+	PRIMARY KEY(id),
+	CHECK ((sąskaita = 'wallet') = (fk_Vartotojas IS NOT NULL)),
+	CONSTRAINT sudaro FOREIGN KEY(fk_Irasas) REFERENCES žurnalo_įrašai (id),
+	CONSTRAINT keičia FOREIGN KEY(fk_Vartotojas) REFERENCES vartotojai (id)
+);
+
+CREATE INDEX žurnalo_eilutės_vartotojas ON žurnalo_eilutės (fk_Vartotojas, fk_Irasas);
+
+-- The journal is append-only
+CREATE FUNCTION žurnalas_nekeičiamas() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'journal entries are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER įrašai_nekeičiami BEFORE UPDATE OR DELETE ON žurnalo_įrašai
+	FOR EACH ROW EXECUTE PROCEDURE žurnalas_nekeičiamas();
+
+CREATE TRIGGER eilutės_nekeičiamos BEFORE UPDATE OR DELETE ON žurnalo_eilutės
+	FOR EACH ROW EXECUTE PROCEDURE žurnalas_nekeičiamas();
+
+-- Postings of every entry must sum up to zero once the transaction commits
+CREATE FUNCTION žurnalas_subalansuotas() RETURNS trigger AS $$
+BEGIN
+	IF (SELECT SUM(suma) FROM žurnalo_eilutės WHERE fk_Irasas = NEW.fk_Irasas) <> 0 THEN
+		RAISE EXCEPTION 'journal entry % is not balanced', NEW.fk_Irasas;
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER eilutės_subalansuotos AFTER INSERT ON žurnalo_eilutės
+	DEFERRABLE INITIALLY DEFERRED
+	FOR EACH ROW EXECUTE PROCEDURE žurnalas_subalansuotas();
+
+-- Existing balances are carried over as opening balance adjustments
+DO $$
+DECLARE
+	v record;
+	įrašas integer;
+BEGIN
+	FOR v IN SELECT id, balansas, valiuta FROM vartotojai WHERE balansas <> 0 LOOP
+		INSERT INTO žurnalo_įrašai (tipas, aprašymas) VALUES ('adjustment', 'Opening balance') RETURNING id INTO įrašas;
+		INSERT INTO žurnalo_eilutės (fk_Irasas, sąskaita, fk_Vartotojas, suma, valiuta, likutis) VALUES
+			(įrašas, 'wallet', v.id, v.balansas, v.valiuta, v.balansas),
+			(įrašas, 'adjustments', NULL, -v.balansas, v.valiuta, NULL);
+	END LOOP;
+END;
+$$;
+
+
+-- migrate:down
@@ -0,0 +1,13 @@
+-- migrate:up
+
+INSERT INTO mokėjimo_būsenos(id, name) VALUES (4, 'grąžintas');
+
+ALTER TABLE mokėjimai
+	ADD COLUMN tiekėjas varchar (20),
+	ADD COLUMN nuoroda varchar (255),
+	ADD COLUMN raktas varchar (64),
+	ADD UNIQUE (fk_Vartotojas, raktas),
+	ADD UNIQUE (tiekėjas, nuoroda);
+
+
+-- migrate:down
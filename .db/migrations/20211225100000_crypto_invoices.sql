@@ -0,0 +1,22 @@
+-- migrate:up
+
+CREATE TABLE kripto_sąskaitos
+(
+	turtas varchar (10) NOT NULL,
+	adresas varchar (255) NOT NULL,
+	suma bigint NOT NULL,
+	mokėtina_suma bigint NOT NULL,
+	valiuta char(3) NOT NULL,
+	operacija varchar (255),
+	galioja_iki timestamp with time zone NOT NULL,
+	sukurta timestamp with time zone NOT NULL DEFAULT now(),
+	id serial,
+	fk_Mokejimas integer NOT NULL,
+	PRIMARY KEY(id),
+	UNIQUE(adresas),
+	UNIQUE(fk_Mokejimas),
+	CONSTRAINT apmokama_kriptovaliuta FOREIGN KEY(fk_Mokejimas) REFERENCES mokėjimai (id)
+);
+
+
+-- migrate:down
@@ -0,0 +1,25 @@
+-- migrate:up
+
+CREATE TABLE grąžinimai
+(
+	suma bigint NOT NULL CHECK (suma > 0),
+	valiuta char(3) NOT NULL,
+	priežastis varchar (500) NOT NULL,
+	sukurta timestamp with time zone NOT NULL,
+	id serial,
+	fk_Uzklausa integer NOT NULL,
+	fk_Vartotojas integer NOT NULL,
+	fk_Darbuotojas integer NOT NULL,
+	fk_Kelione integer,
+	fk_Mokejimas integer,
+	PRIMARY KEY(id),
+	CHECK ((fk_Kelione IS NULL) <> (fk_Mokejimas IS NULL)),
+	CONSTRAINT sprendžia FOREIGN KEY(fk_Uzklausa) REFERENCES užklausos (id),
+	CONSTRAINT gauna FOREIGN KEY(fk_Vartotojas) REFERENCES vartotojai (id),
+	CONSTRAINT išmoka FOREIGN KEY(fk_Darbuotojas) REFERENCES vartotojai (id),
+	CONSTRAINT kompensuoja_kelionę FOREIGN KEY(fk_Kelione) REFERENCES kelionės (id),
+	CONSTRAINT kompensuoja_mokėjimą FOREIGN KEY(fk_Mokejimas) REFERENCES mokėjimai (id)
+);
+
+
+-- migrate:down
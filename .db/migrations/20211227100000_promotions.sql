@@ -0,0 +1,36 @@
+-- migrate:up
+
+CREATE TABLE akcijos
+(
+	kodas varchar (32) NOT NULL,
+	tipas varchar (16) NOT NULL CHECK (tipas IN ('fixed', 'percent')),
+	suma bigint CHECK (suma > 0),
+	valiuta char(3),
+	procentai smallint CHECK (procentai BETWEEN 1 AND 100),
+	galioja_nuo timestamp with time zone NOT NULL,
+	galioja_iki timestamp with time zone,
+	limitas integer CHECK (limitas > 0),
+	limitas_vartotojui integer CHECK (limitas_vartotojui > 0),
+	markės varchar (255)[] NOT NULL DEFAULT '{}',
+	kuro_tipai integer[] NOT NULL DEFAULT '{}',
+	aktyvi boolean NOT NULL DEFAULT true,
+	sukurta timestamp with time zone NOT NULL,
+	id serial,
+	fk_Darbuotojas integer NOT NULL,
+	PRIMARY KEY(id),
+	UNIQUE(kodas),
+	CHECK ((tipas = 'fixed' AND suma IS NOT NULL AND valiuta IS NOT NULL AND procentai IS NULL) OR (tipas = 'percent' AND procentai IS NOT NULL AND suma IS NULL)),
+	CHECK (galioja_iki IS NULL OR galioja_iki > galioja_nuo),
+	CONSTRAINT sukuria_akciją FOREIGN KEY(fk_Darbuotojas) REFERENCES vartotojai (id)
+);
+
+ALTER TABLE rezervacijos ADD fk_Akcija integer;
+ALTER TABLE rezervacijos ADD CONSTRAINT taiko FOREIGN KEY(fk_Akcija) REFERENCES akcijos (id);
+CREATE INDEX ON rezervacijos (fk_Akcija) WHERE fk_Akcija IS NOT NULL;
+
+ALTER TABLE kelionės ADD nuolaida bigint NOT NULL DEFAULT 0;
+ALTER TABLE kelionės ADD fk_Akcija integer;
+ALTER TABLE kelionės ADD CONSTRAINT pritaikyta FOREIGN KEY(fk_Akcija) REFERENCES akcijos (id);
+
+
+-- migrate:down
@@ -0,0 +1,30 @@
+-- migrate:up
+
+CREATE TABLE kvitų_numeracija
+(
+	paskutinis integer NOT NULL
+);
+
+CREATE TABLE kvitai
+(
+	numeris integer NOT NULL,
+	išrašyta timestamp with time zone NOT NULL,
+	pvm_tarifas smallint NOT NULL CHECK (pvm_tarifas BETWEEN 0 AND 100),
+	id serial,
+	fk_Kelione integer NOT NULL,
+	PRIMARY KEY(id),
+	UNIQUE(numeris),
+	UNIQUE(fk_Kelione),
+	CONSTRAINT išrašytas FOREIGN KEY(fk_Kelione) REFERENCES kelionės (id)
+);
+
+-- Already ended trips are numbered in the order they ended
+INSERT INTO kvitai (numeris, išrašyta, pvm_tarifas, fk_Kelione)
+	SELECT ROW_NUMBER() OVER (ORDER BY pabaigos_laikas, id), pabaigos_laikas, 21, id
+	FROM kelionės
+	WHERE pabaigos_laikas IS NOT NULL;
+
+INSERT INTO kvitų_numeracija (paskutinis) SELECT COUNT(*) FROM kvitai;
+
+
+-- migrate:down
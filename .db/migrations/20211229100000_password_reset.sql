@@ -0,0 +1,35 @@
+-- migrate:up
+
+CREATE TABLE laiškai
+(
+	gavėjas varchar(255) NOT NULL,
+	tema varchar(255) NOT NULL,
+	turinys text NOT NULL,
+	bandymai integer NOT NULL DEFAULT 0,
+	klaida text,
+	sukurta timestamp with time zone NOT NULL,
+	išsiųsta timestamp with time zone,
+	id serial,
+	PRIMARY KEY(id)
+);
+
+-- Outbox dispatch only scans unsent emails
+CREATE INDEX laiškai_neišsiųsti ON laiškai (id) WHERE išsiųsta IS NULL;
+
+CREATE TABLE slaptažodžio_atkūrimai
+(
+	žetono_maiša char(64) NOT NULL,
+	galiojimo_pabaiga timestamp with time zone NOT NULL,
+	panaudota timestamp with time zone,
+	sukurta timestamp with time zone NOT NULL DEFAULT now(),
+	id serial,
+	fk_Vartotojas integer NOT NULL,
+	PRIMARY KEY(id),
+	UNIQUE(žetono_maiša),
+	CONSTRAINT atkuria FOREIGN KEY(fk_Vartotojas) REFERENCES vartotojai (id) ON DELETE CASCADE
+);
+
+CREATE INDEX slaptažodžio_atkūrimai_vartotojas ON slaptažodžio_atkūrimai (fk_Vartotojas) WHERE panaudota IS NULL;
+
+
+-- migrate:down
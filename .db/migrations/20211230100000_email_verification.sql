@@ -0,0 +1,25 @@
+-- migrate:up
+
+ALTER TABLE vartotojai ADD COLUMN el_paštas_patvirtintas timestamp with time zone;
+
+-- Existing accounts are trusted
+UPDATE vartotojai SET el_paštas_patvirtintas = now();
+
+CREATE TABLE el_pašto_patvirtinimai
+(
+	el_paštas varchar(255) NOT NULL,
+	žetono_maiša char(64) NOT NULL,
+	galiojimo_pabaiga timestamp with time zone NOT NULL,
+	panaudota timestamp with time zone,
+	sukurta timestamp with time zone NOT NULL DEFAULT now(),
+	id serial,
+	fk_Vartotojas integer NOT NULL,
+	PRIMARY KEY(id),
+	UNIQUE(žetono_maiša),
+	CONSTRAINT patvirtina FOREIGN KEY(fk_Vartotojas) REFERENCES vartotojai (id) ON DELETE CASCADE
+);
+
+CREATE INDEX el_pašto_patvirtinimai_vartotojas ON el_pašto_patvirtinimai (fk_Vartotojas) WHERE panaudota IS NULL;
+
+
+-- migrate:down
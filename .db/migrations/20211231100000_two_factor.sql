@@ -0,0 +1,32 @@
+-- migrate:up
+
+ALTER TABLE vartotojai
+	ADD COLUMN totp_paslaptis bytea,
+	ADD COLUMN totp_patvirtinta timestamp with time zone,
+	ADD COLUMN totp_žingsnis bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE atsarginiai_kodai
+(
+	kodo_maiša char(64) NOT NULL,
+	panaudota timestamp with time zone,
+	id serial,
+	fk_Vartotojas integer NOT NULL,
+	PRIMARY KEY(id),
+	UNIQUE(fk_Vartotojas, kodo_maiša),
+	CONSTRAINT turi FOREIGN KEY(fk_Vartotojas) REFERENCES vartotojai (id) ON DELETE CASCADE
+);
+
+CREATE TABLE prisijungimo_iššūkiai
+(
+	žetono_maiša char(64) NOT NULL,
+	galiojimo_pabaiga timestamp with time zone NOT NULL,
+	bandymai integer NOT NULL DEFAULT 0,
+	id serial,
+	fk_Vartotojas integer NOT NULL,
+	PRIMARY KEY(id),
+	UNIQUE(žetono_maiša),
+	CONSTRAINT tikrina FOREIGN KEY(fk_Vartotojas) REFERENCES vartotojai (id) ON DELETE CASCADE
+);
+
+
+-- migrate:down
@@ -0,0 +1,25 @@
+-- migrate:up
+
+CREATE TABLE prisijungimo_bandymai
+(
+	tipas varchar(16) NOT NULL,
+	subjektas varchar(255) NOT NULL,
+	nesėkmės integer NOT NULL,
+	paskutinė_nesėkmė timestamp with time zone NOT NULL,
+	blokuota_iki timestamp with time zone,
+	PRIMARY KEY(tipas, subjektas)
+);
+
+CREATE TABLE prisijungimo_blokavimai
+(
+	tipas varchar(16) NOT NULL,
+	subjektas varchar(255) NOT NULL,
+	nesėkmės integer NOT NULL,
+	blokuota_iki timestamp with time zone NOT NULL,
+	sukurta timestamp with time zone NOT NULL,
+	id serial,
+	PRIMARY KEY(id)
+);
+
+
+-- migrate:down
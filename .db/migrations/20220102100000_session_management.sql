@@ -0,0 +1,11 @@
+-- migrate:up
+
+ALTER TABLE sesijos ADD COLUMN sukurta timestamp with time zone NOT NULL DEFAULT now();
+ALTER TABLE sesijos ADD COLUMN paskutinį_kartą_matyta timestamp with time zone NOT NULL DEFAULT now();
+ALTER TABLE sesijos ADD COLUMN ip varchar(45) NOT NULL DEFAULT '';
+ALTER TABLE sesijos ADD COLUMN naršyklė varchar(255) NOT NULL DEFAULT '';
+
+CREATE INDEX sesijos_fk_vartotojas ON sesijos (fk_Vartotojas);
+
+
+-- migrate:down
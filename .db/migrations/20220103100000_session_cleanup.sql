@@ -0,0 +1,6 @@
+-- migrate:up
+
+CREATE INDEX sesijos_galiojimo_pabaiga ON sesijos (galiojimo_pabaiga);
+
+
+-- migrate:down
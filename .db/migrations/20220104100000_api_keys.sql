@@ -0,0 +1,23 @@
+-- migrate:up
+
+CREATE TABLE api_raktai
+(
+	pavadinimas varchar(255) NOT NULL,
+	prefiksas varchar(16) NOT NULL,
+	rakto_maiša char(64) NOT NULL,
+	leidimai varchar(32)[] NOT NULL,
+	sukurta timestamp with time zone NOT NULL,
+	galiojimo_pabaiga timestamp with time zone,
+	paskutinį_kartą_naudota timestamp with time zone,
+	atšaukta timestamp with time zone,
+	id serial,
+	fk_Vartotojas integer NOT NULL,
+	fk_Darbuotojas integer NOT NULL,
+	PRIMARY KEY(id),
+	UNIQUE(rakto_maiša),
+	CONSTRAINT naudoja_raktą FOREIGN KEY(fk_Vartotojas) REFERENCES vartotojai (id) ON DELETE CASCADE,
+	CONSTRAINT išduoda_raktą FOREIGN KEY(fk_Darbuotojas) REFERENCES vartotojai (id)
+);
+
+
+-- migrate:down
@@ -0,0 +1,8 @@
+-- migrate:up
+
+-- Telemetry keys report only for the bound car
+ALTER TABLE api_raktai ADD COLUMN fk_Automobilis integer;
+ALTER TABLE api_raktai ADD CONSTRAINT praneša_apie FOREIGN KEY(fk_Automobilis) REFERENCES automobiliai (id) ON DELETE CASCADE;
+
+
+-- migrate:down
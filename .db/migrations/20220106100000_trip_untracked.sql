@@ -0,0 +1,6 @@
+-- migrate:up
+
+ALTER TABLE kelionės ADD be_telemetrijos boolean NOT NULL DEFAULT false;
+
+
+-- migrate:down
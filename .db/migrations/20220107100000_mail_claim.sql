@@ -0,0 +1,6 @@
+-- migrate:up
+
+ALTER TABLE laiškai ADD užimta_iki timestamp with time zone;
+
+
+-- migrate:down
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"os"
 	"strings"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
 )
 
 const ConfigENV = "AUTONUOMA_CONFIG"
@@ -35,12 +37,103 @@ type Config struct {
 		PasswordCost int `json:"passwordCost"`
 		Session      struct {
 			SessionLifetime Duration `json:"sessionLifetime"`
-			CookieName      string   `json:"cookieName"`
-			CookieLifetime  Duration `json:"cookieLifetime"`
-			SecureCookie    bool     `json:"secureCookie"`
+			// Activity extends the session up to maxLifetime after sign in,
+			// cookieLifetime should cover maxLifetime
+			Sliding        bool     `json:"sliding"`
+			MaxLifetime    Duration `json:"maxLifetime"`
+			CookieName     string   `json:"cookieName"`
+			CookieLifetime Duration `json:"cookieLifetime"`
+			SecureCookie   bool     `json:"secureCookie"`
+			Cleanup        struct {
+				Interval  Duration `json:"interval"`
+				BatchSize int      `json:"batchSize"`
+			} `json:"cleanup"`
 		} `json:"session"`
+		Lockout struct {
+			// "pgsql" or "memory"
+			Store   string        `json:"store"`
+			Account LockoutPolicy `json:"account"`
+			IP      LockoutPolicy `json:"ip"`
+		} `json:"lockout"`
+		TwoFactor struct {
+			// Shown in authenticator apps
+			Issuer            string   `json:"issuer"`
+			ChallengeLifetime Duration `json:"challengeLifetime"`
+			MaxAttempts       int      `json:"maxAttempts"`
+		} `json:"twoFactor"`
+		PasswordReset struct {
+			TokenLifetime Duration `json:"tokenLifetime"`
+			// Page of the web app which reads the token query parameter
+			URL string `json:"url"`
+		} `json:"passwordReset"`
+		EmailVerification struct {
+			TokenLifetime Duration `json:"tokenLifetime"`
+			URL           string   `json:"url"`
+		} `json:"emailVerification"`
 	} `json:"auth"`
 
+	Mail struct {
+		From string `json:"from"`
+		// "smtp" or "log"
+		Sender string `json:"sender"`
+		SMTP   struct {
+			Host     string `json:"host"`
+			Port     int    `json:"port"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"smtp"`
+		Log struct {
+			// Optional directory where emails are written as .eml files
+			Dir string `json:"dir"`
+		} `json:"log"`
+		DispatchInterval Duration `json:"dispatchInterval"`
+		BatchSize        int      `json:"batchSize"`
+		MaxAttempts      int      `json:"maxAttempts"`
+	} `json:"mail"`
+
+	Ledger struct {
+		ReconcileInterval Duration `json:"reconcileInterval"`
+	} `json:"ledger"`
+
+	Reservation struct {
+		HoldWindow     Duration `json:"holdWindow"`
+		ExpiryInterval Duration `json:"expiryInterval"`
+		Eligibility    struct {
+			MinAge     int          `json:"minAge"`
+			MinBalance domain.Money `json:"minBalance"`
+		} `json:"eligibility"`
+	} `json:"reservation"`
+
+	Payment struct {
+		Local struct {
+			Secret      string `json:"secret"`
+			AutoConfirm bool   `json:"autoConfirm"`
+		} `json:"local"`
+		Crypto struct {
+			// "mock" or empty to disable crypto payments
+			Watcher string `json:"watcher"`
+			Asset   string `json:"asset"`
+			// Fiat price of a single coin
+			Rate         domain.Money `json:"rate"`
+			InvoiceTTL   Duration     `json:"invoiceTTL"`
+			PollInterval Duration     `json:"pollInterval"`
+			Mock         struct {
+				ConfirmAfter Duration `json:"confirmAfter"`
+			} `json:"mock"`
+		} `json:"crypto"`
+	} `json:"payment"`
+
+	Receipt struct {
+		// Percent, prices include VAT
+		VATRate int `json:"vatRate"`
+		Issuer  struct {
+			Name        string `json:"name"`
+			Address     string `json:"address"`
+			CompanyCode string `json:"companyCode"`
+			VATCode     string `json:"vatCode"`
+		} `json:"issuer"`
+	} `json:"receipt"`
+
 	HTTP struct {
 		Port string `json:"port"`
 		CORS struct {
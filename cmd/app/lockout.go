@@ -0,0 +1,42 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/lockout"
+	_lockoutStore "github.com/wascript3r/autonuoma/pkg/lockout/store"
+)
+
+type LockoutPolicy struct {
+	FreeAttempts    int      `json:"freeAttempts"`
+	BaseDelay       Duration `json:"baseDelay"`
+	MaxDelay        Duration `json:"maxDelay"`
+	MaxFailures     int      `json:"maxFailures"`
+	LockoutDuration Duration `json:"lockoutDuration"`
+	Window          Duration `json:"window"`
+}
+
+func (p LockoutPolicy) AttemptPolicy() domain.AttemptPolicy {
+	return domain.AttemptPolicy{
+		FreeAttempts:    p.FreeAttempts,
+		BaseDelay:       p.BaseDelay.Duration,
+		MaxDelay:        p.MaxDelay.Duration,
+		MaxFailures:     p.MaxFailures,
+		LockoutDuration: p.LockoutDuration.Duration,
+		Window:          p.Window.Duration,
+	}
+}
+
+func newLockoutStore(conn *sql.DB) (lockout.Store, error) {
+	switch Cfg.Auth.Lockout.Store {
+	case "pgsql":
+		return _lockoutStore.NewPgStore(conn), nil
+
+	case "memory":
+		return _lockoutStore.NewMemory(), nil
+	}
+
+	return nil, fmt.Errorf("unknown lockout store %q", Cfg.Auth.Lockout.Store)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/wascript3r/autonuoma/pkg/mail"
+	_mailSender "github.com/wascript3r/autonuoma/pkg/mail/sender"
+	"github.com/wascript3r/cryptopay/pkg/logger"
+)
+
+func newMailSender(log logger.Usecase) (mail.Sender, error) {
+	switch Cfg.Mail.Sender {
+	case "smtp":
+		return _mailSender.NewSMTP(
+			Cfg.Mail.SMTP.Host,
+			Cfg.Mail.SMTP.Port,
+			Cfg.Mail.SMTP.Username,
+			Cfg.Mail.SMTP.Password,
+			Cfg.Mail.From,
+		), nil
+
+	case "log":
+		return _mailSender.NewLog(log, Cfg.Mail.Log.Dir, Cfg.Mail.From), nil
+	}
+
+	return nil, fmt.Errorf("unknown mail sender %q", Cfg.Mail.Sender)
+}
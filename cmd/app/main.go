@@ -25,15 +25,68 @@ import (
 	_userWsHandler "github.com/wascript3r/autonuoma/pkg/user/delivery/ws"
 	_userPwHasher "github.com/wascript3r/autonuoma/pkg/user/pwhasher"
 	_userRepo "github.com/wascript3r/autonuoma/pkg/user/repository"
+	_userTOTP "github.com/wascript3r/autonuoma/pkg/user/totp"
 	_userUcase "github.com/wascript3r/autonuoma/pkg/user/usecase"
 	_userValidator "github.com/wascript3r/autonuoma/pkg/user/validator"
 
+	// Ledger
+	_ledgerHandler "github.com/wascript3r/autonuoma/pkg/ledger/delivery/http"
+	_ledgerRepo "github.com/wascript3r/autonuoma/pkg/ledger/repository"
+	_ledgerUcase "github.com/wascript3r/autonuoma/pkg/ledger/usecase"
+	_ledgerValidator "github.com/wascript3r/autonuoma/pkg/ledger/validator"
+	_ledgerWorker "github.com/wascript3r/autonuoma/pkg/ledger/worker"
+
+	// Payment
+	_paymentHandler "github.com/wascript3r/autonuoma/pkg/payment/delivery/http"
+	_paymentProvider "github.com/wascript3r/autonuoma/pkg/payment/provider"
+	_paymentRepo "github.com/wascript3r/autonuoma/pkg/payment/repository"
+	_paymentUcase "github.com/wascript3r/autonuoma/pkg/payment/usecase"
+	_paymentValidator "github.com/wascript3r/autonuoma/pkg/payment/validator"
+	_paymentWorker "github.com/wascript3r/autonuoma/pkg/payment/worker"
+
+	// Promo
+	_promoHandler "github.com/wascript3r/autonuoma/pkg/promo/delivery/http"
+	_promoRepo "github.com/wascript3r/autonuoma/pkg/promo/repository"
+	_promoUcase "github.com/wascript3r/autonuoma/pkg/promo/usecase"
+	_promoValidator "github.com/wascript3r/autonuoma/pkg/promo/validator"
+
+	// Receipt
+	_receiptHandler "github.com/wascript3r/autonuoma/pkg/receipt/delivery/http"
+	_receiptRender "github.com/wascript3r/autonuoma/pkg/receipt/render"
+	_receiptRepo "github.com/wascript3r/autonuoma/pkg/receipt/repository"
+	_receiptUcase "github.com/wascript3r/autonuoma/pkg/receipt/usecase"
+
+	// Refund
+	_refundHandler "github.com/wascript3r/autonuoma/pkg/refund/delivery/http"
+	_refundRepo "github.com/wascript3r/autonuoma/pkg/refund/repository"
+	_refundUcase "github.com/wascript3r/autonuoma/pkg/refund/usecase"
+	_refundValidator "github.com/wascript3r/autonuoma/pkg/refund/validator"
+
+	// Lockout
+	_lockoutHandler "github.com/wascript3r/autonuoma/pkg/lockout/delivery/http"
+	_lockoutUcase "github.com/wascript3r/autonuoma/pkg/lockout/usecase"
+
+	// Mail
+	_mailRepo "github.com/wascript3r/autonuoma/pkg/mail/repository"
+	_mailUcase "github.com/wascript3r/autonuoma/pkg/mail/usecase"
+	_mailWorker "github.com/wascript3r/autonuoma/pkg/mail/worker"
+
 	// Session
+	_sessionHandler "github.com/wascript3r/autonuoma/pkg/session/delivery/http"
 	_sessionMid "github.com/wascript3r/autonuoma/pkg/session/delivery/http/middleware"
+	_sessionWsHandler "github.com/wascript3r/autonuoma/pkg/session/delivery/ws"
 	_sessionWsMid "github.com/wascript3r/autonuoma/pkg/session/delivery/ws/middleware"
+	_sessionEventBus "github.com/wascript3r/autonuoma/pkg/session/eventbus"
 	_sessionGen "github.com/wascript3r/autonuoma/pkg/session/generator"
 	_sessionRepo "github.com/wascript3r/autonuoma/pkg/session/repository"
 	_sessionUcase "github.com/wascript3r/autonuoma/pkg/session/usecase"
+	_sessionWorker "github.com/wascript3r/autonuoma/pkg/session/worker"
+
+	// API key
+	_apiKeyHandler "github.com/wascript3r/autonuoma/pkg/apikey/delivery/http"
+	_apiKeyRepo "github.com/wascript3r/autonuoma/pkg/apikey/repository"
+	_apiKeyUcase "github.com/wascript3r/autonuoma/pkg/apikey/usecase"
+	_apiKeyValidator "github.com/wascript3r/autonuoma/pkg/apikey/validator"
 
 	// Message
 	_messageWsHandler "github.com/wascript3r/autonuoma/pkg/message/delivery/ws"
@@ -64,13 +117,29 @@ import (
 
 	// Trip
 	_tripHandler "github.com/wascript3r/autonuoma/pkg/trip/delivery/http"
+	_tripPricer "github.com/wascript3r/autonuoma/pkg/trip/pricer"
 	_tripRepo "github.com/wascript3r/autonuoma/pkg/trip/repository"
 	_tripUcase "github.com/wascript3r/autonuoma/pkg/trip/usecase"
+	_tripValidator "github.com/wascript3r/autonuoma/pkg/trip/validator"
 
 	// Reservation
 	_reservationHandler "github.com/wascript3r/autonuoma/pkg/reservation/delivery/http"
+	_reservationWsHandler "github.com/wascript3r/autonuoma/pkg/reservation/delivery/ws"
+	_reservationEligibility "github.com/wascript3r/autonuoma/pkg/reservation/eligibility"
+	_reservationEventBus "github.com/wascript3r/autonuoma/pkg/reservation/eventbus"
 	_reservationRepo "github.com/wascript3r/autonuoma/pkg/reservation/repository"
 	_reservationUcase "github.com/wascript3r/autonuoma/pkg/reservation/usecase"
+	_reservationValidator "github.com/wascript3r/autonuoma/pkg/reservation/validator"
+	_reservationWorker "github.com/wascript3r/autonuoma/pkg/reservation/worker"
+
+	// Telemetry
+	_telemetryHandler "github.com/wascript3r/autonuoma/pkg/telemetry/delivery/http"
+	_telemetryMid "github.com/wascript3r/autonuoma/pkg/telemetry/delivery/http/middleware"
+	_telemetryWsHandler "github.com/wascript3r/autonuoma/pkg/telemetry/delivery/ws"
+	_telemetryEventBus "github.com/wascript3r/autonuoma/pkg/telemetry/eventbus"
+	_telemetryRepo "github.com/wascript3r/autonuoma/pkg/telemetry/repository"
+	_telemetryUcase "github.com/wascript3r/autonuoma/pkg/telemetry/usecase"
+	_telemetryValidator "github.com/wascript3r/autonuoma/pkg/telemetry/validator"
 
 	// FAQ
 	_faqHandler "github.com/wascript3r/autonuoma/pkg/faq/delivery/http"
@@ -91,6 +160,7 @@ import (
 	_carsValidator "github.com/wascript3r/autonuoma/pkg/cars/validator"
 
 	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/payment"
 	"github.com/wascript3r/gocipher/aes"
 	"github.com/wascript3r/gopool"
 	"github.com/wascript3r/gows"
@@ -186,16 +256,71 @@ func main() {
 		fatalError(err)
 	}
 
+	// API key
+	apiKeyRepo := _apiKeyRepo.NewPgRepo(dbConn)
+	apiKeyValidator := _apiKeyValidator.New()
+	apiKeyUcase := _apiKeyUcase.New(
+		apiKeyRepo,
+		Cfg.Database.Postgres.QueryTimeout.Duration,
+
+		apiKeyValidator,
+	)
+
 	// Session
 	sessionRepo := _sessionRepo.NewPgRepo(dbConn)
 	sessionGen := _sessionGen.New()
+	sessionEventBus := _sessionEventBus.New(pool, logger)
+	sessionOpts := []_sessionUcase.Option{
+		_sessionUcase.SessionLifetime(Cfg.Auth.Session.SessionLifetime.Duration),
+		_sessionUcase.CleanupBatchSize(Cfg.Auth.Session.Cleanup.BatchSize),
+	}
+	if Cfg.Auth.Session.Sliding {
+		sessionOpts = append(sessionOpts, _sessionUcase.SlidingExpiration(Cfg.Auth.Session.MaxLifetime.Duration))
+	}
 	sessionUcase := _sessionUcase.New(
 		sessionRepo,
 		Cfg.Database.Postgres.QueryTimeout.Duration,
 
 		sessionGen,
 		cipher,
-		_sessionUcase.SessionLifetime(Cfg.Auth.Session.SessionLifetime.Duration),
+		sessionEventBus,
+		sessionOpts...,
+	)
+
+	// Ledger
+	ledgerRepo := _ledgerRepo.NewPgRepo(dbConn)
+	ledgerValidator := _ledgerValidator.New()
+	ledgerUcase := _ledgerUcase.New(
+		ledgerRepo,
+		Cfg.Database.Postgres.QueryTimeout.Duration,
+		ledgerValidator,
+	)
+
+	// Mail
+	mailRepo := _mailRepo.NewPgRepo(dbConn)
+	mailSender, err := newMailSender(logger)
+	if err != nil {
+		fatalError(err)
+	}
+	mailUcase := _mailUcase.New(
+		mailRepo,
+		Cfg.Database.Postgres.QueryTimeout.Duration,
+		mailSender,
+		Cfg.Mail.BatchSize,
+		Cfg.Mail.MaxAttempts,
+	)
+
+	// Lockout
+	lockoutStore, err := newLockoutStore(dbConn)
+	if err != nil {
+		fatalError(err)
+	}
+	lockoutUcase := _lockoutUcase.New(
+		lockoutStore,
+		Cfg.Database.Postgres.QueryTimeout.Duration,
+		logger,
+		Cfg.Auth.Lockout.Account.AttemptPolicy(),
+		Cfg.Auth.Lockout.IP.AttemptPolicy(),
 	)
 
 	// User
@@ -204,11 +329,50 @@ func main() {
 	userValidator := _userValidator.New(userRepo)
 	userUcase := _userUcase.New(
 		userRepo,
+		mailRepo,
 		Cfg.Database.Postgres.QueryTimeout.Duration,
 
 		sessionUcase,
 		userPwHasher,
 		userValidator,
+		_userTOTP.New(Cfg.Auth.TwoFactor.Issuer),
+		cipher,
+		lockoutUcase,
+		_userUcase.LoginChallenge(Cfg.Auth.TwoFactor.ChallengeLifetime.Duration, Cfg.Auth.TwoFactor.MaxAttempts),
+		_userUcase.PasswordReset(Cfg.Auth.PasswordReset.TokenLifetime.Duration, Cfg.Auth.PasswordReset.URL),
+		_userUcase.EmailVerification(Cfg.Auth.EmailVerification.TokenLifetime.Duration, Cfg.Auth.EmailVerification.URL),
+	)
+
+	// Payment
+	paymentRepo := _paymentRepo.NewPgRepo(dbConn)
+	paymentInvoiceRepo := _paymentRepo.NewInvoicePgRepo(dbConn)
+	paymentValidator := _paymentValidator.New()
+
+	var paymentProviders []payment.Provider
+	chainWatcher, err := newChainWatcher()
+	if err != nil {
+		fatalError(err)
+	}
+	if chainWatcher != nil {
+		paymentProviders = append(paymentProviders, _paymentProvider.NewCrypto(
+			paymentInvoiceRepo,
+			chainWatcher,
+
+			Cfg.Payment.Crypto.Asset,
+			Cfg.Payment.Crypto.Rate,
+			Cfg.Payment.Crypto.InvoiceTTL.Duration,
+		))
+	}
+
+	paymentUcase := _paymentUcase.New(
+		paymentRepo,
+		userRepo,
+		ledgerRepo,
+		Cfg.Database.Postgres.QueryTimeout.Duration,
+		paymentValidator,
+
+		_paymentProvider.NewLocal(Cfg.Payment.Local.Secret, Cfg.Payment.Local.AutoConfirm),
+		paymentProviders...,
 	)
 
 	// Message, Ticket
@@ -238,6 +402,16 @@ func main() {
 		reviewValidator,
 	)
 
+	// Refund
+	refundRepo := _refundRepo.NewPgRepo(dbConn)
+	refundValidator := _refundValidator.New()
+	refundUcase := _refundUcase.New(
+		refundRepo,
+		ledgerRepo,
+		Cfg.Database.Postgres.QueryTimeout.Duration,
+		refundValidator,
+	)
+
 	// Ticket
 	ticketEventBus := _ticketEventBus.New(pool, logger)
 	ticketValidator := _ticketValidator.New(messageValidator)
@@ -245,6 +419,7 @@ func main() {
 		ticketRepo,
 		messageRepo,
 		reviewRepo,
+		refundRepo,
 		Cfg.Database.Postgres.QueryTimeout.Duration,
 
 		ticketEventBus,
@@ -264,13 +439,73 @@ func main() {
 		*flagLicensesDir,
 	)
 
-	// Trip
-	tripRepo := _tripRepo.NewPgRepo(dbConn)
-	tripUsecase := _tripUcase.New(tripRepo)
+	// Promo
+	promoRepo := _promoRepo.NewPgRepo(dbConn)
+	promoValidator := _promoValidator.New()
+	promoUcase := _promoUcase.New(
+		promoRepo,
+		Cfg.Database.Postgres.QueryTimeout.Duration,
+		promoValidator,
+	)
 
 	// Reservation
 	reservationRepo := _reservationRepo.NewPgRepo(dbConn)
-	reservationUcase := _reservationUcase.New(reservationRepo)
+	reservationEventBus := _reservationEventBus.New(pool, logger)
+	reservationValidator := _reservationValidator.New()
+	reservationPolicy := _reservationEligibility.New(
+		_reservationEligibility.EmailVerifiedRule(),
+		_reservationEligibility.LicenseRule(),
+		_reservationEligibility.MinAgeRule(Cfg.Reservation.Eligibility.MinAge),
+		_reservationEligibility.CarAgeRule(),
+		_reservationEligibility.MinBalanceRule(Cfg.Reservation.Eligibility.MinBalance),
+	)
+	reservationUcase := _reservationUcase.New(
+		reservationRepo,
+		promoRepo,
+		Cfg.Database.Postgres.QueryTimeout.Duration,
+
+		reservationEventBus,
+		reservationValidator,
+		reservationPolicy,
+
+		Cfg.Reservation.HoldWindow.Duration,
+	)
+
+	// Receipt
+	receiptRepo := _receiptRepo.NewPgRepo(dbConn)
+	receiptIssuer := domain.ReceiptIssuer{
+		Name:        Cfg.Receipt.Issuer.Name,
+		Address:     Cfg.Receipt.Issuer.Address,
+		CompanyCode: Cfg.Receipt.Issuer.CompanyCode,
+		VATCode:     Cfg.Receipt.Issuer.VATCode,
+	}
+	receiptUcase := _receiptUcase.New(
+		receiptRepo,
+		Cfg.Database.Postgres.QueryTimeout.Duration,
+
+		_receiptRender.NewPDF(receiptIssuer),
+		_receiptRender.NewHTML(receiptIssuer),
+	)
+
+	// Trip
+	tripRepo := _tripRepo.NewPgRepo(dbConn)
+	tripPricer := _tripPricer.New()
+	tripValidator := _tripValidator.New()
+	tripUsecase := _tripUcase.New(
+		tripRepo,
+		reservationRepo,
+		userRepo,
+		ledgerRepo,
+		promoRepo,
+		receiptRepo,
+		Cfg.Database.Postgres.QueryTimeout.Duration,
+
+		reservationEventBus,
+		tripPricer,
+		tripValidator,
+
+		Cfg.Receipt.VATRate,
+	)
 
 	// FAQ
 	faqRepo := _faqRepo.NewPgRepo(dbConn)
@@ -288,6 +523,18 @@ func main() {
 		carsValidator,
 	)
 
+	// Telemetry
+	telemetryRepo := _telemetryRepo.NewPgRepo(dbConn)
+	telemetryEventBus := _telemetryEventBus.New(pool, logger)
+	telemetryValidator := _telemetryValidator.New()
+	telemetryUcase := _telemetryUcase.New(
+		telemetryRepo,
+		Cfg.Database.Postgres.QueryTimeout.Duration,
+
+		telemetryEventBus,
+		telemetryValidator,
+	)
+
 	// Room
 	roomRepo := _roomRepo.NewMemoryRepo()
 	roomUcase := _roomUcase.New(roomRepo)
@@ -300,6 +547,7 @@ func main() {
 	sessionWsMid := _sessionWsMid.NewWSMiddleware(
 		_sessionWsMid.DefaultSessionKey,
 		sessionUcase,
+		wsEventBus,
 	)
 
 	authWsStack := wsMiddleware.New()
@@ -327,6 +575,10 @@ func main() {
 
 	ticketWsMid := _ticketWsMid.NewWSMiddleware(socketPool)
 
+	_sessionWsHandler.NewWSHandler(
+		sessionEventBus,
+		sessionWsMid,
+	)
 	_userWsHandler.NewWSHandler(
 		wsRouter,
 		notAuthWsStack,
@@ -364,6 +616,23 @@ func main() {
 		socketPool,
 	)
 
+	_reservationWsHandler.NewWSHandler(
+		reservationEventBus,
+		sessionWsMid,
+		roomUcase,
+
+		socketPool,
+	)
+	_telemetryWsHandler.NewWSHandler(
+		wsRouter,
+		authWsStack,
+		telemetryEventBus,
+		reservationEventBus,
+		roomUcase,
+
+		socketPool,
+	)
+
 	wsListener, err := net.Listen(WSNetwork, ":"+Cfg.WebSocket.Port)
 	if err != nil {
 		fatalError(err)
@@ -388,6 +657,52 @@ func main() {
 		fatalError(err)
 	}
 
+	// Background workers
+	reservationExpiryWorker := _reservationWorker.NewExpiryWorker(
+		reservationUcase,
+		logger,
+		Cfg.Reservation.ExpiryInterval.Duration,
+	)
+	if err := reservationExpiryWorker.Start(ctx, pool); err != nil {
+		fatalError(err)
+	}
+
+	paymentPollWorker := _paymentWorker.NewPollWorker(
+		paymentUcase,
+		logger,
+		Cfg.Payment.Crypto.PollInterval.Duration,
+	)
+	if err := paymentPollWorker.Start(ctx, pool); err != nil {
+		fatalError(err)
+	}
+
+	mailDispatchWorker := _mailWorker.NewDispatchWorker(
+		mailUcase,
+		logger,
+		Cfg.Mail.DispatchInterval.Duration,
+	)
+	if err := mailDispatchWorker.Start(ctx, pool); err != nil {
+		fatalError(err)
+	}
+
+	ledgerReconcileWorker := _ledgerWorker.NewReconcileWorker(
+		ledgerUcase,
+		logger,
+		Cfg.Ledger.ReconcileInterval.Duration,
+	)
+	if err := ledgerReconcileWorker.Start(ctx, pool); err != nil {
+		fatalError(err)
+	}
+
+	sessionCleanupWorker := _sessionWorker.NewCleanupWorker(
+		sessionUcase,
+		logger,
+		Cfg.Auth.Session.Cleanup.Interval.Duration,
+	)
+	if err := sessionCleanupWorker.Start(ctx, pool); err != nil {
+		fatalError(err)
+	}
+
 	// HTTP server
 	httpRouter := httprouter.New()
 	httpRouter.MethodNotAllowed = MethodNotAllowedHnd
@@ -405,6 +720,7 @@ func main() {
 		Cfg.Auth.Session.SecureCookie,
 
 		sessionUcase,
+		apiKeyUcase,
 	)
 
 	authStack := middleware.NewCtx()
@@ -419,17 +735,104 @@ func main() {
 	agentStack := middleware.NewCtx()
 	agentStack.Use(sessionMid.HasRole(domain.AgentRole))
 
+	adminStack := middleware.NewCtx()
+	adminStack.Use(sessionMid.HasRole(domain.AdminRole))
+
+	staffStack := middleware.NewCtx()
+	staffStack.Use(sessionMid.HasAnyRole(domain.AgentRole, domain.AdminRole))
+
+	statementsStack := middleware.NewCtx()
+	statementsStack.Use(sessionMid.HasScope(domain.StatementsScope))
+
+	telemetryMid := _telemetryMid.NewHTTPMiddleware(apiKeyUcase)
+
+	deviceStack := middleware.New()
+	deviceStack.Use(telemetryMid.Authenticated)
+
 	_userHandler.NewHTTPHandler(
 		context.Background(),
 
 		httpRouter,
 		authStack,
 		notAuthStack,
+		adminStack,
+		statementsStack,
 
 		userUcase,
 		sessionUcase,
 		sessionMid,
 	)
+	_sessionHandler.NewHTTPHandler(
+		context.Background(),
+
+		httpRouter,
+		authStack,
+
+		sessionUcase,
+	)
+	_lockoutHandler.NewHTTPHandler(
+		context.Background(),
+
+		httpRouter,
+		adminStack,
+
+		lockoutUcase,
+	)
+	_paymentHandler.NewHTTPHandler(
+		context.Background(),
+
+		httpRouter,
+		clientStack,
+		adminStack,
+
+		paymentUcase,
+		sessionUcase,
+	)
+	_ledgerHandler.NewHTTPHandler(
+		context.Background(),
+
+		httpRouter,
+		statementsStack,
+
+		ledgerUcase,
+		sessionUcase,
+	)
+	_promoHandler.NewHTTPHandler(
+		context.Background(),
+
+		httpRouter,
+		adminStack,
+
+		promoUcase,
+		sessionUcase,
+	)
+	_apiKeyHandler.NewHTTPHandler(
+		context.Background(),
+
+		httpRouter,
+		adminStack,
+
+		apiKeyUcase,
+		sessionUcase,
+	)
+	_receiptHandler.NewHTTPHandler(
+		context.Background(),
+
+		httpRouter,
+		statementsStack,
+
+		receiptUcase,
+		sessionUcase,
+	)
+	_refundHandler.NewHTTPHandler(
+		context.Background(),
+
+		httpRouter,
+		staffStack,
+
+		refundUcase,
+		sessionUcase,
+	)
 	_reviewHandler.NewHTTPHandler(
 		context.Background(),
 
@@ -455,8 +858,10 @@ func main() {
 
 		httpRouter,
 		clientStack,
+		staffStack,
 
 		tripUsecase,
+		sessionUcase,
 	)
 
 	_reservationHandler.NewHTTPHandler(
@@ -464,14 +869,29 @@ func main() {
 
 		httpRouter,
 		sessionUcase,
-		sessionMid,
 		clientStack,
+		staffStack,
 		reservationUcase,
 	)
 
 	_faqHandler.NewHTTPHandler(httpRouter, faqUcase)
 
-	_carsHandler.NewHTTPHandler(httpRouter, carsUcase)
+	_carsHandler.NewHTTPHandler(
+		context.Background(),
+
+		httpRouter,
+		adminStack,
+		staffStack,
+
+		carsUcase,
+	)
+
+	_telemetryHandler.NewHTTPHandler(
+		httpRouter,
+		deviceStack,
+
+		telemetryUcase,
+	)
 
 	httpServer := &http.Server{
 		Addr: ":" + Cfg.HTTP.Port,
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/wascript3r/autonuoma/pkg/payment"
+	_paymentChain "github.com/wascript3r/autonuoma/pkg/payment/chain"
+)
+
+// newChainWatcher returns nil if crypto payments are disabled
+func newChainWatcher() (payment.ChainWatcher, error) {
+	switch Cfg.Payment.Crypto.Watcher {
+	case "":
+		return nil, nil
+
+	case "mock":
+		return _paymentChain.NewMock(Cfg.Payment.Crypto.Mock.ConfirmAfter.Duration), nil
+	}
+
+	return nil, fmt.Errorf("unknown chain watcher %q", Cfg.Payment.Crypto.Watcher)
+}
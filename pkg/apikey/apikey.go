@@ -0,0 +1,58 @@
+package apikey
+
+import (
+	"strings"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+// KeyPrefix tells API keys apart from session tokens in the Authorization header
+const KeyPrefix = "ak_"
+
+func IsKey(token string) bool {
+	return strings.HasPrefix(token, KeyPrefix)
+}
+
+// Create
+
+type CreateReq struct {
+	UserID int               `json:"userID" validate:"required,gt=0"`
+	Name   string            `json:"name" validate:"required,max=255"`
+	Scopes []domain.APIScope `json:"scopes" validate:"required,min=1,unique,dive,oneof=telemetry statements"`
+	// Required for the telemetry scope, which cannot be combined with other scopes
+	CarID      *int       `json:"carID" validate:"omitempty,gt=0"`
+	ValidUntil *time.Time `json:"validUntil"`
+}
+
+type CreateRes struct {
+	APIKeyID int `json:"apiKeyID"`
+	// The key is shown only once
+	Key string `json:"key"`
+}
+
+// GetAll
+
+type APIKeyInfo struct {
+	ID         int               `json:"id"`
+	Name       string            `json:"name"`
+	Prefix     string            `json:"prefix"`
+	Scopes     []domain.APIScope `json:"scopes"`
+	UserID     int               `json:"userID"`
+	CarID      *int              `json:"carID"`
+	CreatorID  int               `json:"creatorID"`
+	Created    time.Time         `json:"created"`
+	ValidUntil *time.Time        `json:"validUntil"`
+	LastUsed   *time.Time        `json:"lastUsed"`
+	Revoked    *time.Time        `json:"revoked"`
+}
+
+type GetAllRes struct {
+	APIKeys []*APIKeyInfo `json:"apiKeys"`
+}
+
+// Revoke
+
+type RevokeReq struct {
+	APIKeyID int `json:"apiKeyID" validate:"required,gt=0"`
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/wascript3r/autonuoma/pkg/apikey"
+	"github.com/wascript3r/autonuoma/pkg/session"
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+	httpjson "github.com/wascript3r/httputil/json"
+	"github.com/wascript3r/httputil/middleware"
+)
+
+type HTTPHandler struct {
+	apiKeyUcase  apikey.Usecase
+	sessionUcase session.Usecase
+}
+
+func NewHTTPHandler(ctx context.Context, r *httprouter.Router, admin *middleware.StackCtx, au apikey.Usecase, su session.Usecase) {
+	handler := &HTTPHandler{
+		apiKeyUcase:  au,
+		sessionUcase: su,
+	}
+
+	r.POST("/api/admin/apikey/create", admin.Wrap(ctx, handler.Create))
+	r.GET("/api/admin/apikey/list", admin.Wrap(ctx, handler.GetAll))
+	r.POST("/api/admin/apikey/revoke", admin.Wrap(ctx, handler.Revoke))
+}
+
+func serveError(w http.ResponseWriter, err error) {
+	if err == apikey.InvalidInputError {
+		httpjson.BadRequestCustom(w, apikey.InvalidInputError, nil)
+		return
+	}
+
+	code := errcode.UnwrapErr(err, apikey.UnknownError)
+	if code == apikey.UnknownError {
+		httpjson.InternalErrorCustom(w, code, nil)
+		return
+	}
+
+	httpjson.ServeErr(w, code, nil)
+}
+
+func (h *HTTPHandler) Create(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+	req := &apikey.CreateReq{}
+
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.apiKeyUcase.Create(r.Context(), s, req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
+
+func (h *HTTPHandler) GetAll(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	res, err := h.apiKeyUcase.GetAll(r.Context())
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
+
+func (h *HTTPHandler) Revoke(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := &apikey.RevokeReq{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	err = h.apiKeyUcase.Revoke(r.Context(), req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, nil)
+}
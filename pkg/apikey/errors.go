@@ -0,0 +1,34 @@
+package apikey
+
+import (
+	"errors"
+
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+)
+
+var (
+	// Error codes
+
+	InvalidInputError = errcode.InvalidInputError
+	UnknownError      = errcode.UnknownError
+
+	UserNotFoundError = errcode.New(
+		"user_not_found",
+		errors.New("user not found"),
+	)
+
+	UserOrCarNotFoundError = errcode.New(
+		"user_or_car_not_found",
+		errors.New("user or car not found"),
+	)
+
+	APIKeyNotFoundError = errcode.New(
+		"api_key_not_found",
+		errors.New("api key not found"),
+	)
+
+	InvalidAPIKeyError = errcode.New(
+		"invalid_api_key",
+		errors.New("api key is invalid, expired or revoked"),
+	)
+)
@@ -0,0 +1,16 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Repository interface {
+	Insert(ctx context.Context, k *domain.APIKey) error
+	GetAll(ctx context.Context) ([]*domain.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	SetLastUsed(ctx context.Context, keyID int, t time.Time) error
+	Revoke(ctx context.Context, keyID int, t time.Time) error
+}
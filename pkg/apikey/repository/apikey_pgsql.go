@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
+)
+
+const (
+	apiKeyFieldsSQL = "k.id, k.pavadinimas, k.prefiksas, k.rakto_maiša, k.leidimai, k.fk_vartotojas, v.rolė, k.fk_automobilis, k.fk_darbuotojas, k.sukurta, k.galiojimo_pabaiga, k.paskutinį_kartą_naudota, k.atšaukta"
+
+	// Nothing is inserted for an unknown user or car
+	insertSQL      = "INSERT INTO api_raktai (pavadinimas, prefiksas, rakto_maiša, leidimai, sukurta, galiojimo_pabaiga, fk_vartotojas, fk_automobilis, fk_darbuotojas) SELECT $1, $2, $3, $4, $5, $6, v.id, $8, $9 FROM vartotojai v WHERE v.id = $7 AND ($8::integer IS NULL OR EXISTS(SELECT 1 FROM automobiliai a WHERE a.id = $8 AND a.pašalintas = false)) RETURNING id"
+	getAllSQL      = "SELECT " + apiKeyFieldsSQL + " FROM api_raktai k INNER JOIN vartotojai v ON (v.id = k.fk_vartotojas) ORDER BY k.id DESC"
+	getByHashSQL   = "SELECT " + apiKeyFieldsSQL + " FROM api_raktai k INNER JOIN vartotojai v ON (v.id = k.fk_vartotojas) WHERE k.rakto_maiša = $1"
+	setLastUsedSQL = "UPDATE api_raktai SET paskutinį_kartą_naudota = $2 WHERE id = $1"
+	revokeSQL      = "UPDATE api_raktai SET atšaukta = COALESCE(atšaukta, $2) WHERE id = $1"
+)
+
+type PgRepo struct {
+	conn *sql.DB
+}
+
+func NewPgRepo(c *sql.DB) *PgRepo {
+	return &PgRepo{c}
+}
+
+func encodeScopes(ss []domain.APIScope) pq.StringArray {
+	a := make(pq.StringArray, len(ss))
+	for i, s := range ss {
+		a[i] = string(s)
+	}
+	return a
+}
+
+func decodeScopes(a pq.StringArray) []domain.APIScope {
+	ss := make([]domain.APIScope, len(a))
+	for i, s := range a {
+		ss[i] = domain.APIScope(s)
+	}
+	return ss
+}
+
+func scanAPIKey(row pgsql.Row) (*domain.APIKey, error) {
+	var scopes pq.StringArray
+	k := &domain.APIKey{}
+
+	err := row.Scan(
+		&k.ID,
+		&k.Name,
+		&k.Prefix,
+		&k.KeyHash,
+		&scopes,
+		&k.UserID,
+		&k.RoleID,
+		&k.CarID,
+		&k.CreatorID,
+		&k.Created,
+		&k.ValidUntil,
+		&k.LastUsed,
+		&k.Revoked,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	k.Scopes = decodeScopes(scopes)
+	return k, nil
+}
+
+func (p *PgRepo) Insert(ctx context.Context, k *domain.APIKey) error {
+	err := p.conn.QueryRowContext(
+		ctx,
+		insertSQL,
+
+		k.Name,
+		k.Prefix,
+		k.KeyHash,
+		encodeScopes(k.Scopes),
+		k.Created,
+		k.ValidUntil,
+		k.UserID,
+		k.CarID,
+		k.CreatorID,
+	).Scan(&k.ID)
+	if err != nil {
+		return pgsql.ParseSQLError(pgsql.ParsePgError(err))
+	}
+
+	return nil
+}
+
+func (p *PgRepo) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	rows, err := p.conn.QueryContext(ctx, getAllSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	var ks []*domain.APIKey
+
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		ks = append(ks, k)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+func (p *PgRepo) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	k, err := scanAPIKey(p.conn.QueryRowContext(ctx, getByHashSQL, keyHash))
+	if err != nil {
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	return k, nil
+}
+
+func (p *PgRepo) SetLastUsed(ctx context.Context, keyID int, t time.Time) error {
+	_, err := p.conn.ExecContext(ctx, setLastUsedSQL, keyID, t)
+	return err
+}
+
+func (p *PgRepo) Revoke(ctx context.Context, keyID int, t time.Time) error {
+	res, err := p.conn.ExecContext(ctx, revokeSQL, keyID, t)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Usecase interface {
+	Create(ctx context.Context, ss *domain.Session, req *CreateReq) (*CreateRes, error)
+	GetAll(ctx context.Context) (*GetAllRes, error)
+	Revoke(ctx context.Context, req *RevokeReq) error
+	Authenticate(ctx context.Context, key string) (*domain.APIKey, error)
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/apikey"
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/gocipher/encoder"
+	"github.com/wascript3r/gocipher/sha256"
+)
+
+const (
+	keySize = 32
+	// Characters of the key kept in plain text to tell the keys apart
+	prefixLen = len(apikey.KeyPrefix) + 8
+
+	// Last-used time is not written on every request
+	lastUsedInterval = time.Minute
+)
+
+type Usecase struct {
+	apiKeyRepo apikey.Repository
+	ctxTimeout time.Duration
+
+	validate apikey.Validate
+}
+
+func New(ar apikey.Repository, t time.Duration, v apikey.Validate) *Usecase {
+	return &Usecase{
+		apiKeyRepo: ar,
+		ctxTimeout: t,
+
+		validate: v,
+	}
+}
+
+func generateKey() (string, error) {
+	bs := make([]byte, keySize)
+
+	_, err := rand.Read(bs)
+	if err != nil {
+		return "", err
+	}
+
+	return apikey.KeyPrefix + string(encoder.HexEncode(bs)), nil
+}
+
+func hashKey(key string) string {
+	return string(encoder.HexEncode(sha256.Compute([]byte(key))))
+}
+
+// validCarBinding reports whether only telemetry keys are bound to a car.
+// A telemetry key is a device credential, so it has no other scopes.
+func validCarBinding(req *apikey.CreateReq) bool {
+	for _, s := range req.Scopes {
+		if s == domain.TelemetryScope {
+			return req.CarID != nil && len(req.Scopes) == 1
+		}
+	}
+	return req.CarID == nil
+}
+
+func (u *Usecase) Create(ctx context.Context, ss *domain.Session, req *apikey.CreateReq) (*apikey.CreateRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, apikey.InvalidInputError
+	}
+
+	if !validCarBinding(req) {
+		return nil, apikey.InvalidInputError
+	}
+
+	now := time.Now()
+	if req.ValidUntil != nil && !req.ValidUntil.After(now) {
+		return nil, apikey.InvalidInputError
+	}
+
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	k := &domain.APIKey{
+		Name:       req.Name,
+		Prefix:     key[:prefixLen],
+		KeyHash:    hashKey(key),
+		Scopes:     req.Scopes,
+		UserID:     req.UserID,
+		CarID:      req.CarID,
+		CreatorID:  ss.UserID,
+		Created:    now,
+		ValidUntil: req.ValidUntil,
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	err = u.apiKeyRepo.Insert(c, k)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			if req.CarID != nil {
+				return nil, apikey.UserOrCarNotFoundError
+			}
+			return nil, apikey.UserNotFoundError
+		}
+		return nil, err
+	}
+
+	return &apikey.CreateRes{
+		APIKeyID: k.ID,
+		Key:      key,
+	}, nil
+}
+
+func (u *Usecase) GetAll(ctx context.Context) (*apikey.GetAllRes, error) {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	ks, err := u.apiKeyRepo.GetAll(c)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*apikey.APIKeyInfo, len(ks))
+	for i, k := range ks {
+		infos[i] = &apikey.APIKeyInfo{
+			ID:         k.ID,
+			Name:       k.Name,
+			Prefix:     k.Prefix,
+			Scopes:     k.Scopes,
+			UserID:     k.UserID,
+			CarID:      k.CarID,
+			CreatorID:  k.CreatorID,
+			Created:    k.Created,
+			ValidUntil: k.ValidUntil,
+			LastUsed:   k.LastUsed,
+			Revoked:    k.Revoked,
+		}
+	}
+
+	return &apikey.GetAllRes{APIKeys: infos}, nil
+}
+
+func (u *Usecase) Revoke(ctx context.Context, req *apikey.RevokeReq) error {
+	if err := u.validate.RawRequest(req); err != nil {
+		return apikey.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	err := u.apiKeyRepo.Revoke(c, req.APIKeyID, time.Now())
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return apikey.APIKeyNotFoundError
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Authenticate returns the usable key matching the given one
+func (u *Usecase) Authenticate(ctx context.Context, key string) (*domain.APIKey, error) {
+	if !apikey.IsKey(key) {
+		return nil, apikey.InvalidAPIKeyError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	k, err := u.apiKeyRepo.GetByHash(c, hashKey(key))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, apikey.InvalidAPIKeyError
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !k.Usable(now) {
+		return nil, apikey.InvalidAPIKeyError
+	}
+
+	if k.LastUsed == nil || now.Sub(*k.LastUsed) >= lastUsedInterval {
+		err = u.apiKeyRepo.SetLastUsed(c, k.ID, now)
+		if err != nil {
+			return nil, err
+		}
+		k.LastUsed = &now
+	}
+
+	return k, nil
+}
@@ -0,0 +1,5 @@
+package apikey
+
+type Validate interface {
+	RawRequest(s interface{}) error
+}
@@ -21,6 +21,50 @@ type GetAllRes struct {
 	Cars []*CarsListInfo `json:"cars"`
 }
 
+// Search
+
+const DefaultSearchPageSize = 20
+
+type SearchReq struct {
+	Latitude        float64       `json:"lat" validate:"latitude"`
+	Longitude       float64       `json:"lng" validate:"longitude"`
+	Radius          float64       `json:"radius" validate:"required,gt=0,lte=100"`
+	Fuel            *int          `json:"fuel" validate:"omitempty,oneof=1 2 3"`
+	Gearbox         *int          `json:"gearbox" validate:"omitempty,oneof=1 2"`
+	AirConditioning *bool         `json:"air_conditioning"`
+	ChildSeat       *bool         `json:"child_seat"`
+	Navigation      *bool         `json:"navigation"`
+	MaxMinutePrice  *domain.Money `json:"max_minute_price"`
+	Page            int           `json:"page" validate:"omitempty,gte=1"`
+	PageSize        int           `json:"page_size" validate:"omitempty,gte=1,lte=100"`
+}
+
+type NearbyCarInfo struct {
+	ID              int                `json:"id"`
+	LicensePlate    string             `json:"license_plate"`
+	Make            string             `json:"make"`
+	Model           string             `json:"model"`
+	Latitude        string             `json:"lat"`
+	Longitude       string             `json:"lng"`
+	Distance        float64            `json:"distance"`
+	MinutePrice     domain.Money       `json:"minute_price"`
+	HourPrice       domain.Money       `json:"hour_price"`
+	DayPrice        domain.Money       `json:"day_price"`
+	KilometerPrice  domain.Money       `json:"kilometer_price"`
+	AirConditioning bool               `json:"air_conditioning"`
+	ChildSeat       bool               `json:"child_seat"`
+	Navigation      bool               `json:"navigation"`
+	Fuel            domain.FuelType    `json:"fuel"`
+	Gearbox         domain.GearboxType `json:"gearbox"`
+}
+
+type SearchRes struct {
+	Cars     []*NearbyCarInfo `json:"cars"`
+	Total    int              `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
 // GetSingle
 
 type SingleCarRes struct {
@@ -31,10 +75,10 @@ type SingleCarRes struct {
 	Color           string
 	Latitude        string
 	Longitude       string
-	MinutePrice     float64
-	HourPrice       float64
-	DayPrice        float64
-	KilometerPrice  float64
+	MinutePrice     domain.Money
+	HourPrice       domain.Money
+	DayPrice        domain.Money
+	KilometerPrice  domain.Money
 	AirConditioning bool
 	USB             bool
 	Bluetooth       bool
@@ -42,54 +86,57 @@ type SingleCarRes struct {
 	ChildSeat       bool
 	Fuel            domain.FuelType
 	Gearbox         domain.GearboxType
+	MinAge          int
 	IsReserved      bool
 }
 
 // Add car
 type AddCarReq struct {
-	LicensePlate    string  `json:"license_plate" validate:"required"`
-	Make            string  `json:"make" validate:"required"`
-	Model           string  `json:"model" validate:"required"`
-	Color           string  `json:"color" validate:"required"`
-	MinutePrice     float64 `json:"minute_price" validate:"required"`
-	HourPrice       float64 `json:"hour_price" validate:"required"`
-	DayPrice        float64 `json:"day_price" validate:"required"`
-	KilometerPrice  float64 `json:"kilometer_price" validate:"required"`
-	AirConditioning bool    `json:"air_conditioning"`
-	USB             bool    `json:"usb"`
-	Bluetooth       bool    `json:"bluetooth"`
-	Navigation      bool    `json:"navigation"`
-	ChildSeat       bool    `json:"child_seat"`
-	Fuel            int     `json:"fuel" validate:"required"`
-	Gearbox         int     `json:"gearbox" validate:"required"`
+	LicensePlate    string       `json:"license_plate" validate:"required"`
+	Make            string       `json:"make" validate:"required"`
+	Model           string       `json:"model" validate:"required"`
+	Color           string       `json:"color" validate:"required"`
+	MinutePrice     domain.Money `json:"minute_price"`
+	HourPrice       domain.Money `json:"hour_price"`
+	DayPrice        domain.Money `json:"day_price"`
+	KilometerPrice  domain.Money `json:"kilometer_price"`
+	AirConditioning bool         `json:"air_conditioning"`
+	USB             bool         `json:"usb"`
+	Bluetooth       bool         `json:"bluetooth"`
+	Navigation      bool         `json:"navigation"`
+	ChildSeat       bool         `json:"child_seat"`
+	Fuel            int          `json:"fuel" validate:"required"`
+	Gearbox         int          `json:"gearbox" validate:"required"`
+	MinAge          int          `json:"min_age" validate:"gte=0"`
 }
 
 // Update car
 type UpdateCarReq struct {
-	Id              int     `json:"id" validate:"required"`
-	LicensePlate    string  `json:"license_plate" validate:"required"`
-	Make            string  `json:"make" validate:"required"`
-	Model           string  `json:"model" validate:"required"`
-	Color           string  `json:"color" validate:"required"`
-	MinutePrice     float64 `json:"minute_price" validate:"required"`
-	HourPrice       float64 `json:"hour_price" validate:"required"`
-	DayPrice        float64 `json:"day_price" validate:"required"`
-	KilometerPrice  float64 `json:"kilometer_price" validate:"required"`
-	AirConditioning bool    `json:"air_conditioning"`
-	USB             bool    `json:"usb"`
-	Bluetooth       bool    `json:"bluetooth"`
-	Navigation      bool    `json:"navigation"`
-	ChildSeat       bool    `json:"child_seat"`
-	Fuel            int     `json:"fuel" validate:"required"`
-	Gearbox         int     `json:"gearbox" validate:"required"`
+	Id              int          `json:"id" validate:"required"`
+	LicensePlate    string       `json:"license_plate" validate:"required"`
+	Make            string       `json:"make" validate:"required"`
+	Model           string       `json:"model" validate:"required"`
+	Color           string       `json:"color" validate:"required"`
+	MinutePrice     domain.Money `json:"minute_price"`
+	HourPrice       domain.Money `json:"hour_price"`
+	DayPrice        domain.Money `json:"day_price"`
+	KilometerPrice  domain.Money `json:"kilometer_price"`
+	AirConditioning bool         `json:"air_conditioning"`
+	USB             bool         `json:"usb"`
+	Bluetooth       bool         `json:"bluetooth"`
+	Navigation      bool         `json:"navigation"`
+	ChildSeat       bool         `json:"child_seat"`
+	Fuel            int          `json:"fuel" validate:"required"`
+	Gearbox         int          `json:"gearbox" validate:"required"`
+	MinAge          int          `json:"min_age" validate:"gte=0"`
 }
 
 // Car trips
 type CarTripsInfo struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Duration  string `json:"duration"`
-	Price     int    `json:"price"`
+	FirstName string       `json:"first_name"`
+	LastName  string       `json:"last_name"`
+	Duration  string       `json:"duration"`
+	Price     domain.Money `json:"price"`
 }
 
 type CarTripsRes struct {
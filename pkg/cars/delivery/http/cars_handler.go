@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -8,24 +9,28 @@ import (
 	"github.com/wascript3r/autonuoma/pkg/cars"
 	"github.com/wascript3r/cryptopay/pkg/errcode"
 	httpjson "github.com/wascript3r/httputil/json"
+	"github.com/wascript3r/httputil/middleware"
 )
 
 type HTTPHandler struct {
 	carsUcase cars.Usecase
 }
 
-func NewHTTPHandler(r *httprouter.Router, fu cars.Usecase) {
+func NewHTTPHandler(ctx context.Context, r *httprouter.Router, admin *middleware.StackCtx, staff *middleware.StackCtx, fu cars.Usecase) {
 	handler := &HTTPHandler{
 		carsUcase: fu,
 	}
 
 	r.GET("/api/cars/list", handler.AllCars)
+	r.POST("/api/cars/search", handler.Search)
 	r.POST("/api/cars/single", handler.SingleCar)
-	r.POST("/api/cars/remove", handler.RemoveCar)
-	r.POST("/api/cars/add", handler.AddCar)
-	r.POST("/api/cars/update", handler.UpdateCar)
-	r.POST("/api/cars/trips", handler.CarTrips)
-	r.GET("/api/cars/statistics", handler.Statistics)
+
+	r.POST("/api/cars/remove", admin.Wrap(ctx, handler.RemoveCar))
+	r.POST("/api/cars/add", admin.Wrap(ctx, handler.AddCar))
+	r.POST("/api/cars/update", admin.Wrap(ctx, handler.UpdateCar))
+
+	r.POST("/api/cars/trips", staff.Wrap(ctx, handler.CarTrips))
+	r.GET("/api/cars/statistics", staff.Wrap(ctx, handler.Statistics))
 }
 
 func serveError(w http.ResponseWriter, err error) {
@@ -53,6 +58,24 @@ func (h *HTTPHandler) AllCars(w http.ResponseWriter, r *http.Request, _ httprout
 	httpjson.ServeJSON(w, res)
 }
 
+func (h *HTTPHandler) Search(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := &cars.SearchReq{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.carsUcase.Search(r.Context(), req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
+
 func (h *HTTPHandler) SingleCar(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	req := &cars.SingleCarReq{}
 
@@ -71,7 +94,7 @@ func (h *HTTPHandler) SingleCar(w http.ResponseWriter, r *http.Request, _ httpro
 	httpjson.ServeJSON(w, res)
 }
 
-func (h *HTTPHandler) RemoveCar(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+func (h *HTTPHandler) RemoveCar(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	req := &cars.SingleCarReq{}
 
 	err := json.NewDecoder(r.Body).Decode(req)
@@ -89,7 +112,7 @@ func (h *HTTPHandler) RemoveCar(w http.ResponseWriter, r *http.Request, _ httpro
 	httpjson.ServeJSON(w, res)
 }
 
-func (h *HTTPHandler) AddCar(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+func (h *HTTPHandler) AddCar(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	req := &cars.AddCarReq{}
 
 	err := json.NewDecoder(r.Body).Decode(req)
@@ -107,7 +130,7 @@ func (h *HTTPHandler) AddCar(w http.ResponseWriter, r *http.Request, _ httproute
 	httpjson.ServeJSON(w, res)
 }
 
-func (h *HTTPHandler) UpdateCar(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+func (h *HTTPHandler) UpdateCar(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	req := &cars.UpdateCarReq{}
 
 	err := json.NewDecoder(r.Body).Decode(req)
@@ -125,7 +148,7 @@ func (h *HTTPHandler) UpdateCar(w http.ResponseWriter, r *http.Request, _ httpro
 	httpjson.ServeJSON(w, res)
 }
 
-func (h *HTTPHandler) CarTrips(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+func (h *HTTPHandler) CarTrips(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	req := &cars.SingleCarReq{}
 
 	err := json.NewDecoder(r.Body).Decode(req)
@@ -143,7 +166,7 @@ func (h *HTTPHandler) CarTrips(w http.ResponseWriter, r *http.Request, _ httprou
 	httpjson.ServeJSON(w, res)
 }
 
-func (h *HTTPHandler) Statistics(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+func (h *HTTPHandler) Statistics(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	res, err := h.carsUcase.Statistics(r.Context())
 	if err != nil {
 		serveError(w, err)
@@ -8,11 +8,12 @@ import (
 
 type Repository interface {
 	GetAll(ctx context.Context) ([]*domain.Car, error)
+	Search(ctx context.Context, s *domain.CarSearch) ([]*domain.NearbyCar, int, error)
 	GetSingle(ctx context.Context, carId int) (*domain.Car, error)
 	RemoveCar(ctx context.Context, carId int) (*domain.Car, error)
-	AddCar(ctx context.Context, license_plate string, car_make string, car_model string, car_color string, minute_price float64, hour_price float64, day_price float64, kilometer_price float64, ac bool, usb bool, bluetooth bool, navigation bool, child_seat bool, gearbox int, fuel int) (*domain.Car, error)
-	UpdateCar(ctx context.Context, id int, license_plate string, car_make string, car_model string, car_color string, minute_price float64, hour_price float64, day_price float64, kilometer_price float64, ac bool, usb bool, bluetooth bool, navigation bool, child_seat bool, gearbox int, fuel int) (*domain.Car, error)
+	AddCar(ctx context.Context, license_plate string, car_make string, car_model string, car_color string, minute_price domain.Money, hour_price domain.Money, day_price domain.Money, kilometer_price domain.Money, ac bool, usb bool, bluetooth bool, navigation bool, child_seat bool, gearbox int, fuel int, min_age int) (*domain.Car, error)
+	UpdateCar(ctx context.Context, id int, license_plate string, car_make string, car_model string, car_color string, minute_price domain.Money, hour_price domain.Money, day_price domain.Money, kilometer_price domain.Money, ac bool, usb bool, bluetooth bool, navigation bool, child_seat bool, gearbox int, fuel int, min_age int) (*domain.Car, error)
 	CarTrips(ctx context.Context, carId int) ([]*domain.CarTrip, error)
-	GetCarReservation(ctx context.Context, carId int) bool
+	GetCarReservation(ctx context.Context, carId int) (bool, error)
 	Statistics(ctx context.Context) ([]*domain.CarStatistics, error)
 }
@@ -11,15 +11,35 @@ import (
 
 const (
 	getAllSQL     = "SELECT id, valstybiniai_numeriai, markė, modelis, pozicijos_platuma, pozicijos_ilguma FROM automobiliai WHERE pašalintas = false ORDER BY id ASC"
-	getSingleSQL  = "SELECT id, valstybiniai_numeriai, markė, modelis, spalva, pozicijos_platuma, pozicijos_ilguma, minutės_kaina, valandos_kaina, paros_kaina, kilometro_kaina, kondicionierius, usb, bluetooth, navigacija, vaikiška_kėdutė, pavarų_dėžė, kuro_tipas FROM automobiliai WHERE id = $1"
+	getSingleSQL  = "SELECT id, valstybiniai_numeriai, markė, modelis, spalva, pozicijos_platuma, pozicijos_ilguma, minutės_kaina, valandos_kaina, paros_kaina, kilometro_kaina, valiuta, kondicionierius, usb, bluetooth, navigacija, vaikiška_kėdutė, pavarų_dėžė, kuro_tipas, minimalus_amžius FROM automobiliai WHERE id = $1"
 	removeCarSQL  = "UPDATE automobiliai SET pašalintas = true WHERE id = $1"
-	addCarSQL     = "INSERT INTO automobiliai (valstybiniai_numeriai, markė, modelis, spalva, minutės_kaina, valandos_kaina, paros_kaina, kilometro_kaina, kondicionierius, usb, bluetooth, navigacija, vaikiška_kėdutė, pavarų_dėžė, kuro_tipas, pašalintas, pozicijos_platuma, pozicijos_ilguma) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, false, $16, $17)"
-	updateCarSQL  = "UPDATE automobiliai SET valstybiniai_numeriai = $1, markė = $2, modelis = $3, spalva = $4, minutės_kaina = $5, valandos_kaina = $6, paros_kaina = $7, kilometro_kaina = $8, kondicionierius = $9, usb = $10, bluetooth = $11, navigacija = $12, vaikiška_kėdutė = $13, pavarų_dėžė = $14, kuro_tipas = $15 WHERE id = $16"
-	carTripsSQL   = "SELECT v.vardas, v.pavardė, k.trukmė, k.kaina FROM kelionės k INNER JOIN rezervacijos r ON r.id = k.fk_rezervacija INNER JOIN vartotojai v ON v.id = r.fk_vartotojas WHERE r.fk_automobilis = $1"
-	isReservedSQL = "SELECT id FROM rezervacijos WHERE fk_automobilis = $1 AND atšaukta <> NULL"
+	addCarSQL     = "INSERT INTO automobiliai (valstybiniai_numeriai, markė, modelis, spalva, minutės_kaina, valandos_kaina, paros_kaina, kilometro_kaina, kondicionierius, usb, bluetooth, navigacija, vaikiška_kėdutė, pavarų_dėžė, kuro_tipas, pašalintas, pozicijos_platuma, pozicijos_ilguma, minimalus_amžius, valiuta) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, false, $16, $17, $18, $19)"
+	updateCarSQL  = "UPDATE automobiliai SET valstybiniai_numeriai = $1, markė = $2, modelis = $3, spalva = $4, minutės_kaina = $5, valandos_kaina = $6, paros_kaina = $7, kilometro_kaina = $8, kondicionierius = $9, usb = $10, bluetooth = $11, navigacija = $12, vaikiška_kėdutė = $13, pavarų_dėžė = $14, kuro_tipas = $15, minimalus_amžius = $17, valiuta = $18 WHERE id = $16"
+	carTripsSQL   = "SELECT v.vardas, v.pavardė, k.trukmė, k.kaina, k.valiuta FROM kelionės k INNER JOIN rezervacijos r ON r.id = k.fk_rezervacija INNER JOIN vartotojai v ON v.id = r.fk_vartotojas WHERE r.fk_automobilis = $1"
+	isReservedSQL = "SELECT EXISTS(SELECT 1 FROM rezervacijos WHERE fk_automobilis = $1 AND būsena IN (1, 2, 3))"
 	statisticsSQL = "SELECT x.pavadinimas FROM ((SELECT 1 nr, CONCAT(a.markė, ' ', a.modelis, ' (', a.valstybiniai_numeriai, ')') pavadinimas FROM rezervacijos r INNER JOIN automobiliai a ON a.id = r.fk_automobilis GROUP BY a.markė, a.modelis, a.valstybiniai_numeriai ORDER BY COUNT(*) DESC LIMIT 1) UNION (SELECT 2 nr, CONCAT(a.markė, ' ', a.modelis, ' (', a.valstybiniai_numeriai, ')') pavadinimas FROM rezervacijos r INNER JOIN automobiliai a ON a.id = r.fk_automobilis GROUP BY a.markė, a.modelis, a.valstybiniai_numeriai ORDER BY COUNT(*) ASC LIMIT 1) UNION (SELECT 3 nr, CONCAT(a.markė, ' ', a.modelis, ' (', a.valstybiniai_numeriai, ')') pavadinimas FROM rezervacijos r INNER JOIN automobiliai a ON a.id = r.fk_automobilis INNER JOIN kelionės k ON k.fk_rezervacija = r.id GROUP BY a.markė, a.modelis, a.valstybiniai_numeriai ORDER BY SUM(k.kaina) DESC LIMIT 1) UNION (SELECT 4 nr, CONCAT(a.markė, ' ', a.modelis, ' (', a.valstybiniai_numeriai, ')') pavadinimas FROM rezervacijos r INNER JOIN automobiliai a ON a.id = r.fk_automobilis INNER JOIN kelionės k ON k.fk_rezervacija = r.id GROUP BY a.markė, a.modelis, a.valstybiniai_numeriai ORDER BY SUM(k.kaina) ASC LIMIT 1)) x ORDER BY x.nr ASC"
 )
 
+// Haversine distance in km from the point ($1, $2) to the car position
+const distanceSQL = "2 * 6371 * ASIN(SQRT(POWER(SIN(RADIANS(a.pozicijos_platuma::float8 - $1) / 2), 2) + COS(RADIANS($1)) * COS(RADIANS(a.pozicijos_platuma::float8)) * POWER(SIN(RADIANS(a.pozicijos_ilguma::float8 - $2) / 2), 2)))"
+
+// The total is counted separately, so that it is known past the last page too
+const (
+	searchFromSQL = "FROM (" +
+		"SELECT a.*, " + distanceSQL + " atstumas FROM automobiliai a WHERE a.pašalintas = false" +
+		" AND NOT EXISTS(SELECT 1 FROM rezervacijos r WHERE r.fk_automobilis = a.id AND r.būsena IN (1, 2, 3))" +
+		" AND ($4::int IS NULL OR a.kuro_tipas = $4)" +
+		" AND ($5::int IS NULL OR a.pavarų_dėžė = $5)" +
+		" AND ($6::boolean IS NULL OR a.kondicionierius = $6)" +
+		" AND ($7::boolean IS NULL OR a.vaikiška_kėdutė = $7)" +
+		" AND ($8::boolean IS NULL OR a.navigacija = $8)" +
+		" AND ($9::bigint IS NULL OR (a.minutės_kaina <= $9 AND a.valiuta = $10))" +
+		") x WHERE x.atstumas <= $3"
+
+	searchSQL      = "SELECT x.id, x.valstybiniai_numeriai, x.markė, x.modelis, x.pozicijos_platuma, x.pozicijos_ilguma, x.minutės_kaina, x.valandos_kaina, x.paros_kaina, x.kilometro_kaina, x.valiuta, x.kondicionierius, x.vaikiška_kėdutė, x.navigacija, x.kuro_tipas, x.pavarų_dėžė, x.atstumas " + searchFromSQL + " ORDER BY x.atstumas ASC, x.id ASC LIMIT $11 OFFSET $12"
+	searchCountSQL = "SELECT COUNT(*) " + searchFromSQL
+)
+
 type scanFunc = func(row pgsql.Row) (*domain.Car, error)
 type scanFunc2 = func(row pgsql.Row) (*domain.CarTrip, error)
 type scanFunc3 = func(row pgsql.Row) (*domain.CarStatistics, error)
@@ -46,7 +66,7 @@ func scanRow(row pgsql.Row) (*domain.Car, error) {
 func scanRow2(row pgsql.Row) (*domain.CarTrip, error) {
 	f := &domain.CarTrip{}
 
-	err := row.Scan(&f.FirstName, &f.LastName, &f.Duration, &f.Price)
+	err := row.Scan(&f.FirstName, &f.LastName, &f.Duration, &f.Price.Amount, &f.Price.Currency)
 	if err != nil {
 		return nil, pgsql.ParseSQLError(err)
 	}
@@ -131,24 +151,109 @@ func (p *PgRepo) GetAll(ctx context.Context) ([]*domain.Car, error) {
 	return scanRows(rows, scanRow)
 }
 
+// setCurrency copies the currency of the minute price, which all car prices share
+func setCurrency(c *domain.Car) {
+	c.HourPrice.Currency = c.MinutePrice.Currency
+	c.DayPrice.Currency = c.MinutePrice.Currency
+	c.KilometerPrice.Currency = c.MinutePrice.Currency
+}
+
+func (p *PgRepo) Search(ctx context.Context, s *domain.CarSearch) ([]*domain.NearbyCar, int, error) {
+	var (
+		maxPrice         *int64
+		maxPriceCurrency = domain.DefaultCurrency
+	)
+	if s.MaxMinutePrice != nil {
+		maxPrice = &s.MaxMinutePrice.Amount
+		maxPriceCurrency = s.MaxMinutePrice.Currency
+	}
+
+	args := []interface{}{
+		s.Position.Lat,
+		s.Position.Lng,
+		s.Radius,
+		s.Fuel,
+		s.Gearbox,
+		s.AirConditioning,
+		s.ChildSeat,
+		s.Navigation,
+		maxPrice,
+		maxPriceCurrency,
+	}
+
+	var total int
+
+	err := p.conn.QueryRowContext(ctx, searchCountSQL, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := p.conn.QueryContext(ctx, searchSQL, append(args, s.Limit, s.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var cs []*domain.NearbyCar
+
+	for rows.Next() {
+		c := &domain.NearbyCar{Car: &domain.Car{}}
+
+		err := rows.Scan(
+			&c.ID,
+			&c.LicensePlate,
+			&c.Make,
+			&c.Model,
+			&c.Latitude,
+			&c.Longitude,
+			&c.MinutePrice.Amount,
+			&c.HourPrice.Amount,
+			&c.DayPrice.Amount,
+			&c.KilometerPrice.Amount,
+			&c.MinutePrice.Currency,
+			&c.AirConditioning,
+			&c.ChildSeat,
+			&c.Navigation,
+			&c.Fuel,
+			&c.Gearbox,
+			&c.Distance,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		setCurrency(c.Car)
+
+		cs = append(cs, c)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, 0, err
+	}
+
+	return cs, total, nil
+}
+
 func (p PgRepo) GetSingle(ctx context.Context, carId int) (*domain.Car, error) {
 	c := &domain.Car{}
 
-	err := p.conn.QueryRowContext(ctx, getSingleSQL, carId).Scan(&c.ID, &c.LicensePlate, &c.Make, &c.Model, &c.Color, &c.Latitude, &c.Longitude, &c.MinutePrice, &c.HourPrice, &c.DayPrice, &c.KilometerPrice, &c.AirConditioning, &c.USB, &c.Bluetooth, &c.Navigation, &c.ChildSeat, &c.Gearbox, &c.Fuel)
+	err := p.conn.QueryRowContext(ctx, getSingleSQL, carId).Scan(&c.ID, &c.LicensePlate, &c.Make, &c.Model, &c.Color, &c.Latitude, &c.Longitude, &c.MinutePrice.Amount, &c.HourPrice.Amount, &c.DayPrice.Amount, &c.KilometerPrice.Amount, &c.MinutePrice.Currency, &c.AirConditioning, &c.USB, &c.Bluetooth, &c.Navigation, &c.ChildSeat, &c.Gearbox, &c.Fuel, &c.MinAge)
 	if err != nil {
 		return nil, pgsql.ParseSQLError(err)
 	}
+	setCurrency(c)
 
 	return c, nil
 }
 
-func (p PgRepo) GetCarReservation(ctx context.Context, carId int) bool {
-	_, err := p.conn.QueryContext(ctx, isReservedSQL, carId)
+func (p PgRepo) GetCarReservation(ctx context.Context, carId int) (bool, error) {
+	var reserved bool
+
+	err := p.conn.QueryRowContext(ctx, isReservedSQL, carId).Scan(&reserved)
 	if err != nil {
-		return false
+		return false, err
 	}
 
-	return true
+	return reserved, nil
 }
 
 func (p PgRepo) RemoveCar(ctx context.Context, carId int) (*domain.Car, error) {
@@ -157,7 +262,7 @@ func (p PgRepo) RemoveCar(ctx context.Context, carId int) (*domain.Car, error) {
 	return c, nil
 }
 
-func (p PgRepo) AddCar(ctx context.Context, license_plate string, car_make string, car_model string, car_color string, minute_price float64, hour_price float64, day_price float64, kilometer_price float64, ac bool, usb bool, bluetooth bool, navigation bool, child_seat bool, gearbox int, fuel int) (*domain.Car, error) {
+func (p PgRepo) AddCar(ctx context.Context, license_plate string, car_make string, car_model string, car_color string, minute_price domain.Money, hour_price domain.Money, day_price domain.Money, kilometer_price domain.Money, ac bool, usb bool, bluetooth bool, navigation bool, child_seat bool, gearbox int, fuel int, min_age int) (*domain.Car, error) {
 	c := &domain.Car{}
 
 	positions := [5][2]float64{
@@ -172,7 +277,7 @@ func (p PgRepo) AddCar(ctx context.Context, license_plate string, car_make strin
 	x := val[0]
 	y := val[1]
 
-	_, err := p.conn.ExecContext(ctx, addCarSQL, license_plate, car_make, car_model, car_color, minute_price, hour_price, day_price, kilometer_price, ac, usb, bluetooth, navigation, child_seat, gearbox, fuel, x, y)
+	_, err := p.conn.ExecContext(ctx, addCarSQL, license_plate, car_make, car_model, car_color, minute_price.Amount, hour_price.Amount, day_price.Amount, kilometer_price.Amount, ac, usb, bluetooth, navigation, child_seat, gearbox, fuel, x, y, min_age, minute_price.Currency)
 
 	if err != nil {
 		return nil, err
@@ -181,9 +286,9 @@ func (p PgRepo) AddCar(ctx context.Context, license_plate string, car_make strin
 	return c, nil
 }
 
-func (p PgRepo) UpdateCar(ctx context.Context, id int, license_plate string, car_make string, car_model string, car_color string, minute_price float64, hour_price float64, day_price float64, kilometer_price float64, ac bool, usb bool, bluetooth bool, navigation bool, child_seat bool, gearbox int, fuel int) (*domain.Car, error) {
+func (p PgRepo) UpdateCar(ctx context.Context, id int, license_plate string, car_make string, car_model string, car_color string, minute_price domain.Money, hour_price domain.Money, day_price domain.Money, kilometer_price domain.Money, ac bool, usb bool, bluetooth bool, navigation bool, child_seat bool, gearbox int, fuel int, min_age int) (*domain.Car, error) {
 	c := &domain.Car{}
-	_, err := p.conn.ExecContext(ctx, updateCarSQL, license_plate, car_make, car_model, car_color, minute_price, hour_price, day_price, kilometer_price, ac, usb, bluetooth, navigation, child_seat, gearbox, fuel, id)
+	_, err := p.conn.ExecContext(ctx, updateCarSQL, license_plate, car_make, car_model, car_color, minute_price.Amount, hour_price.Amount, day_price.Amount, kilometer_price.Amount, ac, usb, bluetooth, navigation, child_seat, gearbox, fuel, id, min_age, minute_price.Currency)
 
 	if err != nil {
 		return nil, err
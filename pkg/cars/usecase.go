@@ -6,6 +6,7 @@ import (
 
 type Usecase interface {
 	GetAll(ctx context.Context) (*GetAllRes, error)
+	Search(ctx context.Context, req *SearchReq) (*SearchRes, error)
 	GetSingle(ctx context.Context, carId int) (*SingleCarRes, error)
 	RemoveCar(ctx context.Context, carId int) (*SingleCarRes, error)
 	AddCar(ctx context.Context, req *AddCarReq) (*SingleCarRes, error)
@@ -2,9 +2,11 @@ package usecase
 
 import (
 	"context"
+	"math"
 	"time"
 
 	"github.com/wascript3r/autonuoma/pkg/cars"
+	"github.com/wascript3r/autonuoma/pkg/domain"
 )
 
 type Usecase struct {
@@ -47,6 +49,79 @@ func (u *Usecase) GetAll(ctx context.Context) (*cars.GetAllRes, error) {
 	}, nil
 }
 
+func (u *Usecase) Search(ctx context.Context, req *cars.SearchReq) (*cars.SearchRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, cars.InvalidInputError
+	}
+
+	if req.MaxMinutePrice != nil && !req.MaxMinutePrice.IsPositive() {
+		return nil, cars.InvalidInputError
+	}
+
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = cars.DefaultSearchPageSize
+	}
+
+	s := &domain.CarSearch{
+		Position:        domain.Point{Lat: req.Latitude, Lng: req.Longitude},
+		Radius:          req.Radius,
+		AirConditioning: req.AirConditioning,
+		ChildSeat:       req.ChildSeat,
+		Navigation:      req.Navigation,
+		MaxMinutePrice:  req.MaxMinutePrice,
+		Limit:           req.PageSize,
+		Offset:          (req.Page - 1) * req.PageSize,
+	}
+	if req.Fuel != nil {
+		f := domain.FuelType(*req.Fuel)
+		s.Fuel = &f
+	}
+	if req.Gearbox != nil {
+		g := domain.GearboxType(*req.Gearbox)
+		s.Gearbox = &g
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	ns, total, err := u.carsRepo.Search(c, s)
+	if err != nil {
+		return nil, err
+	}
+
+	carslist := make([]*cars.NearbyCarInfo, len(ns))
+	for i, n := range ns {
+		carslist[i] = &cars.NearbyCarInfo{
+			ID:              n.ID,
+			LicensePlate:    n.LicensePlate,
+			Make:            n.Make,
+			Model:           n.Model,
+			Latitude:        n.Latitude,
+			Longitude:       n.Longitude,
+			Distance:        math.Round(n.Distance*1000) / 1000,
+			MinutePrice:     n.MinutePrice,
+			HourPrice:       n.HourPrice,
+			DayPrice:        n.DayPrice,
+			KilometerPrice:  n.KilometerPrice,
+			AirConditioning: n.AirConditioning,
+			ChildSeat:       n.ChildSeat,
+			Navigation:      n.Navigation,
+			Fuel:            n.Fuel,
+			Gearbox:         n.Gearbox,
+		}
+	}
+
+	return &cars.SearchRes{
+		Cars:     carslist,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, nil
+}
+
 func (u *Usecase) GetSingle(ctx context.Context, carId int) (*cars.SingleCarRes, error) {
 	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
 	defer cancel()
@@ -56,7 +131,10 @@ func (u *Usecase) GetSingle(ctx context.Context, carId int) (*cars.SingleCarRes,
 		return nil, err
 	}
 
-	isReserved := u.carsRepo.GetCarReservation(c, carId)
+	isReserved, err := u.carsRepo.GetCarReservation(c, carId)
+	if err != nil {
+		return nil, err
+	}
 
 	return &cars.SingleCarRes{
 		ID:              fs.ID,
@@ -77,6 +155,7 @@ func (u *Usecase) GetSingle(ctx context.Context, carId int) (*cars.SingleCarRes,
 		ChildSeat:       fs.ChildSeat,
 		Fuel:            fs.Fuel,
 		Gearbox:         fs.Gearbox,
+		MinAge:          fs.MinAge,
 		IsReserved:      isReserved,
 	}, nil
 }
@@ -94,6 +173,16 @@ func (u *Usecase) RemoveCar(ctx context.Context, carId int) (*cars.SingleCarRes,
 	return nil, nil
 }
 
+// validPrices requires positive prices sharing a single supported currency
+func validPrices(ps ...domain.Money) bool {
+	for _, p := range ps {
+		if !p.IsPositive() || !p.Currency.Valid() || p.Currency != ps[0].Currency {
+			return false
+		}
+	}
+	return true
+}
+
 func (u *Usecase) AddCar(ctx context.Context, req *cars.AddCarReq) (*cars.SingleCarRes, error) {
 	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
 	defer cancel()
@@ -102,7 +191,11 @@ func (u *Usecase) AddCar(ctx context.Context, req *cars.AddCarReq) (*cars.Single
 		return nil, cars.InvalidInputError
 	}
 
-	_, err := u.carsRepo.AddCar(c, req.LicensePlate, req.Make, req.Model, req.Color, req.MinutePrice, req.HourPrice, req.DayPrice, req.KilometerPrice, req.AirConditioning, req.USB, req.Bluetooth, req.Navigation, req.ChildSeat, req.Gearbox, req.Fuel)
+	if !validPrices(req.MinutePrice, req.HourPrice, req.DayPrice, req.KilometerPrice) {
+		return nil, cars.InvalidInputError
+	}
+
+	_, err := u.carsRepo.AddCar(c, req.LicensePlate, req.Make, req.Model, req.Color, req.MinutePrice, req.HourPrice, req.DayPrice, req.KilometerPrice, req.AirConditioning, req.USB, req.Bluetooth, req.Navigation, req.ChildSeat, req.Gearbox, req.Fuel, req.MinAge)
 	if err != nil {
 		return nil, err
 	}
@@ -118,7 +211,11 @@ func (u *Usecase) UpdateCar(ctx context.Context, req *cars.UpdateCarReq) (*cars.
 		return nil, cars.InvalidInputError
 	}
 
-	_, err := u.carsRepo.UpdateCar(c, req.Id, req.LicensePlate, req.Make, req.Model, req.Color, req.MinutePrice, req.HourPrice, req.DayPrice, req.KilometerPrice, req.AirConditioning, req.USB, req.Bluetooth, req.Navigation, req.ChildSeat, req.Gearbox, req.Fuel)
+	if !validPrices(req.MinutePrice, req.HourPrice, req.DayPrice, req.KilometerPrice) {
+		return nil, cars.InvalidInputError
+	}
+
+	_, err := u.carsRepo.UpdateCar(c, req.Id, req.LicensePlate, req.Make, req.Model, req.Color, req.MinutePrice, req.HourPrice, req.DayPrice, req.KilometerPrice, req.AirConditioning, req.USB, req.Bluetooth, req.Navigation, req.ChildSeat, req.Gearbox, req.Fuel, req.MinAge)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// APIScope limits what an API key can be used for
+type APIScope string
+
+const (
+	// Vehicle telemetry reports of the car the key is bound to
+	TelemetryScope APIScope = "telemetry"
+	// Read-only access to the owner's statements, trips, receipts and
+	// balance history, e.g. for an accounting export job
+	StatementsScope APIScope = "statements"
+)
+
+// APIKey is a long-lived credential issued by an admin. The key acts
+// on behalf of its owner, only its hash is stored.
+type APIKey struct {
+	ID      int
+	Name    string
+	Prefix  string
+	KeyHash string
+	Scopes  []APIScope
+	UserID  int
+	RoleID  Role
+	// Set only for telemetry keys
+	CarID      *int
+	CreatorID  int
+	Created    time.Time
+	ValidUntil *time.Time
+	LastUsed   *time.Time
+	Revoked    *time.Time
+}
+
+func (k *APIKey) Usable(t time.Time) bool {
+	return k.Revoked == nil && (k.ValidUntil == nil || t.Before(*k.ValidUntil))
+}
+
+func (k *APIKey) HasScope(s APIScope) bool {
+	for _, ks := range k.Scopes {
+		if ks == s {
+			return true
+		}
+	}
+	return false
+}
@@ -25,10 +25,10 @@ type Car struct {
 	Color           string
 	Latitude        string
 	Longitude       string
-	MinutePrice     float64
-	HourPrice       float64
-	DayPrice        float64
-	KilometerPrice  float64
+	MinutePrice     Money
+	HourPrice       Money
+	DayPrice        Money
+	KilometerPrice  Money
 	AirConditioning bool
 	USB             bool
 	Bluetooth       bool
@@ -36,13 +36,34 @@ type Car struct {
 	ChildSeat       bool
 	Fuel            FuelType
 	Gearbox         GearboxType
+	MinAge          int
+}
+
+// CarSearch narrows down the available cars around a position.
+// Nil filters are not applied.
+type CarSearch struct {
+	Position        Point
+	Radius          float64 // km
+	Fuel            *FuelType
+	Gearbox         *GearboxType
+	AirConditioning *bool
+	ChildSeat       *bool
+	Navigation      *bool
+	MaxMinutePrice  *Money
+	Limit           int
+	Offset          int
+}
+
+type NearbyCar struct {
+	*Car
+	Distance float64 // km
 }
 
 type CarTrip struct {
 	FirstName string
 	LastName  string
 	Duration  time.Time
-	Price     int
+	Price     Money
 }
 
 type CarStatistics struct {
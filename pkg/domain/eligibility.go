@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// Eligibility is a snapshot of the user and car data the
+// reservation rules are evaluated against
+type Eligibility struct {
+	BirthDate         time.Time
+	EmailVerified     bool
+	Balance           Money
+	LicenseStatus     *LicenseStatus
+	LicenseExpiration *time.Time
+	CarMinAge         int
+}
+
+func (e *Eligibility) Age(now time.Time) int {
+	age := now.Year() - e.BirthDate.Year()
+	if now.Month() < e.BirthDate.Month() || (now.Month() == e.BirthDate.Month() && now.Day() < e.BirthDate.Day()) {
+		age--
+	}
+	return age
+}
+
+// LicenseExpired reports whether the licence is no longer valid, the expiration date itself included
+func (e *Eligibility) LicenseExpired(now time.Time) bool {
+	if e.LicenseExpiration == nil {
+		return true
+	}
+	return !now.Before(e.LicenseExpiration.AddDate(0, 0, 1))
+}
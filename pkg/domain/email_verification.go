@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// EmailVerification is a single-use token proving the ownership of Email.
+// When Email differs from the current account email, using the token
+// changes the account email.
+type EmailVerification struct {
+	ID         int
+	UserID     int
+	Email      string
+	TokenHash  string
+	Expiration time.Time
+	UsedAt     *time.Time
+}
+
+// Usable reports whether the token can still verify the email at t
+func (v *EmailVerification) Usable(t time.Time) bool {
+	return v.UsedAt == nil && t.Before(v.Expiration)
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "math"
+
+const EarthRadius = 6371.0 // km
+
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Distance returns the great-circle distance between two points in kilometers.
+func Distance(a, b Point) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Crypto amounts are kept in base units, e.g. satoshis
+const CryptoDecimals = 8
+
+// CryptoInvoice asks the client to pay Amount of Asset to Address before it expires
+type CryptoInvoice struct {
+	ID         int
+	PaymentID  int
+	Asset      string
+	Address    string
+	Amount     int64
+	FiatAmount Money
+	TxHash     *string
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+}
+
+// AmountString formats the amount in whole coins, e.g. "0.00125000"
+func (i *CryptoInvoice) AmountString() string {
+	s := strconv.FormatInt(i.Amount, 10)
+	if len(s) <= CryptoDecimals {
+		s = strings.Repeat("0", CryptoDecimals-len(s)+1) + s
+	}
+	return s[:len(s)-CryptoDecimals] + "." + s[len(s)-CryptoDecimals:]
+}
+
+// ChainTransfer is a confirmed transfer to an invoice address
+type ChainTransfer struct {
+	TxHash string
+	Amount int64
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type LedgerAccount string
+
+const (
+	// Client wallet, its balance is the spendable user balance
+	WalletLedgerAccount LedgerAccount = "wallet"
+	// Funds received from payment providers
+	CashLedgerAccount LedgerAccount = "cash"
+	// Trip charges and fees earned by the company
+	RevenueLedgerAccount LedgerAccount = "revenue"
+	// Funds given back to clients
+	RefundsLedgerAccount LedgerAccount = "refunds"
+	// Manual corrections and opening balances
+	AdjustmentsLedgerAccount LedgerAccount = "adjustments"
+)
+
+type JournalEntryType string
+
+const (
+	TopUpJournalEntry      JournalEntryType = "top_up"
+	TripChargeJournalEntry JournalEntryType = "trip_charge"
+	RefundJournalEntry     JournalEntryType = "refund"
+	FeeJournalEntry        JournalEntryType = "fee"
+	AdjustmentJournalEntry JournalEntryType = "adjustment"
+)
+
+var ErrUnbalancedEntry = errors.New("journal entry is not balanced")
+
+// Posting changes the balance of a single account.
+// UserID is only set for wallet accounts.
+type Posting struct {
+	Account LedgerAccount
+	UserID  *int
+	Amount  Money
+}
+
+// JournalEntry is an immutable set of postings which sum up to zero.
+type JournalEntry struct {
+	ID          int
+	Type        JournalEntryType
+	Description string
+	CreatedAt   time.Time
+	TripID      *int
+	PaymentID   *int
+	Postings    []*Posting
+}
+
+// Validate checks the double-entry invariant
+func (e *JournalEntry) Validate() error {
+	if len(e.Postings) < 2 {
+		return ErrUnbalancedEntry
+	}
+
+	c := e.Postings[0].Amount.Currency
+	var sum int64
+
+	for _, p := range e.Postings {
+		if p.Amount.IsZero() || p.Amount.Currency != c {
+			return ErrUnbalancedEntry
+		}
+		if (p.Account == WalletLedgerAccount) != (p.UserID != nil) {
+			return ErrUnbalancedEntry
+		}
+		sum += p.Amount.Amount
+	}
+
+	if sum != 0 {
+		return ErrUnbalancedEntry
+	}
+
+	return nil
+}
+
+// newWalletEntry moves amount from the counter account into the user wallet.
+// Negative amounts move funds out of the wallet.
+func newWalletEntry(t JournalEntryType, uid int, counter LedgerAccount, amount Money) *JournalEntry {
+	return &JournalEntry{
+		Type: t,
+		Postings: []*Posting{
+			{Account: WalletLedgerAccount, UserID: &uid, Amount: amount},
+			{Account: counter, Amount: amount.Neg()},
+		},
+	}
+}
+
+func NewTopUpEntry(uid, paymentID int, amount Money) *JournalEntry {
+	e := newWalletEntry(TopUpJournalEntry, uid, CashLedgerAccount, amount)
+	e.PaymentID = &paymentID
+	return e
+}
+
+// NewPaymentRefundEntry returns a refunded top-up to the payment provider
+func NewPaymentRefundEntry(uid, paymentID int, amount Money) *JournalEntry {
+	e := newWalletEntry(RefundJournalEntry, uid, CashLedgerAccount, amount.Neg())
+	e.PaymentID = &paymentID
+	return e
+}
+
+func NewTripChargeEntry(uid, tripID int, amount Money) *JournalEntry {
+	e := newWalletEntry(TripChargeJournalEntry, uid, RevenueLedgerAccount, amount.Neg())
+	e.TripID = &tripID
+	return e
+}
+
+func NewRefundEntry(uid int, amount Money, description string) *JournalEntry {
+	e := newWalletEntry(RefundJournalEntry, uid, RefundsLedgerAccount, amount)
+	e.Description = description
+	return e
+}
+
+func NewFeeEntry(uid int, amount Money, description string) *JournalEntry {
+	e := newWalletEntry(FeeJournalEntry, uid, RevenueLedgerAccount, amount.Neg())
+	e.Description = description
+	return e
+}
+
+// NewAdjustmentEntry corrects the wallet balance by a signed amount
+func NewAdjustmentEntry(uid int, amount Money, description string) *JournalEntry {
+	e := newWalletEntry(AdjustmentJournalEntry, uid, AdjustmentsLedgerAccount, amount)
+	e.Description = description
+	return e
+}
+
+// WalletEntry is a journal entry as seen from a single user wallet
+type WalletEntry struct {
+	ID          int
+	Type        JournalEntryType
+	Description string
+	CreatedAt   time.Time
+	TripID      *int
+	PaymentID   *int
+	Amount      Money
+	Balance     Money
+}
+
+// BalanceMismatch is a wallet whose stored balance differs from the journal
+type BalanceMismatch struct {
+	UserID  int
+	Stored  Money
+	Journal Money
+}
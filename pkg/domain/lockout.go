@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+type LockoutScope string
+
+const (
+	AccountLockoutScope LockoutScope = "account"
+	IPLockoutScope      LockoutScope = "ip"
+)
+
+// LoginAttempts counts the failed sign in attempts of an account or an IP address
+type LoginAttempts struct {
+	Scope        LockoutScope
+	Subject      string
+	Failures     int
+	LastFailure  time.Time
+	BlockedUntil *time.Time
+}
+
+// RetryAfter returns how long the subject has to wait before the next attempt
+func (a *LoginAttempts) RetryAfter(now time.Time) time.Duration {
+	if a.BlockedUntil == nil || !now.Before(*a.BlockedUntil) {
+		return 0
+	}
+	return a.BlockedUntil.Sub(now)
+}
+
+// AttemptPolicy delays attempts exponentially after FreeAttempts failures
+// and locks the subject out for LockoutDuration after MaxFailures.
+// Failures are forgotten after Window without any.
+type AttemptPolicy struct {
+	FreeAttempts    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	MaxFailures     int
+	LockoutDuration time.Duration
+	Window          time.Duration
+}
+
+// Block blocks the subject according to its already counted failures
+// and reports whether it got locked out
+func (p AttemptPolicy) Block(a *LoginAttempts, now time.Time) bool {
+	var until time.Time
+	switch {
+	case a.Failures >= p.MaxFailures:
+		until = now.Add(p.LockoutDuration)
+		a.BlockedUntil = &until
+		return true
+
+	case a.Failures > p.FreeAttempts:
+		delay := p.BaseDelay
+		for i := p.FreeAttempts + 1; i < a.Failures && delay < p.MaxDelay; i++ {
+			delay *= 2
+		}
+		if delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+
+		until = now.Add(delay)
+		a.BlockedUntil = &until
+	}
+
+	return false
+}
+
+// Lockout is logged every time a subject gets locked out
+type Lockout struct {
+	ID       int
+	Scope    LockoutScope
+	Subject  string
+	Failures int
+	Until    time.Time
+	Created  time.Time
+}
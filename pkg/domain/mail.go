@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// Email is a message in the outbox. It is sent after the transaction
+// which enqueued it commits, so no email leaves for rolled back changes.
+type Email struct {
+	ID        int
+	To        string
+	Subject   string
+	Body      string
+	Attempts  int
+	LastError *string
+	CreatedAt time.Time
+	SentAt    *time.Time
+}
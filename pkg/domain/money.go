@@ -0,0 +1,249 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Currency string
+
+const (
+	EUR Currency = "EUR"
+
+	DefaultCurrency = EUR
+)
+
+var supportedCurrencies = map[Currency]bool{
+	EUR: true,
+}
+
+func (c Currency) Valid() bool {
+	return supportedCurrencies[c]
+}
+
+// All supported currencies have two decimal places
+const (
+	minorUnitDigits = 2
+	minorUnitScale  = 100
+)
+
+var (
+	ErrInvalidMoney     = errors.New("invalid money amount")
+	ErrInvalidCurrency  = errors.New("unsupported currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrMoneyOverflow    = errors.New("money amount out of range")
+)
+
+// Money is an amount in minor units (cents) of the currency.
+// Amounts are never stored as floating point numbers.
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+func NewMoney(amount int64, c Currency) Money {
+	return Money{Amount: amount, Currency: c}
+}
+
+func Cents(amount int64) Money {
+	return NewMoney(amount, DefaultCurrency)
+}
+
+// MoneyFromFloat converts major units to Money rounding half away from zero.
+// It is only meant for values which are floats by nature, e.g. the distance price.
+func MoneyFromFloat(v float64, c Currency) Money {
+	return NewMoney(int64(math.Round(v*minorUnitScale)), c)
+}
+
+// ParseMoney parses a decimal amount in major units, e.g. "12.5" or "-0.99".
+// More than two decimal places are rejected instead of being rounded.
+func ParseMoney(s string, c Currency) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Money{}, ErrInvalidMoney
+	}
+
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+
+	if whole == "" || len(frac) > minorUnitDigits || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, ErrInvalidMoney
+	}
+	frac += strings.Repeat("0", minorUnitDigits-len(frac))
+
+	major, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || major > math.MaxInt64/minorUnitScale-1 {
+		return Money{}, ErrInvalidMoney
+	}
+	minor, _ := strconv.ParseInt(frac, 10, 64)
+
+	amount := major*minorUnitScale + minor
+	if neg {
+		amount = -amount
+	}
+
+	return NewMoney(amount, c), nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// The arithmetic below fails instead of mixing currencies
+// or wrapping around the int64 range of minor units
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if (o.Amount > 0 && m.Amount > math.MaxInt64-o.Amount) || (o.Amount < 0 && m.Amount < math.MinInt64-o.Amount) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(m.Amount+o.Amount, m.Currency), nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if (o.Amount > 0 && m.Amount < math.MinInt64+o.Amount) || (o.Amount < 0 && m.Amount > math.MaxInt64+o.Amount) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(m.Amount-o.Amount, m.Currency), nil
+}
+
+func (m Money) Mul(n int64) (Money, error) {
+	r := m.Amount * n
+	if n != 0 && (r/n != m.Amount || (m.Amount == math.MinInt64 && n == -1)) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(r, m.Currency), nil
+}
+
+// MulFloat multiplies by a non-monetary factor rounding half away from zero
+func (m Money) MulFloat(f float64) (Money, error) {
+	r := math.Round(float64(m.Amount) * f)
+	if !(r > math.MinInt64 && r < math.MaxInt64) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(int64(r), m.Currency), nil
+}
+
+func (m Money) Neg() Money {
+	return NewMoney(-m.Amount, m.Currency)
+}
+
+func (m Money) LessThan(o Money) (bool, error) {
+	if m.Currency != o.Currency {
+		return false, ErrCurrencyMismatch
+	}
+	return m.Amount < o.Amount, nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// String formats the amount in major units with exactly two decimal places
+func (m Money) String() string {
+	a := m.Amount
+	sign := ""
+	if a < 0 {
+		sign = "-"
+	}
+
+	u := uint64(a)
+	if a < 0 {
+		u = uint64(-a)
+	}
+
+	frac := strconv.FormatUint(u%minorUnitScale, 10)
+	if len(frac) < minorUnitDigits {
+		frac = strings.Repeat("0", minorUnitDigits-len(frac)) + frac
+	}
+
+	return sign + strconv.FormatUint(u/minorUnitScale, 10) + "." + frac
+}
+
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency Currency        `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as a decimal string so that no precision is lost:
+// {"amount": "12.50", "currency": "EUR"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	c := m.Currency
+	if c == "" {
+		c = DefaultCurrency
+	}
+
+	return json.Marshal(struct {
+		Amount   string   `json:"amount"`
+		Currency Currency `json:"currency"`
+	}{m.String(), c})
+}
+
+// UnmarshalJSON accepts the MarshalJSON format or a bare amount in the
+// default currency. Amounts may be JSON strings or numbers, currencies
+// have to be supported.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	raw := json.RawMessage(b)
+	c := DefaultCurrency
+
+	if len(b) > 0 && b[0] == '{' {
+		var mj moneyJSON
+		if err := json.Unmarshal(b, &mj); err != nil {
+			return err
+		}
+		raw = mj.Amount
+		if mj.Currency != "" {
+			c = mj.Currency
+		}
+		if !c.Valid() {
+			return ErrInvalidCurrency
+		}
+	}
+
+	s := strings.TrimSpace(string(raw))
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+	}
+
+	v, err := ParseMoney(s, c)
+	if err != nil {
+		return err
+	}
+
+	*m = v
+	return nil
+}
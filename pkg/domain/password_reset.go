@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// PasswordReset is a single-use password reset token. Only the hash
+// of the token is stored, the token itself is sent to the user by email.
+type PasswordReset struct {
+	ID         int
+	UserID     int
+	Email      string
+	TokenHash  string
+	Expiration time.Time
+	UsedAt     *time.Time
+}
+
+// Usable reports whether the token can still reset the password at t
+func (r *PasswordReset) Usable(t time.Time) bool {
+	return r.UsedAt == nil && t.Before(r.Expiration)
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+type PaymentStatus int8
+
+const (
+	SuccessfulPaymentStatus PaymentStatus = iota + 1
+	RejectedPaymentStatus
+	PendingPaymentStatus
+	RefundedPaymentStatus
+)
+
+// Payment is a top-up made through a payment provider
+type Payment struct {
+	ID             int
+	UserID         int
+	Amount         Money
+	Status         PaymentStatus
+	Provider       string
+	Reference      *string
+	IdempotencyKey string
+	CreatedAt      time.Time
+}
+
+type PaymentEventType string
+
+const (
+	PaymentSucceededEvent PaymentEventType = "payment.succeeded"
+	PaymentFailedEvent    PaymentEventType = "payment.failed"
+)
+
+// PaymentEvent is a provider notification about a payment
+type PaymentEvent struct {
+	Type      PaymentEventType
+	Reference string
+	Amount    Money
+}
+
+// PaymentIntent is returned by a provider when a payment is created.
+// Providers which confirm synchronously set Event.
+type PaymentIntent struct {
+	Reference   string
+	RedirectURL string
+	Invoice     *CryptoInvoice
+	Event       *PaymentEvent
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+type PriceCap string
+
+const (
+	HourPriceCap PriceCap = "hour"
+	DayPriceCap  PriceCap = "day"
+)
+
+// Tariff holds car prices in minor units of the currency.
+type Tariff struct {
+	MinutePrice    int64
+	HourPrice      int64
+	DayPrice       int64
+	KilometerPrice int64
+	Currency       Currency
+}
+
+func NewTariff(c *Car) *Tariff {
+	return &Tariff{
+		MinutePrice:    c.MinutePrice.Amount,
+		HourPrice:      c.HourPrice.Amount,
+		DayPrice:       c.DayPrice.Amount,
+		KilometerPrice: c.KilometerPrice.Amount,
+		Currency:       c.MinutePrice.Currency,
+	}
+}
+
+// PriceBreakdown holds a computed trip fare.
+type PriceBreakdown struct {
+	Duration      time.Duration
+	Distance      float64
+	Days          int
+	Hours         int
+	Minutes       int
+	TimePrice     Money
+	DistancePrice Money
+	Discount      Money
+	Total         Money
+	Caps          []PriceCap
+	Promotion     *Promotion
+}
+
+// ApplyPromotion deducts the discount of the promotion from the total.
+// The discount is always in the currency of the total.
+func (pb *PriceBreakdown) ApplyPromotion(p *Promotion) {
+	pb.Discount = p.Discount(pb.Total)
+	pb.Total = NewMoney(pb.Total.Amount-pb.Discount.Amount, pb.Total.Currency)
+	pb.Promotion = p
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type DiscountType string
+
+const (
+	FixedDiscount   DiscountType = "fixed"
+	PercentDiscount DiscountType = "percent"
+)
+
+// Promotion is a discount campaign redeemed with a promo code.
+// Nil limits and empty restrictions are not applied.
+type Promotion struct {
+	ID             int
+	Code           string
+	Type           DiscountType
+	Amount         Money // fixed discounts only
+	Percent        int   // percentage discounts only
+	ValidFrom      time.Time
+	ValidUntil     *time.Time
+	MaxUses        *int
+	MaxUsesPerUser *int
+	Makes          []string
+	Fuels          []FuelType
+	Active         bool
+	Created        time.Time
+	CreatorID      int
+}
+
+// PromotionUsage counts the redemptions of a promotion
+type PromotionUsage struct {
+	Total int
+	User  int
+}
+
+// NormalizePromoCode makes promo codes case insensitive
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Valid reports whether the promotion can be redeemed at t.
+func (p *Promotion) Valid(t time.Time) bool {
+	if !p.Active || t.Before(p.ValidFrom) {
+		return false
+	}
+	return p.ValidUntil == nil || t.Before(*p.ValidUntil)
+}
+
+// Exhausted reports whether one more redemption would exceed any of the usage limits.
+func (p *Promotion) Exhausted(u *PromotionUsage) bool {
+	if p.MaxUses != nil && u.Total >= *p.MaxUses {
+		return true
+	}
+	return p.MaxUsesPerUser != nil && u.User >= *p.MaxUsesPerUser
+}
+
+// AppliesTo reports whether the car satisfies the make and fuel restrictions.
+func (p *Promotion) AppliesTo(c *Car) bool {
+	if p.Type == FixedDiscount && p.Amount.Currency != c.MinutePrice.Currency {
+		return false
+	}
+
+	if len(p.Makes) > 0 {
+		found := false
+		for _, m := range p.Makes {
+			if strings.EqualFold(m, c.Make) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(p.Fuels) > 0 {
+		for _, f := range p.Fuels {
+			if f == c.Fuel {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// Discount returns the part of total covered by the promotion.
+// Percentages are rounded down and the discount never exceeds the total.
+func (p *Promotion) Discount(total Money) Money {
+	d := NewMoney(0, total.Currency)
+
+	switch p.Type {
+	case FixedDiscount:
+		if p.Amount.Currency == total.Currency {
+			d.Amount = p.Amount.Amount
+		}
+	case PercentDiscount:
+		d.Amount = total.Amount * int64(p.Percent) / 100
+	}
+
+	if total.Amount < d.Amount {
+		return total
+	}
+	return d
+}
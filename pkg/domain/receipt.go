@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReceiptIssuer is the company printed on receipts
+type ReceiptIssuer struct {
+	Name        string
+	Address     string
+	CompanyCode string
+	VATCode     string
+}
+
+// Receipt of a completed trip. Receipts are numbered sequentially
+// when the trip ends, prices include VAT.
+type Receipt struct {
+	Number  int
+	Issued  time.Time
+	VATRate int // percent
+
+	TripID   int
+	UserID   int
+	Client   *UserMeta
+	Begin    time.Time
+	End      time.Time
+	From     string
+	To       string
+	Distance float64 // km
+
+	CarMake  string
+	CarModel string
+	CarPlate string
+
+	TimePrice     Money
+	DistancePrice Money
+	Discount      Money
+	Total         Money
+	Caps          []PriceCap
+	PromoCode     *string
+
+	PaymentStatus PaymentStatus
+	// Wallet balance right after the trip was paid, nil while the payment is pending
+	Balance *Money
+}
+
+// ReceiptNumber formats sequential receipt numbers
+func ReceiptNumber(n int) string {
+	return fmt.Sprintf("AN-%06d", n)
+}
+
+func (r *Receipt) Code() string {
+	return ReceiptNumber(r.Number)
+}
+
+func (r *Receipt) Duration() time.Duration {
+	return r.End.Sub(r.Begin)
+}
+
+// VAT returns the VAT part of the total rounding half up
+func (r *Receipt) VAT() Money {
+	base := int64(100 + r.VATRate)
+	return NewMoney((r.Total.Amount*int64(r.VATRate)+base/2)/base, r.Total.Currency)
+}
+
+// Net returns the total without VAT, the VAT is always in the currency of the total
+func (r *Receipt) Net() Money {
+	return NewMoney(r.Total.Amount-r.VAT().Amount, r.Total.Currency)
+}
+
+// FormatDuration formats durations as days, hours and minutes, e.g. "1 d 2 h 5 min"
+func FormatDuration(d time.Duration) string {
+	m := int(d.Round(time.Minute) / time.Minute)
+	days, hours, mins := m/(24*60), m/60%24, m%60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%d d %d h %d min", days, hours, mins)
+	case hours > 0:
+		return fmt.Sprintf("%d h %d min", hours, mins)
+	}
+	return fmt.Sprintf("%d min", mins)
+}
+
+// Address falls back to the coordinates when the address is not known
+func Address(addr *string, p *Point) string {
+	if addr != nil && strings.TrimSpace(*addr) != "" {
+		return *addr
+	}
+	if p == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.5f, %.5f", p.Lat, p.Lng)
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Refund compensates a client for a trip charge or a payment
+// while resolving a support ticket.
+type Refund struct {
+	ID         int
+	TicketID   int
+	UserID     int
+	IssuerID   int
+	TripID     *int
+	PaymentID  *int
+	Amount     Money
+	Reason     string
+	Created    time.Time
+	IssuerMeta *UserMeta
+}
+
+// RefundTarget is the original charge a refund is capped at
+type RefundTarget struct {
+	UserID   int
+	Charge   Money
+	Refunded Money
+	Settled  bool
+}
+
+// Refundable returns the part of the charge which is not refunded yet
+func (t *RefundTarget) Refundable() (Money, error) {
+	return t.Charge.Sub(t.Refunded)
+}
@@ -2,6 +2,50 @@ package domain
 
 import "time"
 
+type ReservationStatus int8
+
+const (
+	PendingReservationStatus ReservationStatus = iota + 1
+	ActiveReservationStatus
+	InTripReservationStatus
+	CompletedReservationStatus
+	CancelledReservationStatus
+	ExpiredReservationStatus
+)
+
+type CancelReason string
+
+const (
+	UserCancelReason    CancelReason = "user"
+	StaffCancelReason   CancelReason = "staff"
+	ExpiredCancelReason CancelReason = "expired"
+)
+
+var reservationTransitions = map[ReservationStatus][]ReservationStatus{
+	PendingReservationStatus: {ActiveReservationStatus, CancelledReservationStatus, ExpiredReservationStatus},
+	ActiveReservationStatus:  {InTripReservationStatus, CancelledReservationStatus, ExpiredReservationStatus},
+	InTripReservationStatus:  {CompletedReservationStatus},
+}
+
+// CanTransition reports whether a reservation in status s may be moved to status to.
+func (s ReservationStatus) CanTransition(to ReservationStatus) bool {
+	for _, t := range reservationTransitions[s] {
+		if t == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLive reports whether a reservation in status s still holds the car.
+func (s ReservationStatus) IsLive() bool {
+	switch s {
+	case PendingReservationStatus, ActiveReservationStatus, InTripReservationStatus:
+		return true
+	}
+	return false
+}
+
 type Reservation struct {
 	ID           int
 	CreatedAt    time.Time
@@ -10,4 +54,5 @@ type Reservation struct {
 	EndAddress   string
 	CarID        int
 	UserID       int
+	Status       ReservationStatus
 }
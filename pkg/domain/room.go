@@ -7,13 +7,14 @@ type Room int
 const (
 	AuthenticatedRoom Room = iota
 	AgentRoom
+	CarsLiveRoom
 )
 
 var ErrInvalidRoomName = errors.New("invalid room name")
 
 func IsValidRoom(r Room) bool {
 	switch r {
-	case AuthenticatedRoom, AgentRoom:
+	case AuthenticatedRoom, AgentRoom, CarsLiveRoom:
 		return true
 	}
 	return false
@@ -6,9 +6,38 @@ func HasRole(ss *Session, r Role) bool {
 	return ss.RoleID == r
 }
 
+func HasAnyRole(ss *Session, rs ...Role) bool {
+	for _, r := range rs {
+		if ss.RoleID == r {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the session may be used where the scope is
+// required. Only API key sessions are limited by scopes.
+func HasScope(ss *Session, s APIScope) bool {
+	return ss.APIKey == nil || ss.APIKey.HasScope(s)
+}
+
+// IsStaff reports whether the session belongs to an agent or an admin.
+// API key sessions never act as staff, they reach only the data of the key owner.
+func IsStaff(ss *Session) bool {
+	return ss.APIKey == nil && HasAnyRole(ss, AgentRole, AdminRole)
+}
+
 type Session struct {
 	ID         string
 	UserID     int
 	Expiration time.Time
 	RoleID     Role
+
+	CreatedAt time.Time
+	LastSeen  time.Time
+	IP        string
+	UserAgent string
+
+	// Set when the request is authenticated with an API key
+	APIKey *APIKey
 }
@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+type StatementLineType string
+
+const (
+	TripStatementLine       StatementLineType = "trip"
+	TopUpStatementLine      StatementLineType = "top_up"
+	RefundStatementLine     StatementLineType = "refund"
+	FeeStatementLine        StatementLineType = "fee"
+	AdjustmentStatementLine StatementLineType = "adjustment"
+)
+
+type UserPayment struct {
+	ID       int
+	Created  time.Time
+	Amount   Money
+	Provider string
+	Status   string
+}
+
+// StatementTotals sums the wallet movements of a period by their kind
+type StatementTotals struct {
+	Trips       Money
+	TopUps      Money
+	Refunds     Money
+	Fees        Money
+	Adjustments Money
+}
+
+func NewStatementTotals(c Currency) *StatementTotals {
+	zero := NewMoney(0, c)
+	return &StatementTotals{zero, zero, zero, zero, zero}
+}
+
+// Add books a wallet journal entry under its kind
+func (t *StatementTotals) Add(e *WalletEntry) error {
+	var total *Money
+	switch e.Type {
+	case TripChargeJournalEntry:
+		total = &t.Trips
+	case TopUpJournalEntry:
+		total = &t.TopUps
+	case RefundJournalEntry:
+		total = &t.Refunds
+	case FeeJournalEntry:
+		total = &t.Fees
+	case AdjustmentJournalEntry:
+		total = &t.Adjustments
+	default:
+		return nil
+	}
+
+	sum, err := total.Add(e.Amount)
+	if err != nil {
+		return err
+	}
+
+	*total = sum
+	return nil
+}
+
+func (t *StatementTotals) Sum() (Money, error) {
+	sum := t.Trips
+	for _, m := range []Money{t.TopUps, t.Refunds, t.Fees, t.Adjustments} {
+		var err error
+		if sum, err = sum.Add(m); err != nil {
+			return Money{}, err
+		}
+	}
+	return sum, nil
+}
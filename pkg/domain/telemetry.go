@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+type Telemetry struct {
+	CarID      int
+	Position   Point
+	FuelLevel  float64 // percent of fuel or charge left
+	Odometer   float64 // km
+	ReportedAt time.Time
+	// The car is not held by a live reservation
+	Available bool
+}
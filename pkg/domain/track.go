@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+type TrackPoint struct {
+	Point
+	RecordedAt time.Time
+}
+
+// TrackDistance returns the length of the route through the given points in kilometers.
+func TrackDistance(ps []Point) float64 {
+	var d float64
+	for i := 1; i < len(ps); i++ {
+		d += Distance(ps[i-1], ps[i])
+	}
+	return d
+}
+
+// EncodePolyline encodes the points using the Google encoded polyline algorithm
+// with a precision of 5 decimal places.
+func EncodePolyline(ps []Point) string {
+	var (
+		sb               strings.Builder
+		prevLat, prevLng int64
+	)
+
+	for _, p := range ps {
+		lat := int64(math.Round(p.Lat * 1e5))
+		lng := int64(math.Round(p.Lng * 1e5))
+
+		encodePolylineValue(&sb, lat-prevLat)
+		encodePolylineValue(&sb, lng-prevLng)
+
+		prevLat, prevLng = lat, lng
+	}
+
+	return sb.String()
+}
+
+func encodePolylineValue(sb *strings.Builder, v int64) {
+	u := uint64(v << 1)
+	if v < 0 {
+		u = ^u
+	}
+
+	for u >= 0x20 {
+		sb.WriteByte(byte((0x20 | (u & 0x1f)) + 63))
+		u >>= 5
+	}
+	sb.WriteByte(byte(u + 63))
+}
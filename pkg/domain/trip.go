@@ -3,12 +3,13 @@ package domain
 import "time"
 
 type UserTrip struct {
-	ID    int
-	Begin time.Time
-	End   time.Time
-	From  string
-	To    string
-	Price float32
+	ID            int
+	Begin         time.Time
+	End           time.Time
+	From          string
+	To            string
+	Price         Money
+	PaymentStatus string
 }
 
 type Trip struct {
@@ -18,6 +19,24 @@ type Trip struct {
 	EndLng        string
 	EndLat        string
 	Duration      time.Time
-	Price         float32
+	Price         Money
 	ReservationID int
+	UserID        int
+	Distance      *float64
+	Polyline      string
+	// No telemetry was reported by the car during the trip
+	Untracked bool
+}
+
+type TripMeta struct {
+	ReservationID int
+	UserID        int
+	Begin         time.Time
+	End           *time.Time
+	From          *Point
+	To            *Point
+	Price         Money
+	PaymentStatus *PaymentStatus
+	PromotionID   *int
+	Car           *Car
 }
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// TwoFactorRequired reports whether the role has to use two-factor authentication
+func TwoFactorRequired(r Role) bool {
+	return r == AgentRole || r == AdminRole
+}
+
+// TwoFactor holds the TOTP state of a user. Secret is encrypted and is
+// set before the enrolment is confirmed with the first valid code.
+type TwoFactor struct {
+	UserID    int
+	Email     string
+	RoleID    Role
+	Secret    []byte
+	EnabledAt *time.Time
+	// Last accepted time step, codes cannot be reused
+	LastStep int64
+}
+
+func (t *TwoFactor) Enabled() bool {
+	return t.EnabledAt != nil
+}
+
+// LoginChallenge is issued after a valid password when the second
+// factor still has to be verified before the session is created.
+type LoginChallenge struct {
+	ID         int
+	UserID     int
+	TokenHash  string
+	Expiration time.Time
+	Attempts   int
+}
+
+func (c *LoginChallenge) Usable(t time.Time, maxAttempts int) bool {
+	return t.Before(c.Expiration) && c.Attempts < maxAttempts
+}
@@ -22,15 +22,16 @@ type User struct {
 	FirstName string
 	LastName  string
 	BirthDate time.Time
-	Balance   float32
+	Balance   Money
 	PIN       string
 	RoleID    Role
 }
 
 type UserCredentials struct {
-	ID       int
-	RoleID   Role
-	Password string
+	ID        int
+	RoleID    Role
+	Password  string
+	TwoFactor bool
 }
 
 type UserMeta struct {
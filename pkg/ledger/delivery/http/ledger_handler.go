@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/wascript3r/autonuoma/pkg/ledger"
+	"github.com/wascript3r/autonuoma/pkg/session"
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+	httpjson "github.com/wascript3r/httputil/json"
+	"github.com/wascript3r/httputil/middleware"
+)
+
+type HTTPHandler struct {
+	ledgerUcase  ledger.Usecase
+	sessionUcase session.Usecase
+}
+
+func NewHTTPHandler(ctx context.Context, r *httprouter.Router, auth *middleware.StackCtx, lu ledger.Usecase, su session.Usecase) {
+	handler := &HTTPHandler{
+		ledgerUcase:  lu,
+		sessionUcase: su,
+	}
+
+	r.POST("/api/user/balance/history", auth.Wrap(ctx, handler.History))
+}
+
+func serveError(w http.ResponseWriter, err error) {
+	if err == ledger.InvalidInputError {
+		httpjson.BadRequestCustom(w, ledger.InvalidInputError, nil)
+		return
+	}
+
+	code := errcode.UnwrapErr(err, ledger.UnknownError)
+	if code == ledger.UnknownError {
+		httpjson.InternalErrorCustom(w, code, nil)
+		return
+	}
+
+	httpjson.ServeErr(w, code, nil)
+}
+
+func (h *HTTPHandler) History(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+	req := &ledger.HistoryReq{}
+
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.ledgerUcase.History(r.Context(), s, req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
@@ -0,0 +1,12 @@
+package ledger
+
+import (
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+)
+
+var (
+	// Error codes
+
+	InvalidInputError = errcode.InvalidInputError
+	UnknownError      = errcode.UnknownError
+)
@@ -0,0 +1,34 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+// History
+
+const DefaultHistoryPageSize = 20
+
+type HistoryReq struct {
+	Page     int `json:"page" validate:"omitempty,gte=1"`
+	PageSize int `json:"page_size" validate:"omitempty,gte=1,lte=100"`
+}
+
+type EntryInfo struct {
+	ID          int                     `json:"id"`
+	Type        domain.JournalEntryType `json:"type"`
+	Description string                  `json:"description,omitempty"`
+	CreatedAt   time.Time               `json:"created_at"`
+	TripID      *int                    `json:"trip_id"`
+	PaymentID   *int                    `json:"payment_id"`
+	Amount      domain.Money            `json:"amount"`
+	Balance     domain.Money            `json:"balance"`
+}
+
+type HistoryRes struct {
+	Entries  []*EntryInfo `json:"entries"`
+	Total    int          `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
@@ -0,0 +1,19 @@
+package ledger
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+)
+
+type Repository interface {
+	NewTx(ctx context.Context) (repository.Transaction, error)
+
+	// Post records the entry and applies its wallet postings to the user balances
+	Post(ctx context.Context, e *domain.JournalEntry) error
+	PostTx(ctx context.Context, tx repository.Transaction, e *domain.JournalEntry) error
+
+	GetHistory(ctx context.Context, uid, limit, offset int) ([]*domain.WalletEntry, int, error)
+	GetMismatches(ctx context.Context) ([]*domain.BalanceMismatch, error)
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
+)
+
+const (
+	insertEntrySQL   = "INSERT INTO žurnalo_įrašai (tipas, aprašymas, fk_kelione, fk_mokejimas) VALUES ($1, $2, $3, $4) RETURNING id, sukurta"
+	insertPostingSQL = "INSERT INTO žurnalo_eilutės (fk_irasas, sąskaita, fk_vartotojas, suma, valiuta, likutis) VALUES ($1, $2, $3, $4, $5, $6)"
+	applyWalletSQL   = "UPDATE vartotojai SET balansas = balansas + $2 WHERE id = $1 AND valiuta = $3 RETURNING balansas"
+
+	getHistorySQL      = "SELECT j.id, j.tipas, j.aprašymas, j.sukurta, j.fk_kelione, j.fk_mokejimas, e.suma, e.valiuta, e.likutis FROM žurnalo_eilutės e INNER JOIN žurnalo_įrašai j ON (j.id = e.fk_irasas) WHERE e.fk_vartotojas = $1 AND e.sąskaita = $2 ORDER BY j.id DESC LIMIT $3 OFFSET $4"
+	getHistoryCountSQL = "SELECT COUNT(*) FROM žurnalo_eilutės e WHERE e.fk_vartotojas = $1 AND e.sąskaita = $2"
+	getMismatchesSQL   = "SELECT v.id, v.balansas, v.valiuta, COALESCE(SUM(e.suma), 0) FROM vartotojai v LEFT JOIN žurnalo_eilutės e ON (e.fk_vartotojas = v.id AND e.sąskaita = $1) GROUP BY v.id HAVING v.balansas <> COALESCE(SUM(e.suma), 0)"
+)
+
+type PgRepo struct {
+	conn *sql.DB
+}
+
+func NewPgRepo(c *sql.DB) *PgRepo {
+	return &PgRepo{c}
+}
+
+func (p *PgRepo) NewTx(ctx context.Context) (repository.Transaction, error) {
+	return p.conn.BeginTx(ctx, nil)
+}
+
+func (p *PgRepo) post(ctx context.Context, q pgsql.Querier, e *domain.JournalEntry) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	err := q.QueryRowContext(ctx, insertEntrySQL, e.Type, e.Description, e.TripID, e.PaymentID).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return pgsql.ParsePgError(err)
+	}
+
+	for _, ps := range e.Postings {
+		// Only wallets keep a running balance
+		var balance *int64
+		if ps.UserID != nil {
+			balance = new(int64)
+			err := q.QueryRowContext(ctx, applyWalletSQL, *ps.UserID, ps.Amount.Amount, ps.Amount.Currency).Scan(balance)
+			if err != nil {
+				return pgsql.ParseSQLError(err)
+			}
+		}
+
+		_, err := q.ExecContext(ctx, insertPostingSQL, e.ID, ps.Account, ps.UserID, ps.Amount.Amount, ps.Amount.Currency, balance)
+		if err != nil {
+			return pgsql.ParsePgError(err)
+		}
+	}
+
+	return nil
+}
+
+func (p *PgRepo) Post(ctx context.Context, e *domain.JournalEntry) error {
+	tx, err := p.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = p.post(ctx, tx, e)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (p *PgRepo) PostTx(ctx context.Context, tx repository.Transaction, e *domain.JournalEntry) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := p.post(ctx, sqlTx, e)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (p *PgRepo) GetHistory(ctx context.Context, uid, limit, offset int) ([]*domain.WalletEntry, int, error) {
+	var total int
+
+	// Counted separately, so that the total is known past the last page too
+	err := p.conn.QueryRowContext(ctx, getHistoryCountSQL, uid, domain.WalletLedgerAccount).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := p.conn.QueryContext(ctx, getHistorySQL, uid, domain.WalletLedgerAccount, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var es []*domain.WalletEntry
+
+	for rows.Next() {
+		e := &domain.WalletEntry{}
+
+		err := rows.Scan(
+			&e.ID,
+			&e.Type,
+			&e.Description,
+			&e.CreatedAt,
+			&e.TripID,
+			&e.PaymentID,
+			&e.Amount.Amount,
+			&e.Amount.Currency,
+			&e.Balance.Amount,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		e.Balance.Currency = e.Amount.Currency
+
+		es = append(es, e)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, 0, err
+	}
+
+	return es, total, nil
+}
+
+func (p *PgRepo) GetMismatches(ctx context.Context) ([]*domain.BalanceMismatch, error) {
+	rows, err := p.conn.QueryContext(ctx, getMismatchesSQL, domain.WalletLedgerAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []*domain.BalanceMismatch
+
+	for rows.Next() {
+		m := &domain.BalanceMismatch{}
+
+		err := rows.Scan(&m.UserID, &m.Stored.Amount, &m.Stored.Currency, &m.Journal.Amount)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		m.Journal.Currency = m.Stored.Currency
+
+		ms = append(ms, m)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return ms, nil
+}
@@ -0,0 +1,12 @@
+package ledger
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Usecase interface {
+	History(ctx context.Context, ss *domain.Session, req *HistoryReq) (*HistoryRes, error)
+	Reconcile(ctx context.Context) ([]*domain.BalanceMismatch, error)
+}
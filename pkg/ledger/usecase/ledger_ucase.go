@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/ledger"
+)
+
+type Usecase struct {
+	ledgerRepo ledger.Repository
+	ctxTimeout time.Duration
+	validate   ledger.Validate
+}
+
+func New(lr ledger.Repository, t time.Duration, v ledger.Validate) *Usecase {
+	return &Usecase{
+		ledgerRepo: lr,
+		ctxTimeout: t,
+		validate:   v,
+	}
+}
+
+func (u *Usecase) History(ctx context.Context, ss *domain.Session, req *ledger.HistoryReq) (*ledger.HistoryRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, ledger.InvalidInputError
+	}
+
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = ledger.DefaultHistoryPageSize
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	es, total, err := u.ledgerRepo.GetHistory(c, ss.UserID, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*ledger.EntryInfo, len(es))
+	for i, e := range es {
+		entries[i] = &ledger.EntryInfo{
+			ID:          e.ID,
+			Type:        e.Type,
+			Description: e.Description,
+			CreatedAt:   e.CreatedAt,
+			TripID:      e.TripID,
+			PaymentID:   e.PaymentID,
+			Amount:      e.Amount,
+			Balance:     e.Balance,
+		}
+	}
+
+	return &ledger.HistoryRes{
+		Entries:  entries,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}, nil
+}
+
+func (u *Usecase) Reconcile(ctx context.Context) ([]*domain.BalanceMismatch, error) {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	return u.ledgerRepo.GetMismatches(c)
+}
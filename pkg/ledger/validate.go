@@ -0,0 +1,5 @@
+package ledger
+
+type Validate interface {
+	RawRequest(s interface{}) error
+}
@@ -0,0 +1,34 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/ledger"
+	"github.com/wascript3r/autonuoma/pkg/periodic"
+	"github.com/wascript3r/cryptopay/pkg/logger"
+)
+
+// NewReconcileWorker periodically compares the stored wallet balances
+// with the journal and logs every balance which differs.
+func NewReconcileWorker(lu ledger.Usecase, log logger.Usecase, interval time.Duration) *periodic.Worker {
+	reconcile := func(ctx context.Context) (int, error) {
+		ms, err := lu.Reconcile(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, m := range ms {
+			log.Error("User %d balance %s differs from the journal balance %s", m.UserID, m.Stored, m.Journal)
+		}
+		return len(ms), nil
+	}
+
+	return periodic.NewWorker(
+		reconcile,
+		log,
+		interval,
+		"Cannot reconcile wallet balances",
+		"Found %d wallet balance mismatch(es)",
+	)
+}
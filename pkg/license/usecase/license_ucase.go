@@ -48,6 +48,8 @@ func (u *Usecase) changeStatus(ctx context.Context, newStatus domain.LicenseStat
 		return err
 	}
 
+	defer tx.Rollback()
+
 	status, err := u.licenseRepo.GetStatusTx(c, tx, req.LicenseID)
 	if err != nil {
 		if err == domain.ErrNotFound {
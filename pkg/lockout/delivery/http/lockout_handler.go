@@ -0,0 +1,51 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/wascript3r/autonuoma/pkg/lockout"
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+	httpjson "github.com/wascript3r/httputil/json"
+	"github.com/wascript3r/httputil/middleware"
+)
+
+type HTTPHandler struct {
+	lockoutUcase lockout.Usecase
+}
+
+func NewHTTPHandler(ctx context.Context, r *httprouter.Router, admin *middleware.StackCtx, lu lockout.Usecase) {
+	handler := &HTTPHandler{
+		lockoutUcase: lu,
+	}
+
+	r.GET("/api/admin/lockouts", admin.Wrap(ctx, handler.GetLockouts))
+}
+
+// ServeRetryError responds with the retry hint in the body and the Retry-After header
+func ServeRetryError(w http.ResponseWriter, err *lockout.RetryError) {
+	w.Header().Set("Retry-After", strconv.Itoa(err.Seconds()))
+	httpjson.ServeErr(w, lockout.TooManyAttemptsError, &lockout.RetryRes{RetryAfter: err.Seconds()})
+}
+
+func serveError(w http.ResponseWriter, err error) {
+	code := errcode.UnwrapErr(err, lockout.UnknownError)
+	if code == lockout.UnknownError {
+		httpjson.InternalErrorCustom(w, code, nil)
+		return
+	}
+
+	httpjson.ServeErr(w, code, nil)
+}
+
+func (h *HTTPHandler) GetLockouts(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	res, err := h.lockoutUcase.GetLockouts(r.Context())
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
@@ -0,0 +1,34 @@
+package lockout
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+)
+
+var (
+	// Error codes
+
+	UnknownError = errcode.UnknownError
+
+	TooManyAttemptsError = errcode.New(
+		"too_many_attempts",
+		errors.New("too many failed attempts, try again later"),
+	)
+)
+
+// RetryError is returned instead of TooManyAttemptsError to carry the retry hint
+type RetryError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RetryError) Error() string {
+	return TooManyAttemptsError.Error()
+}
+
+// Seconds rounds the delay up, so retrying after it always succeeds
+func (e *RetryError) Seconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
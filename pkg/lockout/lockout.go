@@ -0,0 +1,29 @@
+package lockout
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+// Check
+
+type RetryRes struct {
+	// Seconds
+	RetryAfter int `json:"retryAfter"`
+}
+
+// GetLockouts
+
+type LockoutInfo struct {
+	ID       int                 `json:"id"`
+	Scope    domain.LockoutScope `json:"scope"`
+	Subject  string              `json:"subject"`
+	Failures int                 `json:"failures"`
+	Until    time.Time           `json:"until"`
+	Created  time.Time           `json:"created"`
+}
+
+type GetLockoutsRes struct {
+	Lockouts []*LockoutInfo `json:"lockouts"`
+}
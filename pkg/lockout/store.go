@@ -0,0 +1,23 @@
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+// Store keeps the attempt counters and the lockout log
+type Store interface {
+	// Get returns domain.ErrNotFound if the subject has no failures
+	Get(ctx context.Context, scope domain.LockoutScope, subject string) (*domain.LoginAttempts, error)
+	// Increment atomically counts a failure at now and returns the updated counter.
+	// The count starts over if the previous failure is older than window.
+	Increment(ctx context.Context, scope domain.LockoutScope, subject string, now time.Time, window time.Duration) (*domain.LoginAttempts, error)
+	// Block never shortens an already longer block
+	Block(ctx context.Context, scope domain.LockoutScope, subject string, until time.Time) error
+	Delete(ctx context.Context, scope domain.LockoutScope, subject string) error
+
+	InsertLockout(ctx context.Context, l *domain.Lockout) error
+	GetLockouts(ctx context.Context, limit int) ([]*domain.Lockout, error)
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+const (
+	// Counters idle longer than this are swept, they are forgotten by every sane policy by then
+	memoryIdle = 24 * time.Hour
+	// Only the latest lockouts are kept
+	memoryLockouts = 1000
+)
+
+type key struct {
+	scope   domain.LockoutScope
+	subject string
+}
+
+// Memory keeps the counters in the process, so they are lost on restart
+// and are not shared between instances. Meant for development and single instance deployments.
+type Memory struct {
+	mx        sync.Mutex
+	attempts  map[key]domain.LoginAttempts
+	lockouts  []*domain.Lockout
+	lastID    int
+	lastSweep time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		attempts:  make(map[key]domain.LoginAttempts),
+		lastSweep: time.Now(),
+	}
+}
+
+func (m *Memory) Get(_ context.Context, scope domain.LockoutScope, subject string) (*domain.LoginAttempts, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	a, ok := m.attempts[key{scope, subject}]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &a, nil
+}
+
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < memoryIdle {
+		return
+	}
+	m.lastSweep = now
+
+	for k, a := range m.attempts {
+		if now.Sub(a.LastFailure) > memoryIdle && a.RetryAfter(now) == 0 {
+			delete(m.attempts, k)
+		}
+	}
+}
+
+func (m *Memory) Increment(_ context.Context, scope domain.LockoutScope, subject string, now time.Time, window time.Duration) (*domain.LoginAttempts, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	k := key{scope, subject}
+	a, ok := m.attempts[k]
+	if !ok {
+		a = domain.LoginAttempts{Scope: scope, Subject: subject}
+	}
+
+	if now.Sub(a.LastFailure) > window {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailure = now
+
+	m.attempts[k] = a
+	m.sweep(now)
+
+	return &a, nil
+}
+
+func (m *Memory) Block(_ context.Context, scope domain.LockoutScope, subject string, until time.Time) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	k := key{scope, subject}
+	a, ok := m.attempts[k]
+	if !ok || (a.BlockedUntil != nil && !a.BlockedUntil.Before(until)) {
+		return nil
+	}
+
+	a.BlockedUntil = &until
+	m.attempts[k] = a
+
+	return nil
+}
+
+func (m *Memory) Delete(_ context.Context, scope domain.LockoutScope, subject string) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	delete(m.attempts, key{scope, subject})
+	return nil
+}
+
+func (m *Memory) InsertLockout(_ context.Context, l *domain.Lockout) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.lastID++
+	l.ID = m.lastID
+
+	m.lockouts = append(m.lockouts, l)
+	if len(m.lockouts) > memoryLockouts {
+		m.lockouts = m.lockouts[len(m.lockouts)-memoryLockouts:]
+	}
+
+	return nil
+}
+
+func (m *Memory) GetLockouts(_ context.Context, limit int) ([]*domain.Lockout, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	ls := make([]*domain.Lockout, 0, limit)
+	for i := len(m.lockouts) - 1; i >= 0 && len(ls) < limit; i-- {
+		ls = append(ls, m.lockouts[i])
+	}
+	return ls, nil
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
+)
+
+const (
+	getAttemptsSQL    = "SELECT tipas, subjektas, nesėkmės, paskutinė_nesėkmė, blokuota_iki FROM prisijungimo_bandymai WHERE tipas = $1 AND subjektas = $2"
+	incrementSQL      = "INSERT INTO prisijungimo_bandymai (tipas, subjektas, nesėkmės, paskutinė_nesėkmė) VALUES ($1, $2, 1, $3) ON CONFLICT (tipas, subjektas) DO UPDATE SET nesėkmės = CASE WHEN prisijungimo_bandymai.paskutinė_nesėkmė < $3 - $4 * INTERVAL '1 second' THEN 1 ELSE prisijungimo_bandymai.nesėkmės + 1 END, paskutinė_nesėkmė = EXCLUDED.paskutinė_nesėkmė RETURNING tipas, subjektas, nesėkmės, paskutinė_nesėkmė, blokuota_iki"
+	blockSQL          = "UPDATE prisijungimo_bandymai SET blokuota_iki = GREATEST(blokuota_iki, $3) WHERE tipas = $1 AND subjektas = $2"
+	deleteAttemptsSQL = "DELETE FROM prisijungimo_bandymai WHERE tipas = $1 AND subjektas = $2"
+
+	insertLockoutSQL = "INSERT INTO prisijungimo_blokavimai (tipas, subjektas, nesėkmės, blokuota_iki, sukurta) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	getLockoutsSQL   = "SELECT id, tipas, subjektas, nesėkmės, blokuota_iki, sukurta FROM prisijungimo_blokavimai ORDER BY id DESC LIMIT $1"
+)
+
+// PgStore shares the counters between all instances of the app
+type PgStore struct {
+	conn *sql.DB
+}
+
+func NewPgStore(c *sql.DB) *PgStore {
+	return &PgStore{c}
+}
+
+func (p *PgStore) Get(ctx context.Context, scope domain.LockoutScope, subject string) (*domain.LoginAttempts, error) {
+	a := &domain.LoginAttempts{}
+
+	err := p.conn.QueryRowContext(ctx, getAttemptsSQL, scope, subject).Scan(
+		&a.Scope,
+		&a.Subject,
+		&a.Failures,
+		&a.LastFailure,
+		&a.BlockedUntil,
+	)
+	if err != nil {
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	return a, nil
+}
+
+func (p *PgStore) Increment(ctx context.Context, scope domain.LockoutScope, subject string, now time.Time, window time.Duration) (*domain.LoginAttempts, error) {
+	a := &domain.LoginAttempts{}
+
+	err := p.conn.QueryRowContext(ctx, incrementSQL, scope, subject, now, window.Seconds()).Scan(
+		&a.Scope,
+		&a.Subject,
+		&a.Failures,
+		&a.LastFailure,
+		&a.BlockedUntil,
+	)
+	if err != nil {
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	return a, nil
+}
+
+func (p *PgStore) Block(ctx context.Context, scope domain.LockoutScope, subject string, until time.Time) error {
+	_, err := p.conn.ExecContext(ctx, blockSQL, scope, subject, until)
+	return err
+}
+
+func (p *PgStore) Delete(ctx context.Context, scope domain.LockoutScope, subject string) error {
+	_, err := p.conn.ExecContext(ctx, deleteAttemptsSQL, scope, subject)
+	return err
+}
+
+func (p *PgStore) InsertLockout(ctx context.Context, l *domain.Lockout) error {
+	err := p.conn.QueryRowContext(ctx, insertLockoutSQL, l.Scope, l.Subject, l.Failures, l.Until, l.Created).Scan(&l.ID)
+	return pgsql.ParsePgError(err)
+}
+
+func (p *PgStore) GetLockouts(ctx context.Context, limit int) ([]*domain.Lockout, error) {
+	rows, err := p.conn.QueryContext(ctx, getLockoutsSQL, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ls []*domain.Lockout
+
+	for rows.Next() {
+		l := &domain.Lockout{}
+
+		err := rows.Scan(&l.ID, &l.Scope, &l.Subject, &l.Failures, &l.Until, &l.Created)
+		if err != nil {
+			return nil, err
+		}
+
+		ls = append(ls, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ls, nil
+}
@@ -0,0 +1,12 @@
+package lockout
+
+import "context"
+
+type Usecase interface {
+	// Check returns a *RetryError while the account or the IP address is blocked
+	Check(ctx context.Context, email, ip string) error
+	Fail(ctx context.Context, email, ip string) error
+	// Succeed clears the account failures, the IP address failures are kept
+	Succeed(ctx context.Context, email string) error
+	GetLockouts(ctx context.Context) (*GetLockoutsRes, error)
+}
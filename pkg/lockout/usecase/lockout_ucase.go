@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/lockout"
+	"github.com/wascript3r/cryptopay/pkg/logger"
+)
+
+const lockoutsLimit = 100
+
+type Usecase struct {
+	store      lockout.Store
+	ctxTimeout time.Duration
+
+	log      logger.Usecase
+	policies map[domain.LockoutScope]domain.AttemptPolicy
+}
+
+func New(s lockout.Store, t time.Duration, log logger.Usecase, account, ip domain.AttemptPolicy) *Usecase {
+	return &Usecase{
+		store:      s,
+		ctxTimeout: t,
+
+		log: log,
+		policies: map[domain.LockoutScope]domain.AttemptPolicy{
+			domain.AccountLockoutScope: account,
+			domain.IPLockoutScope:      ip,
+		},
+	}
+}
+
+type subject struct {
+	scope domain.LockoutScope
+	value string
+}
+
+func subjects(email, ip string) []subject {
+	ss := []subject{{domain.AccountLockoutScope, strings.ToLower(email)}}
+	if ip != "" {
+		ss = append(ss, subject{domain.IPLockoutScope, ip})
+	}
+	return ss
+}
+
+func (u *Usecase) Check(ctx context.Context, email, ip string) error {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	var (
+		now   = time.Now()
+		retry time.Duration
+	)
+
+	for _, s := range subjects(email, ip) {
+		a, err := u.store.Get(c, s.scope, s.value)
+		if err != nil {
+			if err == domain.ErrNotFound {
+				continue
+			}
+			return err
+		}
+
+		if d := a.RetryAfter(now); d > retry {
+			retry = d
+		}
+	}
+
+	if retry > 0 {
+		return &lockout.RetryError{RetryAfter: retry}
+	}
+	return nil
+}
+
+func (u *Usecase) Fail(ctx context.Context, email, ip string) error {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	now := time.Now()
+
+	for _, s := range subjects(email, ip) {
+		p := u.policies[s.scope]
+
+		a, err := u.store.Increment(c, s.scope, s.value, now, p.Window)
+		if err != nil {
+			return err
+		}
+
+		locked := p.Block(a, now)
+		if a.RetryAfter(now) == 0 {
+			continue
+		}
+
+		if err := u.store.Block(c, a.Scope, a.Subject, *a.BlockedUntil); err != nil {
+			return err
+		}
+
+		if !locked {
+			continue
+		}
+
+		u.log.Info("Sign in locked for %s %s until %s after %d failures", a.Scope, a.Subject, a.BlockedUntil.Format(time.RFC3339), a.Failures)
+
+		err = u.store.InsertLockout(c, &domain.Lockout{
+			Scope:    a.Scope,
+			Subject:  a.Subject,
+			Failures: a.Failures,
+			Until:    *a.BlockedUntil,
+			Created:  now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *Usecase) Succeed(ctx context.Context, email string) error {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	return u.store.Delete(c, domain.AccountLockoutScope, strings.ToLower(email))
+}
+
+func (u *Usecase) GetLockouts(ctx context.Context) (*lockout.GetLockoutsRes, error) {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	ls, err := u.store.GetLockouts(c, lockoutsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &lockout.GetLockoutsRes{
+		Lockouts: make([]*lockout.LockoutInfo, len(ls)),
+	}
+	for i, l := range ls {
+		res.Lockouts[i] = &lockout.LockoutInfo{
+			ID:       l.ID,
+			Scope:    l.Scope,
+			Subject:  l.Subject,
+			Failures: l.Failures,
+			Until:    l.Until,
+			Created:  l.Created,
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,23 @@
+package mail
+
+import (
+	"context"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+)
+
+type Repository interface {
+	NewTx(ctx context.Context) (repository.Transaction, error)
+
+	Enqueue(ctx context.Context, e *domain.Email) error
+	EnqueueTx(ctx context.Context, tx repository.Transaction, e *domain.Email) error
+
+	// Claim reserves a batch of unsent emails for the dispatcher until the given time,
+	// emails claimed by other dispatchers are skipped
+	Claim(ctx context.Context, maxAttempts, limit int, until time.Time) ([]*domain.Email, error)
+
+	SetSent(ctx context.Context, emailID int, t time.Time) error
+	SetFailed(ctx context.Context, emailID int, reason string) error
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
+)
+
+const (
+	enqueueSQL = "INSERT INTO laiškai (gavėjas, tema, turinys, sukurta) VALUES ($1, $2, $3, $4) RETURNING id"
+
+	// Claimed emails are skipped by other dispatchers until the claim expires
+	claimSQL     = "UPDATE laiškai SET užimta_iki = $4 WHERE id IN (SELECT id FROM laiškai WHERE išsiųsta IS NULL AND bandymai < $1 AND (užimta_iki IS NULL OR užimta_iki < $3) ORDER BY id ASC LIMIT $2 FOR UPDATE SKIP LOCKED) RETURNING id, gavėjas, tema, turinys, bandymai, klaida, sukurta"
+	setSentSQL   = "UPDATE laiškai SET išsiųsta = $2, bandymai = bandymai + 1, klaida = NULL, užimta_iki = NULL WHERE id = $1"
+	setFailedSQL = "UPDATE laiškai SET bandymai = bandymai + 1, klaida = $2, užimta_iki = NULL WHERE id = $1"
+)
+
+type PgRepo struct {
+	conn *sql.DB
+}
+
+func NewPgRepo(c *sql.DB) *PgRepo {
+	return &PgRepo{c}
+}
+
+func (p *PgRepo) NewTx(ctx context.Context) (repository.Transaction, error) {
+	return p.conn.BeginTx(ctx, nil)
+}
+
+func (p *PgRepo) enqueue(ctx context.Context, q pgsql.Querier, e *domain.Email) error {
+	e.CreatedAt = time.Now()
+
+	err := q.QueryRowContext(ctx, enqueueSQL, e.To, e.Subject, e.Body, e.CreatedAt).Scan(&e.ID)
+	return pgsql.ParsePgError(err)
+}
+
+func (p *PgRepo) Enqueue(ctx context.Context, e *domain.Email) error {
+	return p.enqueue(ctx, p.conn, e)
+}
+
+func (p *PgRepo) EnqueueTx(ctx context.Context, tx repository.Transaction, e *domain.Email) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := p.enqueue(ctx, sqlTx, e)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (p *PgRepo) Claim(ctx context.Context, maxAttempts, limit int, until time.Time) ([]*domain.Email, error) {
+	rows, err := p.conn.QueryContext(ctx, claimSQL, maxAttempts, limit, time.Now(), until)
+	if err != nil {
+		return nil, err
+	}
+
+	var es []*domain.Email
+
+	for rows.Next() {
+		e := &domain.Email{}
+
+		err := rows.Scan(&e.ID, &e.To, &e.Subject, &e.Body, &e.Attempts, &e.LastError, &e.CreatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		es = append(es, e)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	// The emails come back in the update order
+	sort.Slice(es, func(i, j int) bool {
+		return es[i].ID < es[j].ID
+	})
+
+	return es, nil
+}
+
+func (p *PgRepo) SetSent(ctx context.Context, emailID int, t time.Time) error {
+	_, err := p.conn.ExecContext(ctx, setSentSQL, emailID, t)
+	return err
+}
+
+func (p *PgRepo) SetFailed(ctx context.Context, emailID int, reason string) error {
+	_, err := p.conn.ExecContext(ctx, setFailedSQL, emailID, reason)
+	return err
+}
@@ -0,0 +1,11 @@
+package mail
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Sender interface {
+	Send(ctx context.Context, e *domain.Email) error
+}
@@ -0,0 +1,38 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/cryptopay/pkg/logger"
+)
+
+// Log is a development sender, emails are only logged.
+// When dir is set every email is also written there as an .eml file.
+type Log struct {
+	log  logger.Usecase
+	dir  string
+	from string
+}
+
+func NewLog(log logger.Usecase, dir, from string) *Log {
+	return &Log{log, dir, from}
+}
+
+func (l *Log) Send(_ context.Context, e *domain.Email) error {
+	l.log.Info("Email #%d to %s: %s\n%s", e.ID, e.To, e.Subject, e.Body)
+
+	if l.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(l.dir, fmt.Sprintf("%06d.eml", e.ID))
+	return os.WriteFile(path, message(l.from, e), 0o644)
+}
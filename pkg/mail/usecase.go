@@ -0,0 +1,7 @@
+package mail
+
+import "context"
+
+type Usecase interface {
+	Dispatch(ctx context.Context) (int, error)
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/mail"
+)
+
+type Usecase struct {
+	mailRepo   mail.Repository
+	ctxTimeout time.Duration
+
+	sender mail.Sender
+
+	batchSize   int
+	maxAttempts int
+}
+
+func New(mr mail.Repository, t time.Duration, s mail.Sender, batchSize, maxAttempts int) *Usecase {
+	return &Usecase{
+		mailRepo:   mr,
+		ctxTimeout: t,
+
+		sender: s,
+
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Dispatch sends a batch of outbox emails and returns the number of sent ones.
+// Failed emails are retried by later dispatches until maxAttempts is reached.
+// The batch is claimed for as long as sending it may take, so no transaction
+// is held open while talking to the mail server.
+func (u *Usecase) Dispatch(ctx context.Context) (int, error) {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	until := time.Now().Add(time.Duration(2*u.batchSize+1) * u.ctxTimeout)
+	es, err := u.mailRepo.Claim(c, u.maxAttempts, u.batchSize, until)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, e := range es {
+		c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+		sendErr := u.sender.Send(c, e)
+		cancel()
+
+		c, cancel = context.WithTimeout(ctx, u.ctxTimeout)
+		if sendErr != nil {
+			err = u.mailRepo.SetFailed(c, e.ID, sendErr.Error())
+		} else {
+			err = u.mailRepo.SetSent(c, e.ID, time.Now())
+			n++
+		}
+		cancel()
+
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
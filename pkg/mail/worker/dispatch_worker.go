@@ -0,0 +1,20 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/mail"
+	"github.com/wascript3r/autonuoma/pkg/periodic"
+	"github.com/wascript3r/cryptopay/pkg/logger"
+)
+
+// NewDispatchWorker periodically sends the emails waiting in the outbox.
+func NewDispatchWorker(mu mail.Usecase, log logger.Usecase, interval time.Duration) *periodic.Worker {
+	return periodic.NewWorker(
+		mu.Dispatch,
+		log,
+		interval,
+		"Cannot dispatch emails",
+		"Sent %d email(s)",
+	)
+}
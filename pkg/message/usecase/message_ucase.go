@@ -63,6 +63,8 @@ func (u *Usecase) Send(ctx context.Context, userID int, role domain.Role, req *m
 		return err
 	}
 
+	defer tx.Rollback()
+
 	meta, err := u.ticketRepo.GetMetaTx(c, tx, req.TicketID)
 	if err != nil {
 		if err == domain.ErrNotFound {
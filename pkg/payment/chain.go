@@ -0,0 +1,15 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type ChainWatcher interface {
+	// NewAddress returns a deposit address dedicated to the payment
+	NewAddress(ctx context.Context, paymentID int) (string, error)
+
+	// Received returns the confirmed transfer to the invoice address or nil
+	Received(ctx context.Context, inv *domain.CryptoInvoice) (*domain.ChainTransfer, error)
+}
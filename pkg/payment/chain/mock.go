@@ -0,0 +1,45 @@
+package chain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+// Regtest style bech32 prefix, mock addresses are never valid on a real chain
+const mockAddressPrefix = "bcrt1q"
+
+// Mock is a deterministic chain watcher for development and tests.
+// Every invoice is paid in full confirmAfter its creation,
+// a negative duration leaves all invoices unpaid until they expire.
+type Mock struct {
+	confirmAfter time.Duration
+}
+
+func NewMock(confirmAfter time.Duration) *Mock {
+	return &Mock{confirmAfter}
+}
+
+func hash(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func (m *Mock) NewAddress(_ context.Context, paymentID int) (string, error) {
+	return mockAddressPrefix + hash("payment:" + strconv.Itoa(paymentID))[:38], nil
+}
+
+func (m *Mock) Received(_ context.Context, inv *domain.CryptoInvoice) (*domain.ChainTransfer, error) {
+	if m.confirmAfter < 0 || time.Since(inv.CreatedAt) < m.confirmAfter {
+		return nil, nil
+	}
+
+	return &domain.ChainTransfer{
+		TxHash: hash("tx:" + inv.Address),
+		Amount: inv.Amount,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/wascript3r/autonuoma/pkg/payment"
+	"github.com/wascript3r/autonuoma/pkg/session"
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+	httpjson "github.com/wascript3r/httputil/json"
+	"github.com/wascript3r/httputil/middleware"
+)
+
+const maxEventSize = 64 << 10
+
+type HTTPHandler struct {
+	paymentUcase payment.Usecase
+	sessionUcase session.Usecase
+}
+
+func NewHTTPHandler(ctx context.Context, r *httprouter.Router, client *middleware.StackCtx, admin *middleware.StackCtx, pu payment.Usecase, su session.Usecase) {
+	handler := &HTTPHandler{
+		paymentUcase: pu,
+		sessionUcase: su,
+	}
+
+	r.POST("/api/payment/topup", client.Wrap(ctx, handler.TopUp))
+	r.POST("/api/payment/webhook/:provider", handler.Webhook)
+
+	r.POST("/api/payment/refund", admin.Wrap(ctx, handler.Refund))
+}
+
+func serveError(w http.ResponseWriter, err error) {
+	if err == payment.InvalidInputError {
+		httpjson.BadRequestCustom(w, payment.InvalidInputError, nil)
+		return
+	}
+
+	code := errcode.UnwrapErr(err, payment.UnknownError)
+	if code == payment.UnknownError {
+		httpjson.InternalErrorCustom(w, code, nil)
+		return
+	}
+
+	httpjson.ServeErr(w, code, nil)
+}
+
+func (h *HTTPHandler) TopUp(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+	req := &payment.TopUpReq{}
+
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.paymentUcase.TopUp(r.Context(), s, req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
+
+func (h *HTTPHandler) Webhook(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	// Signatures are computed over the raw body
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEventSize))
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	err = h.paymentUcase.HandleEvent(r.Context(), ps.ByName("provider"), payload, r.Header)
+	if err != nil {
+		if err == payment.InvalidEventError {
+			httpjson.BadRequestCustom(w, payment.InvalidEventError, nil)
+			return
+		}
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, nil)
+}
+
+func (h *HTTPHandler) Refund(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := &payment.RefundReq{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.paymentUcase.Refund(r.Context(), req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
@@ -0,0 +1,59 @@
+package payment
+
+import (
+	"errors"
+
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+)
+
+var (
+	ErrInvalidEvent        = errors.New("invalid provider event")
+	ErrUnknownReference    = errors.New("unknown payment reference")
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrRefundNotSupported  = errors.New("provider does not support refunds")
+
+	// Error codes
+
+	InvalidInputError = errcode.InvalidInputError
+	UnknownError      = errcode.UnknownError
+
+	PaymentNotFoundError = errcode.New(
+		"payment_not_found",
+		errors.New("payment not found"),
+	)
+
+	IdempotencyKeyConflictError = errcode.New(
+		"idempotency_key_conflict",
+		errors.New("idempotency key is already used for a different payment"),
+	)
+
+	UnknownProviderError = errcode.New(
+		"unknown_provider",
+		errors.New("unknown payment provider"),
+	)
+
+	InvalidEventError = errcode.New(
+		"invalid_event",
+		errors.New("invalid payment provider event"),
+	)
+
+	PaymentNotRefundableError = errcode.New(
+		"payment_not_refundable",
+		errors.New("only successful payments can be refunded"),
+	)
+
+	PaymentPartlyRefundedError = errcode.New(
+		"payment_partly_refunded",
+		errors.New("payment is already partly refunded by support"),
+	)
+
+	RefundNotSupportedError = errcode.New(
+		"refund_not_supported",
+		errors.New("payment provider does not support refunds"),
+	)
+
+	InsufficientBalanceError = errcode.New(
+		"insufficient_balance",
+		errors.New("balance is too low to refund the payment"),
+	)
+)
@@ -0,0 +1,42 @@
+package payment
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+// TopUp
+
+type TopUpReq struct {
+	Amount         domain.Money `json:"amount"`
+	IdempotencyKey string       `json:"idempotency_key" validate:"required,max=64"`
+	Provider       string       `json:"provider" validate:"omitempty,max=20"`
+}
+
+type InvoiceInfo struct {
+	Asset     string    `json:"asset"`
+	Address   string    `json:"address"`
+	Amount    string    `json:"amount"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type TopUpRes struct {
+	PaymentID   int                  `json:"payment_id"`
+	Amount      domain.Money         `json:"amount"`
+	Status      domain.PaymentStatus `json:"status"`
+	RedirectURL string               `json:"redirect_url,omitempty"`
+	Invoice     *InvoiceInfo         `json:"invoice,omitempty"`
+}
+
+// Refund
+
+type RefundReq struct {
+	PaymentID int `json:"payment_id" validate:"required"`
+}
+
+type RefundRes struct {
+	PaymentID int                  `json:"payment_id"`
+	Amount    domain.Money         `json:"amount"`
+	Status    domain.PaymentStatus `json:"status"`
+}
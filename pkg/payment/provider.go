@@ -0,0 +1,28 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Provider interface {
+	Name() string
+
+	// CreateIntent registers the payment with the provider.
+	// It is called again for retried top-ups and must be idempotent per payment.
+	CreateIntent(ctx context.Context, p *domain.Payment) (*domain.PaymentIntent, error)
+
+	// ParseEvent verifies and decodes a webhook request,
+	// ErrInvalidEvent is returned for forged or malformed events
+	ParseEvent(payload []byte, h http.Header) (*domain.PaymentEvent, error)
+
+	Refund(ctx context.Context, p *domain.Payment) error
+}
+
+// Poller is implemented by providers without webhooks,
+// their events are collected periodically instead.
+type Poller interface {
+	Poll(ctx context.Context) ([]*domain.PaymentEvent, error)
+}
@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/payment"
+)
+
+const CryptoName = "crypto"
+
+// Crypto settles top-ups paid to generated crypto invoices.
+// It has no webhooks, the chain watcher is polled for transfers instead.
+type Crypto struct {
+	invoiceRepo payment.InvoiceRepository
+	watcher     payment.ChainWatcher
+
+	asset string
+	rate  domain.Money
+	ttl   time.Duration
+}
+
+// NewCrypto quotes invoices at a fixed rate, the fiat price of a single coin
+func NewCrypto(ir payment.InvoiceRepository, w payment.ChainWatcher, asset string, rate domain.Money, ttl time.Duration) *Crypto {
+	return &Crypto{
+		invoiceRepo: ir,
+		watcher:     w,
+
+		asset: asset,
+		rate:  rate,
+		ttl:   ttl,
+	}
+}
+
+func (c *Crypto) Name() string {
+	return CryptoName
+}
+
+// quote converts fiat money to base units of the asset rounding up
+func (c *Crypto) quote(m domain.Money) (int64, error) {
+	if m.Currency != c.rate.Currency || !c.rate.IsPositive() {
+		return 0, payment.ErrUnsupportedCurrency
+	}
+
+	units := int64(math.Pow10(domain.CryptoDecimals))
+	if m.Amount > math.MaxInt64/units {
+		return 0, domain.ErrInvalidMoney
+	}
+
+	n := m.Amount * units
+	q := n / c.rate.Amount
+	if n%c.rate.Amount != 0 {
+		q++
+	}
+
+	return q, nil
+}
+
+func (c *Crypto) CreateIntent(ctx context.Context, p *domain.Payment) (*domain.PaymentIntent, error) {
+	inv, err := c.invoiceRepo.GetByPayment(ctx, p.ID)
+	if err != nil {
+		if err != domain.ErrNotFound {
+			return nil, err
+		}
+
+		inv, err = c.newInvoice(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &domain.PaymentIntent{
+		Reference: inv.Address,
+		Invoice:   inv,
+	}, nil
+}
+
+func (c *Crypto) newInvoice(ctx context.Context, p *domain.Payment) (*domain.CryptoInvoice, error) {
+	amount, err := c.quote(p.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := c.watcher.NewAddress(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &domain.CryptoInvoice{
+		PaymentID:  p.ID,
+		Asset:      c.asset,
+		Address:    addr,
+		Amount:     amount,
+		FiatAmount: p.Amount,
+		ExpiresAt:  time.Now().Add(c.ttl),
+	}
+
+	err = c.invoiceRepo.Insert(ctx, inv)
+	if err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+func (c *Crypto) ParseEvent(_ []byte, _ http.Header) (*domain.PaymentEvent, error) {
+	return nil, payment.ErrInvalidEvent
+}
+
+func (c *Crypto) Refund(_ context.Context, _ *domain.Payment) error {
+	return payment.ErrRefundNotSupported
+}
+
+// Poll confirms fully paid invoices and fails the expired ones.
+// Transfers arriving after the expiry but before the next poll are still accepted.
+func (c *Crypto) Poll(ctx context.Context) ([]*domain.PaymentEvent, error) {
+	invs, err := c.invoiceRepo.GetPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var evs []*domain.PaymentEvent
+	now := time.Now()
+
+	for _, inv := range invs {
+		tr, err := c.watcher.Received(ctx, inv)
+		if err != nil {
+			return nil, err
+		}
+
+		ev := &domain.PaymentEvent{
+			Reference: inv.Address,
+			Amount:    inv.FiatAmount,
+		}
+
+		switch {
+		case tr != nil && tr.Amount >= inv.Amount:
+			if err := c.invoiceRepo.SetTxHash(ctx, inv.ID, tr.TxHash); err != nil {
+				return nil, err
+			}
+			ev.Type = domain.PaymentSucceededEvent
+
+		case now.After(inv.ExpiresAt):
+			ev.Type = domain.PaymentFailedEvent
+
+		default:
+			continue
+		}
+
+		evs = append(evs, ev)
+	}
+
+	return evs, nil
+}
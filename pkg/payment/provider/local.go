@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/payment"
+)
+
+const (
+	LocalName = "local"
+
+	// Hex encoded HMAC-SHA256 of the request body
+	LocalSignatureHeader = "X-Local-Signature"
+
+	localReferencePrefix = "local_"
+)
+
+type localEvent struct {
+	Type      domain.PaymentEventType `json:"type"`
+	Reference string                  `json:"reference"`
+	Amount    domain.Money            `json:"amount"`
+}
+
+// Local is a deterministic in-process gateway for development and tests.
+// References are derived from payment IDs and events are signed with a shared secret.
+// With auto confirmation every intent succeeds immediately.
+type Local struct {
+	secret      []byte
+	autoConfirm bool
+}
+
+func NewLocal(secret string, autoConfirm bool) *Local {
+	return &Local{[]byte(secret), autoConfirm}
+}
+
+func (l *Local) Name() string {
+	return LocalName
+}
+
+func (l *Local) CreateIntent(_ context.Context, p *domain.Payment) (*domain.PaymentIntent, error) {
+	in := &domain.PaymentIntent{
+		Reference: localReferencePrefix + strconv.Itoa(p.ID),
+	}
+
+	if l.autoConfirm {
+		in.Event = &domain.PaymentEvent{
+			Type:      domain.PaymentSucceededEvent,
+			Reference: in.Reference,
+			Amount:    p.Amount,
+		}
+	}
+
+	return in, nil
+}
+
+// Sign returns the signature expected in LocalSignatureHeader
+func (l *Local) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *Local) ParseEvent(payload []byte, h http.Header) (*domain.PaymentEvent, error) {
+	// An empty secret rejects every event
+	if len(l.secret) == 0 || !hmac.Equal([]byte(h.Get(LocalSignatureHeader)), []byte(l.Sign(payload))) {
+		return nil, payment.ErrInvalidEvent
+	}
+
+	ev := &localEvent{}
+	if err := json.Unmarshal(payload, ev); err != nil {
+		return nil, payment.ErrInvalidEvent
+	}
+
+	if !strings.HasPrefix(ev.Reference, localReferencePrefix) {
+		return nil, payment.ErrInvalidEvent
+	}
+
+	switch ev.Type {
+	case domain.PaymentSucceededEvent, domain.PaymentFailedEvent:
+	default:
+		return nil, payment.ErrInvalidEvent
+	}
+
+	return &domain.PaymentEvent{
+		Type:      ev.Type,
+		Reference: ev.Reference,
+		Amount:    ev.Amount,
+	}, nil
+}
+
+func (l *Local) Refund(_ context.Context, p *domain.Payment) error {
+	if p.Reference == nil || !strings.HasPrefix(*p.Reference, localReferencePrefix) {
+		return payment.ErrUnknownReference
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+)
+
+type Repository interface {
+	NewTx(ctx context.Context) (repository.Transaction, error)
+
+	// Insert returns domain.ErrExists if the idempotency key is already used
+	Insert(ctx context.Context, p *domain.Payment) error
+	GetByKey(ctx context.Context, uid int, key string) (*domain.Payment, error)
+	SetReference(ctx context.Context, id int, ref string) error
+
+	Get(ctx context.Context, id int) (*domain.Payment, error)
+	GetTx(ctx context.Context, tx repository.Transaction, id int) (*domain.Payment, error)
+
+	GetByReference(ctx context.Context, provider, ref string) (*domain.Payment, error)
+	GetByReferenceTx(ctx context.Context, tx repository.Transaction, provider, ref string) (*domain.Payment, error)
+
+	SetStatus(ctx context.Context, id int, status domain.PaymentStatus) error
+	SetStatusTx(ctx context.Context, tx repository.Transaction, id int, status domain.PaymentStatus) error
+
+	// HasRefunds reports whether support agents refunded any part of the payment
+	HasRefunds(ctx context.Context, id int) (bool, error)
+	HasRefundsTx(ctx context.Context, tx repository.Transaction, id int) (bool, error)
+}
+
+type InvoiceRepository interface {
+	Insert(ctx context.Context, inv *domain.CryptoInvoice) error
+	GetByPayment(ctx context.Context, paymentID int) (*domain.CryptoInvoice, error)
+
+	// GetPending returns invoices of payments which are not settled yet
+	GetPending(ctx context.Context) ([]*domain.CryptoInvoice, error)
+	SetTxHash(ctx context.Context, id int, hash string) error
+}
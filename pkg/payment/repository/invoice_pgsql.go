@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
+)
+
+const (
+	insertInvoiceSQL = "INSERT INTO kripto_sąskaitos (fk_mokejimas, turtas, adresas, suma, mokėtina_suma, valiuta, galioja_iki) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, sukurta"
+	setTxHashSQL     = "UPDATE kripto_sąskaitos SET operacija = $2 WHERE id = $1"
+
+	selectInvoiceSQL     = "SELECT s.id, s.fk_mokejimas, s.turtas, s.adresas, s.suma, s.mokėtina_suma, s.valiuta, s.operacija, s.galioja_iki, s.sukurta FROM kripto_sąskaitos s"
+	getByPaymentSQL      = selectInvoiceSQL + " WHERE s.fk_mokejimas = $1"
+	getPendingInvoiceSQL = selectInvoiceSQL + " INNER JOIN mokėjimai m ON (m.id = s.fk_mokejimas) WHERE m.būsena = $1 ORDER BY s.id ASC"
+)
+
+type InvoicePgRepo struct {
+	conn *sql.DB
+}
+
+func NewInvoicePgRepo(c *sql.DB) *InvoicePgRepo {
+	return &InvoicePgRepo{c}
+}
+
+func (p *InvoicePgRepo) Insert(ctx context.Context, inv *domain.CryptoInvoice) error {
+	err := p.conn.QueryRowContext(
+		ctx,
+		insertInvoiceSQL,
+
+		inv.PaymentID,
+		inv.Asset,
+		inv.Address,
+		inv.Amount,
+		inv.FiatAmount.Amount,
+		inv.FiatAmount.Currency,
+		inv.ExpiresAt,
+	).Scan(&inv.ID, &inv.CreatedAt)
+
+	return pgsql.ParsePgError(err)
+}
+
+func scanInvoice(row pgsql.Row) (*domain.CryptoInvoice, error) {
+	inv := &domain.CryptoInvoice{}
+
+	err := row.Scan(
+		&inv.ID,
+		&inv.PaymentID,
+		&inv.Asset,
+		&inv.Address,
+		&inv.Amount,
+		&inv.FiatAmount.Amount,
+		&inv.FiatAmount.Currency,
+		&inv.TxHash,
+		&inv.ExpiresAt,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	return inv, nil
+}
+
+func (p *InvoicePgRepo) GetByPayment(ctx context.Context, paymentID int) (*domain.CryptoInvoice, error) {
+	return scanInvoice(p.conn.QueryRowContext(ctx, getByPaymentSQL, paymentID))
+}
+
+func (p *InvoicePgRepo) GetPending(ctx context.Context) ([]*domain.CryptoInvoice, error) {
+	rows, err := p.conn.QueryContext(ctx, getPendingInvoiceSQL, domain.PendingPaymentStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	var invs []*domain.CryptoInvoice
+
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		invs = append(invs, inv)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return invs, nil
+}
+
+func (p *InvoicePgRepo) SetTxHash(ctx context.Context, id int, hash string) error {
+	_, err := p.conn.ExecContext(ctx, setTxHashSQL, id, hash)
+	return err
+}
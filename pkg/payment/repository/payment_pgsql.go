@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
+)
+
+const (
+	insertSQL       = "INSERT INTO mokėjimai (suma, valiuta, būsena, fk_vartotojas, tiekėjas, raktas) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, sukurta"
+	setReferenceSQL = "UPDATE mokėjimai SET nuoroda = $2 WHERE id = $1"
+	setStatusSQL    = "UPDATE mokėjimai SET būsena = $2 WHERE id = $1"
+	hasRefundsSQL   = "SELECT EXISTS(SELECT 1 FROM grąžinimai WHERE fk_mokejimas = $1)"
+
+	selectSQL             = "SELECT id, fk_vartotojas, suma, valiuta, būsena, tiekėjas, nuoroda, raktas, sukurta FROM mokėjimai"
+	getByKeySQL           = selectSQL + " WHERE fk_vartotojas = $1 AND raktas = $2"
+	getSQL                = selectSQL + " WHERE id = $1 AND tiekėjas IS NOT NULL"
+	getForUpdateSQL       = getSQL + " FOR UPDATE"
+	getByReferenceSQL     = selectSQL + " WHERE tiekėjas = $1 AND nuoroda = $2"
+	getByReferenceLockSQL = getByReferenceSQL + " FOR UPDATE"
+)
+
+type PgRepo struct {
+	conn *sql.DB
+}
+
+func NewPgRepo(c *sql.DB) *PgRepo {
+	return &PgRepo{c}
+}
+
+func (p *PgRepo) NewTx(ctx context.Context) (repository.Transaction, error) {
+	return p.conn.BeginTx(ctx, nil)
+}
+
+func (p *PgRepo) Insert(ctx context.Context, pm *domain.Payment) error {
+	err := p.conn.QueryRowContext(
+		ctx,
+		insertSQL,
+
+		pm.Amount.Amount,
+		pm.Amount.Currency,
+		pm.Status,
+		pm.UserID,
+		pm.Provider,
+		pm.IdempotencyKey,
+	).Scan(&pm.ID, &pm.CreatedAt)
+
+	return pgsql.ParsePgError(err)
+}
+
+func scanPayment(row pgsql.Row) (*domain.Payment, error) {
+	pm := &domain.Payment{}
+
+	err := row.Scan(
+		&pm.ID,
+		&pm.UserID,
+		&pm.Amount.Amount,
+		&pm.Amount.Currency,
+		&pm.Status,
+		&pm.Provider,
+		&pm.Reference,
+		&pm.IdempotencyKey,
+		&pm.CreatedAt,
+	)
+	if err != nil {
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	return pm, nil
+}
+
+func (p *PgRepo) GetByKey(ctx context.Context, uid int, key string) (*domain.Payment, error) {
+	return scanPayment(p.conn.QueryRowContext(ctx, getByKeySQL, uid, key))
+}
+
+func (p *PgRepo) SetReference(ctx context.Context, id int, ref string) error {
+	_, err := p.conn.ExecContext(ctx, setReferenceSQL, id, ref)
+	return pgsql.ParsePgError(err)
+}
+
+func (p *PgRepo) Get(ctx context.Context, id int) (*domain.Payment, error) {
+	return scanPayment(p.conn.QueryRowContext(ctx, getSQL, id))
+}
+
+func (p *PgRepo) GetTx(ctx context.Context, tx repository.Transaction, id int) (*domain.Payment, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	pm, err := scanPayment(sqlTx.QueryRowContext(ctx, getForUpdateSQL, id))
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	return pm, nil
+}
+
+func (p *PgRepo) GetByReference(ctx context.Context, provider, ref string) (*domain.Payment, error) {
+	return scanPayment(p.conn.QueryRowContext(ctx, getByReferenceSQL, provider, ref))
+}
+
+func (p *PgRepo) GetByReferenceTx(ctx context.Context, tx repository.Transaction, provider, ref string) (*domain.Payment, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	pm, err := scanPayment(sqlTx.QueryRowContext(ctx, getByReferenceLockSQL, provider, ref))
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	return pm, nil
+}
+
+func (p *PgRepo) setStatus(ctx context.Context, q pgsql.Querier, id int, status domain.PaymentStatus) error {
+	_, err := q.ExecContext(ctx, setStatusSQL, id, status)
+	return err
+}
+
+func (p *PgRepo) SetStatus(ctx context.Context, id int, status domain.PaymentStatus) error {
+	return p.setStatus(ctx, p.conn, id, status)
+}
+
+func (p *PgRepo) SetStatusTx(ctx context.Context, tx repository.Transaction, id int, status domain.PaymentStatus) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := p.setStatus(ctx, sqlTx, id, status)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (p *PgRepo) hasRefunds(ctx context.Context, q pgsql.Querier, id int) (bool, error) {
+	var exists bool
+
+	err := q.QueryRowContext(ctx, hasRefundsSQL, id).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (p *PgRepo) HasRefunds(ctx context.Context, id int) (bool, error) {
+	return p.hasRefunds(ctx, p.conn, id)
+}
+
+func (p *PgRepo) HasRefundsTx(ctx context.Context, tx repository.Transaction, id int) (bool, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return false, repository.ErrTxMismatch
+	}
+
+	exists, err := p.hasRefunds(ctx, sqlTx, id)
+	if err != nil {
+		sqlTx.Rollback()
+		return false, err
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,15 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Usecase interface {
+	TopUp(ctx context.Context, ss *domain.Session, req *TopUpReq) (*TopUpRes, error)
+	HandleEvent(ctx context.Context, provider string, payload []byte, h http.Header) error
+	Refund(ctx context.Context, req *RefundReq) (*RefundRes, error)
+	Poll(ctx context.Context) (int, error)
+}
@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/ledger"
+	"github.com/wascript3r/autonuoma/pkg/payment"
+	"github.com/wascript3r/autonuoma/pkg/user"
+)
+
+type Usecase struct {
+	paymentRepo payment.Repository
+	userRepo    user.Repository
+	ledgerRepo  ledger.Repository
+	ctxTimeout  time.Duration
+
+	provider  payment.Provider
+	providers map[string]payment.Provider
+	validate  payment.Validate
+}
+
+// New uses p for top-ups which do not name a provider
+func New(pr payment.Repository, ur user.Repository, lr ledger.Repository, t time.Duration, v payment.Validate, p payment.Provider, ps ...payment.Provider) *Usecase {
+	providers := make(map[string]payment.Provider, len(ps)+1)
+	for _, x := range append(ps, p) {
+		providers[x.Name()] = x
+	}
+
+	return &Usecase{
+		paymentRepo: pr,
+		userRepo:    ur,
+		ledgerRepo:  lr,
+		ctxTimeout:  t,
+
+		provider:  p,
+		providers: providers,
+		validate:  v,
+	}
+}
+
+func (u *Usecase) TopUp(ctx context.Context, ss *domain.Session, req *payment.TopUpReq) (*payment.TopUpRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, payment.InvalidInputError
+	}
+
+	if !req.Amount.IsPositive() {
+		return nil, payment.InvalidInputError
+	}
+
+	p := u.provider
+	if req.Provider != "" {
+		var ok bool
+		if p, ok = u.providers[req.Provider]; !ok {
+			return nil, payment.UnknownProviderError
+		}
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	// The wallet is kept in a single currency
+	balance, err := u.userRepo.GetBalance(c, ss.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Amount.Currency != balance.Currency {
+		return nil, payment.InvalidInputError
+	}
+
+	pm, err := u.getOrInsert(c, ss.UserID, p.Name(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	if pm.Amount != req.Amount || pm.Provider != p.Name() {
+		return nil, payment.IdempotencyKeyConflictError
+	}
+
+	res := &payment.TopUpRes{
+		PaymentID: pm.ID,
+		Amount:    pm.Amount,
+		Status:    pm.Status,
+	}
+
+	// Retries of pending payments get the same intent again
+	if pm.Status != domain.PendingPaymentStatus {
+		return res, nil
+	}
+
+	in, err := p.CreateIntent(c, pm)
+	if err != nil {
+		if err == payment.ErrUnsupportedCurrency {
+			return nil, payment.InvalidInputError
+		}
+		return nil, err
+	}
+
+	if pm.Reference == nil {
+		err = u.paymentRepo.SetReference(c, pm.ID, in.Reference)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res.RedirectURL = in.RedirectURL
+	if inv := in.Invoice; inv != nil {
+		res.Invoice = &payment.InvoiceInfo{
+			Asset:     inv.Asset,
+			Address:   inv.Address,
+			Amount:    inv.AmountString(),
+			ExpiresAt: inv.ExpiresAt,
+		}
+	}
+
+	if in.Event != nil {
+		res.Status, err = u.apply(ctx, pm.Provider, in.Event)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// getOrInsert returns the payment of the idempotency key or creates a pending one
+func (u *Usecase) getOrInsert(ctx context.Context, uid int, provider string, req *payment.TopUpReq) (*domain.Payment, error) {
+	pm, err := u.paymentRepo.GetByKey(ctx, uid, req.IdempotencyKey)
+	if err != domain.ErrNotFound {
+		return pm, err
+	}
+
+	pm = &domain.Payment{
+		UserID:         uid,
+		Amount:         req.Amount,
+		Status:         domain.PendingPaymentStatus,
+		Provider:       provider,
+		IdempotencyKey: req.IdempotencyKey,
+	}
+
+	err = u.paymentRepo.Insert(ctx, pm)
+	if err != nil {
+		// A concurrent request with the same key was faster
+		if err == domain.ErrExists {
+			return u.paymentRepo.GetByKey(ctx, uid, req.IdempotencyKey)
+		}
+		return nil, err
+	}
+
+	return pm, nil
+}
+
+func (u *Usecase) HandleEvent(ctx context.Context, provider string, payload []byte, h http.Header) error {
+	p, ok := u.providers[provider]
+	if !ok {
+		return payment.UnknownProviderError
+	}
+
+	ev, err := p.ParseEvent(payload, h)
+	if err != nil {
+		if err == payment.ErrInvalidEvent {
+			return payment.InvalidEventError
+		}
+		return err
+	}
+
+	_, err = u.apply(ctx, provider, ev)
+	return err
+}
+
+// apply settles a pending payment. Only a confirmed payment credits the wallet,
+// repeated events of settled payments are ignored.
+func (u *Usecase) apply(ctx context.Context, provider string, ev *domain.PaymentEvent) (domain.PaymentStatus, error) {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.paymentRepo.NewTx(c)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	pm, err := u.paymentRepo.GetByReferenceTx(c, tx, provider, ev.Reference)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return 0, payment.PaymentNotFoundError
+		}
+		return 0, err
+	}
+
+	if pm.Status != domain.PendingPaymentStatus {
+		return pm.Status, tx.Rollback()
+	}
+
+	var status domain.PaymentStatus
+
+	switch ev.Type {
+	case domain.PaymentSucceededEvent:
+		if ev.Amount != pm.Amount {
+			return 0, payment.InvalidEventError
+		}
+		status = domain.SuccessfulPaymentStatus
+
+		err = u.ledgerRepo.PostTx(c, tx, domain.NewTopUpEntry(pm.UserID, pm.ID, pm.Amount))
+		if err != nil {
+			return 0, err
+		}
+
+	case domain.PaymentFailedEvent:
+		status = domain.RejectedPaymentStatus
+
+	default:
+		return 0, payment.InvalidEventError
+	}
+
+	err = u.paymentRepo.SetStatusTx(c, tx, pm.ID, status)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return status, nil
+}
+
+func (u *Usecase) Refund(ctx context.Context, req *payment.RefundReq) (*payment.RefundRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, payment.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.paymentRepo.NewTx(c)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	pm, err := u.paymentRepo.GetTx(c, tx, req.PaymentID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, payment.PaymentNotFoundError
+		}
+		return nil, err
+	}
+
+	if pm.Status != domain.SuccessfulPaymentStatus {
+		return nil, payment.PaymentNotRefundableError
+	}
+
+	p, ok := u.providers[pm.Provider]
+	if !ok {
+		return nil, payment.UnknownProviderError
+	}
+
+	// Providers refund the whole payment, so it would be refunded twice.
+	// Refunds by support lock the payment too, so they cannot race this check.
+	refunded, err := u.paymentRepo.HasRefundsTx(c, tx, pm.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if refunded {
+		return nil, payment.PaymentPartlyRefundedError
+	}
+
+	balance, err := u.userRepo.GetBalanceTx(c, tx, pm.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	less, err := balance.LessThan(pm.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if less {
+		return nil, payment.InsufficientBalanceError
+	}
+
+	err = u.paymentRepo.SetStatusTx(c, tx, pm.ID, domain.RefundedPaymentStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.ledgerRepo.PostTx(c, tx, domain.NewPaymentRefundEntry(pm.UserID, pm.ID, pm.Amount))
+	if err != nil {
+		return nil, err
+	}
+
+	// The provider is called last so that a failed refund rolls everything back
+	err = p.Refund(c, pm)
+	if err != nil {
+		if err == payment.ErrRefundNotSupported {
+			return nil, payment.RefundNotSupportedError
+		}
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &payment.RefundRes{
+		PaymentID: pm.ID,
+		Amount:    pm.Amount,
+		Status:    domain.RefundedPaymentStatus,
+	}, nil
+}
+
+// Poll settles the payments of providers which do not send webhooks.
+// It returns the number of applied events.
+func (u *Usecase) Poll(ctx context.Context) (int, error) {
+	n := 0
+
+	for name, p := range u.providers {
+		poller, ok := p.(payment.Poller)
+		if !ok {
+			continue
+		}
+
+		c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+		evs, err := poller.Poll(c)
+		cancel()
+		if err != nil {
+			return n, err
+		}
+
+		for _, ev := range evs {
+			if _, err := u.apply(ctx, name, ev); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+
+	return n, nil
+}
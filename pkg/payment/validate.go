@@ -0,0 +1,5 @@
+package payment
+
+type Validate interface {
+	RawRequest(s interface{}) error
+}
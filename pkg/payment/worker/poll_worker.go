@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/payment"
+	"github.com/wascript3r/autonuoma/pkg/periodic"
+	"github.com/wascript3r/cryptopay/pkg/logger"
+)
+
+// NewPollWorker periodically settles payments of providers which
+// cannot notify about them, e.g. crypto invoices.
+func NewPollWorker(pu payment.Usecase, log logger.Usecase, interval time.Duration) *periodic.Worker {
+	return periodic.NewWorker(
+		pu.Poll,
+		log,
+		interval,
+		"Cannot poll payment providers",
+		"Settled %d polled payment(s)",
+	)
+}
@@ -0,0 +1,63 @@
+package periodic
+
+import (
+	"context"
+	"time"
+
+	"github.com/wascript3r/cryptopay/pkg/logger"
+	"github.com/wascript3r/gopool"
+)
+
+// Job processes a batch of work and returns the number of processed items
+type Job func(ctx context.Context) (int, error)
+
+// Worker runs a job every interval. Errors are logged with errMsg and
+// processed items with doneMsg, which gets the number as its only argument.
+type Worker struct {
+	job Job
+	log logger.Usecase
+
+	interval time.Duration
+	errMsg   string
+	doneMsg  string
+}
+
+func NewWorker(job Job, log logger.Usecase, interval time.Duration, errMsg, doneMsg string) *Worker {
+	return &Worker{
+		job: job,
+		log: log,
+
+		interval: interval,
+		errMsg:   errMsg,
+		doneMsg:  doneMsg,
+	}
+}
+
+// Start schedules the worker on the given pool. The worker stops when ctx is done.
+func (w *Worker) Start(ctx context.Context, pool *gopool.Pool) error {
+	return pool.Schedule(func() {
+		w.run(ctx)
+	})
+}
+
+func (w *Worker) run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			// Items processed before an error are reported too
+			n, err := w.job(ctx)
+			if err != nil {
+				w.log.Error(w.errMsg+": %s", err)
+			}
+			if n > 0 {
+				w.log.Info(w.doneMsg, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/wascript3r/autonuoma/pkg/promo"
+	"github.com/wascript3r/autonuoma/pkg/session"
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+	httpjson "github.com/wascript3r/httputil/json"
+	"github.com/wascript3r/httputil/middleware"
+)
+
+type HTTPHandler struct {
+	promoUcase   promo.Usecase
+	sessionUcase session.Usecase
+}
+
+func NewHTTPHandler(ctx context.Context, r *httprouter.Router, admin *middleware.StackCtx, pu promo.Usecase, su session.Usecase) {
+	handler := &HTTPHandler{
+		promoUcase:   pu,
+		sessionUcase: su,
+	}
+
+	r.POST("/api/promo/create", admin.Wrap(ctx, handler.Create))
+	r.GET("/api/promo/list", admin.Wrap(ctx, handler.GetAll))
+	r.POST("/api/promo/deactivate", admin.Wrap(ctx, handler.Deactivate))
+}
+
+func serveError(w http.ResponseWriter, err error) {
+	if err == promo.InvalidInputError {
+		httpjson.BadRequestCustom(w, promo.InvalidInputError, nil)
+		return
+	}
+
+	code := errcode.UnwrapErr(err, promo.UnknownError)
+	if code == promo.UnknownError {
+		httpjson.InternalErrorCustom(w, code, nil)
+		return
+	}
+
+	httpjson.ServeErr(w, code, nil)
+}
+
+func (h *HTTPHandler) Create(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+	req := &promo.CreateReq{}
+
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.promoUcase.Create(r.Context(), s, req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
+
+func (h *HTTPHandler) GetAll(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	res, err := h.promoUcase.GetAll(r.Context())
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
+
+func (h *HTTPHandler) Deactivate(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := &promo.DeactivateReq{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	err = h.promoUcase.Deactivate(r.Context(), req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, nil)
+}
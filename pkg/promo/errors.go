@@ -0,0 +1,24 @@
+package promo
+
+import (
+	"errors"
+
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+)
+
+var (
+	// Error codes
+
+	InvalidInputError = errcode.InvalidInputError
+	UnknownError      = errcode.UnknownError
+
+	PromotionNotFoundError = errcode.New(
+		"promotion_not_found",
+		errors.New("promotion not found"),
+	)
+
+	CodeAlreadyExistsError = errcode.New(
+		"promo_code_already_exists",
+		errors.New("promo code already exists"),
+	)
+)
@@ -0,0 +1,54 @@
+package promo
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+// Create
+
+type CreateReq struct {
+	Code           string              `json:"code" validate:"required,alphanum,min=3,max=32"`
+	Type           domain.DiscountType `json:"type" validate:"required,oneof=fixed percent"`
+	Amount         domain.Money        `json:"amount"`
+	Percent        int                 `json:"percent" validate:"required_if=Type percent,omitempty,min=1,max=100"`
+	ValidFrom      *time.Time          `json:"validFrom"`
+	ValidUntil     *time.Time          `json:"validUntil"`
+	MaxUses        *int                `json:"maxUses" validate:"omitempty,min=1"`
+	MaxUsesPerUser *int                `json:"maxUsesPerUser" validate:"omitempty,min=1"`
+	Makes          []string            `json:"makes" validate:"max=50,dive,required,max=255"`
+	Fuels          []domain.FuelType   `json:"fuels" validate:"max=3,dive,min=1,max=3"`
+}
+
+type CreateRes struct {
+	PromotionID int `json:"promotionID"`
+}
+
+// GetAll
+
+type PromotionInfo struct {
+	ID             int                 `json:"id"`
+	Code           string              `json:"code"`
+	Type           domain.DiscountType `json:"type"`
+	Amount         *domain.Money       `json:"amount"`
+	Percent        *int                `json:"percent"`
+	ValidFrom      time.Time           `json:"validFrom"`
+	ValidUntil     *time.Time          `json:"validUntil"`
+	MaxUses        *int                `json:"maxUses"`
+	MaxUsesPerUser *int                `json:"maxUsesPerUser"`
+	Makes          []string            `json:"makes"`
+	Fuels          []domain.FuelType   `json:"fuels"`
+	Active         bool                `json:"active"`
+	Created        time.Time           `json:"created"`
+}
+
+type GetAllRes struct {
+	Promotions []*PromotionInfo `json:"promotions"`
+}
+
+// Deactivate
+
+type DeactivateReq struct {
+	PromotionID int `json:"promotionID" validate:"required"`
+}
@@ -0,0 +1,21 @@
+package promo
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+)
+
+type Repository interface {
+	Insert(ctx context.Context, p *domain.Promotion) error
+	GetAll(ctx context.Context) ([]*domain.Promotion, error)
+	Deactivate(ctx context.Context, promoID int) error
+
+	// GetByCodeTx locks the promotion so that concurrent redemptions are counted one by one
+	GetByCodeTx(ctx context.Context, tx repository.Transaction, code string) (*domain.Promotion, error)
+	GetUsageTx(ctx context.Context, tx repository.Transaction, promoID, userID int) (*domain.PromotionUsage, error)
+	GetCarTx(ctx context.Context, tx repository.Transaction, carID int) (*domain.Car, error)
+
+	GetByReservationTx(ctx context.Context, tx repository.Transaction, reservationID int) (*domain.Promotion, error)
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
+)
+
+const (
+	promotionFieldsSQL = "a.id, a.kodas, a.tipas, a.suma, a.valiuta, a.procentai, a.galioja_nuo, a.galioja_iki, a.limitas, a.limitas_vartotojui, a.markės, a.kuro_tipai, a.aktyvi, a.sukurta, a.fk_darbuotojas"
+
+	insertSQL     = "INSERT INTO akcijos (kodas, tipas, suma, valiuta, procentai, galioja_nuo, galioja_iki, limitas, limitas_vartotojui, markės, kuro_tipai, aktyvi, sukurta, fk_darbuotojas) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id"
+	getAllSQL     = "SELECT " + promotionFieldsSQL + " FROM akcijos a ORDER BY a.id DESC"
+	deactivateSQL = "UPDATE akcijos SET aktyvi = false WHERE id = $1"
+
+	getByCodeForUpdateSQL = "SELECT " + promotionFieldsSQL + " FROM akcijos a WHERE a.kodas = $1 FOR UPDATE"
+	getByReservationSQL   = "SELECT " + promotionFieldsSQL + " FROM akcijos a INNER JOIN rezervacijos r ON (r.fk_akcija = a.id) WHERE r.id = $1"
+
+	// Cancelled and expired reservations give the redemption back
+	getUsageSQL = "SELECT COUNT(*), COUNT(*) FILTER (WHERE fk_vartotojas = $2) FROM rezervacijos WHERE fk_akcija = $1 AND būsena NOT IN ($3, $4)"
+	getCarSQL   = "SELECT id, markė, kuro_tipas, valiuta FROM automobiliai WHERE id = $1"
+)
+
+type PgRepo struct {
+	conn *sql.DB
+}
+
+func NewPgRepo(c *sql.DB) *PgRepo {
+	return &PgRepo{c}
+}
+
+func scanPromotion(row pgsql.Row) (*domain.Promotion, error) {
+	var (
+		amount   *int64
+		currency *domain.Currency
+		percent  *int
+		fuels    pq.Int64Array
+	)
+	p := &domain.Promotion{}
+
+	err := row.Scan(
+		&p.ID,
+		&p.Code,
+		&p.Type,
+		&amount,
+		&currency,
+		&percent,
+		&p.ValidFrom,
+		&p.ValidUntil,
+		&p.MaxUses,
+		&p.MaxUsesPerUser,
+		(*pq.StringArray)(&p.Makes),
+		&fuels,
+		&p.Active,
+		&p.Created,
+		&p.CreatorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if amount != nil && currency != nil {
+		p.Amount = domain.NewMoney(*amount, *currency)
+	}
+	if percent != nil {
+		p.Percent = *percent
+	}
+
+	if len(fuels) > 0 {
+		p.Fuels = make([]domain.FuelType, len(fuels))
+		for i, f := range fuels {
+			p.Fuels[i] = domain.FuelType(f)
+		}
+	}
+
+	return p, nil
+}
+
+func (p *PgRepo) Insert(ctx context.Context, pr *domain.Promotion) error {
+	var (
+		amount   *int64
+		currency *domain.Currency
+		percent  *int
+	)
+
+	if pr.Type == domain.FixedDiscount {
+		amount, currency = &pr.Amount.Amount, &pr.Amount.Currency
+	} else {
+		percent = &pr.Percent
+	}
+
+	makes := pq.StringArray(pr.Makes)
+	if makes == nil {
+		makes = pq.StringArray{}
+	}
+
+	fuels := make(pq.Int64Array, len(pr.Fuels))
+	for i, f := range pr.Fuels {
+		fuels[i] = int64(f)
+	}
+
+	err := p.conn.QueryRowContext(
+		ctx,
+		insertSQL,
+
+		pr.Code,
+		pr.Type,
+		amount,
+		currency,
+		percent,
+		pr.ValidFrom,
+		pr.ValidUntil,
+		pr.MaxUses,
+		pr.MaxUsesPerUser,
+		makes,
+		fuels,
+		pr.Active,
+		pr.Created,
+		pr.CreatorID,
+	).Scan(&pr.ID)
+
+	return pgsql.ParsePgError(err)
+}
+
+func (p *PgRepo) GetAll(ctx context.Context) ([]*domain.Promotion, error) {
+	rows, err := p.conn.QueryContext(ctx, getAllSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	var ps []*domain.Promotion
+
+	for rows.Next() {
+		pr, err := scanPromotion(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		ps = append(ps, pr)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+func (p *PgRepo) Deactivate(ctx context.Context, promoID int) error {
+	res, err := p.conn.ExecContext(ctx, deactivateSQL, promoID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *PgRepo) getTx(ctx context.Context, tx repository.Transaction, query string, arg interface{}) (*domain.Promotion, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	pr, err := scanPromotion(sqlTx.QueryRowContext(ctx, query, arg))
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	return pr, nil
+}
+
+func (p *PgRepo) GetByCodeTx(ctx context.Context, tx repository.Transaction, code string) (*domain.Promotion, error) {
+	return p.getTx(ctx, tx, getByCodeForUpdateSQL, code)
+}
+
+func (p *PgRepo) GetByReservationTx(ctx context.Context, tx repository.Transaction, reservationID int) (*domain.Promotion, error) {
+	return p.getTx(ctx, tx, getByReservationSQL, reservationID)
+}
+
+func (p *PgRepo) GetUsageTx(ctx context.Context, tx repository.Transaction, promoID, userID int) (*domain.PromotionUsage, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	u := &domain.PromotionUsage{}
+
+	err := sqlTx.QueryRowContext(
+		ctx,
+		getUsageSQL,
+
+		promoID,
+		userID,
+		domain.CancelledReservationStatus,
+		domain.ExpiredReservationStatus,
+	).Scan(&u.Total, &u.User)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func (p *PgRepo) GetCarTx(ctx context.Context, tx repository.Transaction, carID int) (*domain.Car, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	var (
+		carMake *string
+		fuel    *domain.FuelType
+	)
+	c := &domain.Car{}
+
+	err := sqlTx.QueryRowContext(ctx, getCarSQL, carID).Scan(&c.ID, &carMake, &fuel, &c.MinutePrice.Currency)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	if carMake != nil {
+		c.Make = *carMake
+	}
+	if fuel != nil {
+		c.Fuel = *fuel
+	}
+
+	return c, nil
+}
@@ -0,0 +1,13 @@
+package promo
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Usecase interface {
+	Create(ctx context.Context, ss *domain.Session, req *CreateReq) (*CreateRes, error)
+	GetAll(ctx context.Context) (*GetAllRes, error)
+	Deactivate(ctx context.Context, req *DeactivateReq) error
+}
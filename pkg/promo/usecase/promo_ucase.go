@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/promo"
+)
+
+type Usecase struct {
+	promoRepo  promo.Repository
+	ctxTimeout time.Duration
+
+	validate promo.Validate
+}
+
+func New(pr promo.Repository, t time.Duration, v promo.Validate) *Usecase {
+	return &Usecase{
+		promoRepo:  pr,
+		ctxTimeout: t,
+
+		validate: v,
+	}
+}
+
+func (u *Usecase) Create(ctx context.Context, ss *domain.Session, req *promo.CreateReq) (*promo.CreateRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, promo.InvalidInputError
+	}
+
+	p := &domain.Promotion{
+		Code:           domain.NormalizePromoCode(req.Code),
+		Type:           req.Type,
+		ValidFrom:      time.Now(),
+		ValidUntil:     req.ValidUntil,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+		Makes:          req.Makes,
+		Fuels:          req.Fuels,
+		Active:         true,
+		Created:        time.Now(),
+		CreatorID:      ss.UserID,
+	}
+
+	switch req.Type {
+	case domain.FixedDiscount:
+		if !req.Amount.IsPositive() || req.Percent != 0 {
+			return nil, promo.InvalidInputError
+		}
+		p.Amount = req.Amount
+	case domain.PercentDiscount:
+		if !req.Amount.IsZero() {
+			return nil, promo.InvalidInputError
+		}
+		p.Percent = req.Percent
+	}
+
+	if req.ValidFrom != nil {
+		p.ValidFrom = *req.ValidFrom
+	}
+
+	if p.ValidUntil != nil && !p.ValidFrom.Before(*p.ValidUntil) {
+		return nil, promo.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	err := u.promoRepo.Insert(c, p)
+	if err != nil {
+		if err == domain.ErrExists {
+			return nil, promo.CodeAlreadyExistsError
+		}
+		return nil, err
+	}
+
+	return &promo.CreateRes{PromotionID: p.ID}, nil
+}
+
+func toPromotionInfo(p *domain.Promotion) *promo.PromotionInfo {
+	info := &promo.PromotionInfo{
+		ID:             p.ID,
+		Code:           p.Code,
+		Type:           p.Type,
+		ValidFrom:      p.ValidFrom,
+		ValidUntil:     p.ValidUntil,
+		MaxUses:        p.MaxUses,
+		MaxUsesPerUser: p.MaxUsesPerUser,
+		Makes:          p.Makes,
+		Fuels:          p.Fuels,
+		Active:         p.Active,
+		Created:        p.Created,
+	}
+
+	if p.Type == domain.FixedDiscount {
+		amount := p.Amount
+		info.Amount = &amount
+	} else {
+		percent := p.Percent
+		info.Percent = &percent
+	}
+
+	if info.Makes == nil {
+		info.Makes = []string{}
+	}
+	if info.Fuels == nil {
+		info.Fuels = []domain.FuelType{}
+	}
+
+	return info
+}
+
+func (u *Usecase) GetAll(ctx context.Context) (*promo.GetAllRes, error) {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	ps, err := u.promoRepo.GetAll(c)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*promo.PromotionInfo, len(ps))
+	for i, p := range ps {
+		infos[i] = toPromotionInfo(p)
+	}
+
+	return &promo.GetAllRes{Promotions: infos}, nil
+}
+
+func (u *Usecase) Deactivate(ctx context.Context, req *promo.DeactivateReq) error {
+	if err := u.validate.RawRequest(req); err != nil {
+		return promo.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	err := u.promoRepo.Deactivate(c, req.PromotionID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return promo.PromotionNotFoundError
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,5 @@
+package promo
+
+type Validate interface {
+	RawRequest(s interface{}) error
+}
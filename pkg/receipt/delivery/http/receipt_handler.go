@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/wascript3r/autonuoma/pkg/receipt"
+	"github.com/wascript3r/autonuoma/pkg/session"
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+	httpjson "github.com/wascript3r/httputil/json"
+	"github.com/wascript3r/httputil/middleware"
+)
+
+type HTTPHandler struct {
+	receiptUcase receipt.Usecase
+	sessionUcase session.Usecase
+}
+
+func NewHTTPHandler(ctx context.Context, r *httprouter.Router, auth *middleware.StackCtx, ru receipt.Usecase, su session.Usecase) {
+	handler := &HTTPHandler{
+		receiptUcase: ru,
+		sessionUcase: su,
+	}
+
+	r.GET("/api/receipt/:tripID", auth.Wrap(ctx, handler.Get))
+}
+
+func serveError(w http.ResponseWriter, err error) {
+	if err == receipt.InvalidInputError {
+		httpjson.BadRequestCustom(w, receipt.InvalidInputError, nil)
+		return
+	}
+
+	code := errcode.UnwrapErr(err, receipt.UnknownError)
+	if code == receipt.UnknownError {
+		httpjson.InternalErrorCustom(w, code, nil)
+		return
+	}
+
+	httpjson.ServeErr(w, code, nil)
+}
+
+// Get serves the receipt as a download, ?format=html or ?format=pdf (default)
+func (h *HTTPHandler) Get(ctx context.Context, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+
+	id, err := strconv.Atoi(ps.ByName("tripID"))
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = receipt.PDFFormat
+	}
+
+	doc, err := h.receiptUcase.Get(r.Context(), s, id, format)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.Name}))
+	w.Header().Set("Content-Length", strconv.Itoa(len(doc.Body)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(doc.Body)
+}
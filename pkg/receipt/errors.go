@@ -0,0 +1,29 @@
+package receipt
+
+import (
+	"errors"
+
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+)
+
+var (
+	// Error codes
+
+	InvalidInputError = errcode.InvalidInputError
+	UnknownError      = errcode.UnknownError
+
+	ReceiptNotFoundError = errcode.New(
+		"receipt_not_found",
+		errors.New("receipt not found"),
+	)
+
+	ReceiptNotOwnedError = errcode.New(
+		"receipt_not_owned",
+		errors.New("receipt is not owned by this user"),
+	)
+
+	UnknownFormatError = errcode.New(
+		"unknown_receipt_format",
+		errors.New("unknown receipt format"),
+	)
+)
@@ -0,0 +1,14 @@
+package receipt
+
+const (
+	HTMLFormat = "html"
+	PDFFormat  = "pdf"
+)
+
+// Get
+
+type Document struct {
+	Name        string
+	ContentType string
+	Body        []byte
+}
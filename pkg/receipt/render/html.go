@@ -0,0 +1,68 @@
+package render
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/receipt"
+)
+
+var htmlTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Receipt {{.Code}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; max-width: 640px; margin: 2em auto; color: #222; }
+h1 { font-size: 1.4em; margin-bottom: 0; }
+h2 { font-size: 1.1em; border-bottom: 1px solid #ccc; padding-bottom: .2em; }
+table { width: 100%; border-collapse: collapse; }
+td { padding: .2em 0; vertical-align: top; }
+td:last-child { text-align: right; }
+.issuer { color: #555; font-size: .9em; }
+</style>
+</head>
+<body>
+<h1>Receipt {{.Code}}</h1>
+<p>Issued {{.Issued}}</p>
+<p class="issuer">{{with .Issuer}}{{.Name}}<br>{{.Address}}<br>Company code {{.CompanyCode}}{{if .VATCode}}, VAT code {{.VATCode}}{{end}}{{end}}</p>
+{{range .Sections}}<h2>{{.Title}}</h2>
+<table>
+{{range .Rows}}<tr><td>{{.Label}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+type htmlData struct {
+	Code     string
+	Issued   string
+	Issuer   domain.ReceiptIssuer
+	Sections []section
+}
+
+type HTML struct {
+	issuer domain.ReceiptIssuer
+}
+
+func NewHTML(issuer domain.ReceiptIssuer) *HTML {
+	return &HTML{issuer}
+}
+
+func (h *HTML) Format() string {
+	return receipt.HTMLFormat
+}
+
+func (h *HTML) ContentType() string {
+	return "text/html; charset=utf-8"
+}
+
+func (h *HTML) Render(w io.Writer, r *domain.Receipt) error {
+	return htmlTemplate.Execute(w, &htmlData{
+		Code:     r.Code(),
+		Issued:   r.Issued.Format(dateTimeFormat),
+		Issuer:   h.issuer,
+		Sections: sections(r),
+	})
+}
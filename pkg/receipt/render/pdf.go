@@ -0,0 +1,170 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/receipt"
+)
+
+// A4 in points
+const (
+	pageWidth  = 595
+	pageHeight = 842
+
+	marginLeft = 50
+	valueLeft  = 300
+	maxValue   = 48 // runes which fit between valueLeft and the right margin
+)
+
+// Characters of the standard fonts outside ASCII and Latin-1
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '–': 0x96, '—': 0x97,
+	'Š': 0x8a, 'š': 0x9a, 'Ž': 0x8e, 'ž': 0x9e, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+}
+
+// Lithuanian letters missing from WinAnsiEncoding lose their diacritics
+var fallback = map[rune]byte{
+	'Ą': 'A', 'ą': 'a', 'Č': 'C', 'č': 'c', 'Ę': 'E', 'ę': 'e', 'Ė': 'E', 'ė': 'e',
+	'Į': 'I', 'į': 'i', 'Ų': 'U', 'ų': 'u', 'Ū': 'U', 'ū': 'u',
+}
+
+// pdfString encodes s as a WinAnsi PDF literal string
+func pdfString(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+
+	for _, r := range s {
+		var c byte
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			c = byte(r)
+		case r < 0x80 || (r >= 0xa0 && r <= 0xff):
+			c = byte(r)
+		default:
+			var ok bool
+			if c, ok = winAnsi[r]; !ok {
+				if c, ok = fallback[r]; !ok {
+					c = '?'
+				}
+			}
+		}
+
+		if c < 0x20 || c >= 0x80 {
+			fmt.Fprintf(&b, "\\%03o", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+
+	b.WriteByte(')')
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	rs := []rune(s)
+	if len(rs) <= n {
+		return s
+	}
+	return string(rs[:n-1]) + "…"
+}
+
+// PDF renders single page receipts using the standard Helvetica fonts,
+// so no fonts have to be embedded.
+type PDF struct {
+	issuer domain.ReceiptIssuer
+}
+
+func NewPDF(issuer domain.ReceiptIssuer) *PDF {
+	return &PDF{issuer}
+}
+
+func (p *PDF) Format() string {
+	return receipt.PDFFormat
+}
+
+func (p *PDF) ContentType() string {
+	return "application/pdf"
+}
+
+type textWriter struct {
+	buf bytes.Buffer
+	y   int
+}
+
+func (t *textWriter) text(font string, size, x int, s string) {
+	fmt.Fprintf(&t.buf, "BT /%s %d Tf %d %d Td %s Tj ET\n", font, size, x, t.y, pdfString(s))
+}
+
+func (t *textWriter) line(font string, size int, s string) {
+	t.text(font, size, marginLeft, s)
+	t.y -= size + 6
+}
+
+func (p *PDF) content(r *domain.Receipt) []byte {
+	t := &textWriter{y: pageHeight - 60}
+
+	t.line("F2", 18, "Receipt "+r.Code())
+	t.line("F1", 10, "Issued "+r.Issued.Format(dateTimeFormat))
+	t.y -= 6
+
+	t.line("F1", 9, p.issuer.Name)
+	t.line("F1", 9, p.issuer.Address)
+	codes := "Company code " + p.issuer.CompanyCode
+	if p.issuer.VATCode != "" {
+		codes += ", VAT code " + p.issuer.VATCode
+	}
+	t.line("F1", 9, codes)
+
+	for _, s := range sections(r) {
+		t.y -= 12
+		t.line("F2", 12, s.Title)
+		fmt.Fprintf(&t.buf, "%d %d m %d %d l S\n", marginLeft, t.y+14, pageWidth-marginLeft, t.y+14)
+
+		for _, rw := range s.Rows {
+			t.text("F1", 10, valueLeft, truncate(rw.Value, maxValue))
+			t.line("F1", 10, rw.Label)
+		}
+	}
+
+	return t.buf.Bytes()
+}
+
+func (p *PDF) Render(w io.Writer, r *domain.Receipt) error {
+	content := p.content(r)
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pageWidth, pageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+		fmt.Sprintf("<< /Title %s /Producer (autonuoma) >>", pdfString("Receipt "+r.Code())),
+	}
+
+	var (
+		buf     bytes.Buffer
+		offsets = make([]int, len(objects))
+	)
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	for i, o := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,97 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+const dateTimeFormat = "2006-01-02 15:04"
+
+// row is a labelled line shared by all receipt formats
+type row struct {
+	Label string
+	Value string
+}
+
+type section struct {
+	Title string
+	Rows  []row
+}
+
+func paymentStatus(s domain.PaymentStatus) string {
+	switch s {
+	case domain.SuccessfulPaymentStatus:
+		return "Paid"
+	case domain.PendingPaymentStatus:
+		return "Pending"
+	case domain.RejectedPaymentStatus:
+		return "Rejected"
+	case domain.RefundedPaymentStatus:
+		return "Refunded"
+	}
+	return ""
+}
+
+func money(m domain.Money) string {
+	return m.String() + " " + string(m.Currency)
+}
+
+func sections(r *domain.Receipt) []section {
+	trip := section{
+		Title: "Trip",
+		Rows: []row{
+			{"Trip", fmt.Sprintf("#%d", r.TripID)},
+			{"Client", strings.TrimSpace(r.Client.FirstName + " " + r.Client.LastName)},
+			{"Car", strings.TrimSpace(r.CarMake + " " + r.CarModel)},
+			{"Licence plate", r.CarPlate},
+			{"Start", r.Begin.Format(dateTimeFormat) + ", " + r.From},
+			{"End", r.End.Format(dateTimeFormat) + ", " + r.To},
+			{"Duration", domain.FormatDuration(r.Duration())},
+			{"Distance", fmt.Sprintf("%.2f km", r.Distance)},
+		},
+	}
+
+	timeLabel := "Time"
+	if len(r.Caps) > 0 {
+		caps := make([]string, len(r.Caps))
+		for i, c := range r.Caps {
+			caps[i] = string(c)
+		}
+		timeLabel += " (" + strings.Join(caps, ", ") + " price cap)"
+	}
+
+	charges := section{
+		Title: "Charges",
+		Rows: []row{
+			{timeLabel, money(r.TimePrice)},
+			{"Distance", money(r.DistancePrice)},
+		},
+	}
+	if r.Discount.IsPositive() {
+		label := "Discount"
+		if r.PromoCode != nil {
+			label += " (" + *r.PromoCode + ")"
+		}
+		charges.Rows = append(charges.Rows, row{label, money(r.Discount.Neg())})
+	}
+	charges.Rows = append(
+		charges.Rows,
+		row{"Total", money(r.Total)},
+		row{"Net amount", money(r.Net())},
+		row{fmt.Sprintf("VAT %d%%", r.VATRate), money(r.VAT())},
+	)
+
+	payment := section{
+		Title: "Payment",
+		Rows: []row{
+			{"Status", paymentStatus(r.PaymentStatus)},
+		},
+	}
+	if r.Balance != nil {
+		payment.Rows = append(payment.Rows, row{"Remaining balance", money(*r.Balance)})
+	}
+
+	return []section{trip, charges, payment}
+}
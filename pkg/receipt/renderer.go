@@ -0,0 +1,13 @@
+package receipt
+
+import (
+	"io"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Renderer interface {
+	Format() string
+	ContentType() string
+	Render(w io.Writer, r *domain.Receipt) error
+}
@@ -0,0 +1,16 @@
+package receipt
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+)
+
+type Repository interface {
+	// Issue assigns the next receipt number to an ended trip
+	Issue(ctx context.Context, tripID, vatRate int) (int, error)
+	IssueTx(ctx context.Context, tx repository.Transaction, tripID, vatRate int) (int, error)
+
+	GetByTrip(ctx context.Context, tripID int) (*domain.Receipt, error)
+}
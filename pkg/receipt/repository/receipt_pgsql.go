@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
+)
+
+const (
+	// The single counter row is locked until the transaction ends, so numbers have no gaps
+	issueSQL = "WITH n AS (UPDATE kvitų_numeracija SET paskutinis = paskutinis + 1 RETURNING paskutinis) INSERT INTO kvitai (numeris, išrašyta, pvm_tarifas, fk_kelione) SELECT paskutinis, now(), $2, $1 FROM n RETURNING numeris"
+
+	// Balance is the wallet balance right after the trip charge was posted
+	getByTripSQL = "SELECT kv.numeris, kv.išrašyta, kv.pvm_tarifas, k.id, v.id, v.vardas, v.pavardė, k.pradžios_laikas, k.pabaigos_laikas, r.pradzios_adresas, r.pabaigos_adresas, k.pradžios_taško_platuma, k.pradžios_taško_ilguma, k.pabaigos_taško_platuma, k.pabaigos_taško_ilguma, COALESCE(k.atstumas, 0), COALESCE(a.markė, ''), COALESCE(a.modelis, ''), COALESCE(a.valstybiniai_numeriai, ''), COALESCE(k.laiko_kaina, k.kaina), COALESCE(k.atstumo_kaina, 0), k.nuolaida, k.kaina, k.valiuta, COALESCE(k.taikyti_limitai, ''), ak.kodas, COALESCE(k.apmokėjimo_būsena, $2), (SELECT e.likutis FROM žurnalo_eilutės e INNER JOIN žurnalo_įrašai j ON (j.id = e.fk_irasas) WHERE j.fk_kelione = k.id AND j.tipas = $3 AND e.sąskaita = $4 ORDER BY e.id DESC LIMIT 1), v.valiuta FROM kvitai kv INNER JOIN kelionės k ON (k.id = kv.fk_kelione) INNER JOIN rezervacijos r ON (r.id = k.fk_rezervacija) INNER JOIN vartotojai v ON (v.id = r.fk_vartotojas) INNER JOIN automobiliai a ON (a.id = r.fk_automobilis) LEFT JOIN akcijos ak ON (ak.id = k.fk_akcija) WHERE kv.fk_kelione = $1"
+
+	capsSeparator = ","
+)
+
+type PgRepo struct {
+	conn *sql.DB
+}
+
+func NewPgRepo(c *sql.DB) *PgRepo {
+	return &PgRepo{c}
+}
+
+func (p *PgRepo) issue(ctx context.Context, q pgsql.Querier, tripID, vatRate int) (int, error) {
+	var n int
+
+	err := q.QueryRowContext(ctx, issueSQL, tripID, vatRate).Scan(&n)
+	if err != nil {
+		return 0, pgsql.ParsePgError(err)
+	}
+
+	return n, nil
+}
+
+func (p *PgRepo) Issue(ctx context.Context, tripID, vatRate int) (int, error) {
+	return p.issue(ctx, p.conn, tripID, vatRate)
+}
+
+func (p *PgRepo) IssueTx(ctx context.Context, tx repository.Transaction, tripID, vatRate int) (int, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return 0, repository.ErrTxMismatch
+	}
+
+	n, err := p.issue(ctx, sqlTx, tripID, vatRate)
+	if err != nil {
+		sqlTx.Rollback()
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func decodeCaps(s string) []domain.PriceCap {
+	if s == "" {
+		return nil
+	}
+
+	parts := strings.Split(s, capsSeparator)
+	caps := make([]domain.PriceCap, len(parts))
+	for i, c := range parts {
+		caps[i] = domain.PriceCap(c)
+	}
+	return caps
+}
+
+func toPoint(lat, lng *float64) *domain.Point {
+	if lat == nil || lng == nil {
+		return nil
+	}
+	return &domain.Point{Lat: *lat, Lng: *lng}
+}
+
+func (p *PgRepo) GetByTrip(ctx context.Context, tripID int) (*domain.Receipt, error) {
+	var (
+		fromAddr, toAddr               *string
+		fromLat, fromLng, toLat, toLng *float64
+		caps                           string
+		balance                        *int64
+		balanceCurrency                domain.Currency
+	)
+
+	r := &domain.Receipt{
+		Client: &domain.UserMeta{},
+	}
+
+	err := p.conn.QueryRowContext(
+		ctx,
+		getByTripSQL,
+
+		tripID,
+		domain.PendingPaymentStatus,
+		domain.TripChargeJournalEntry,
+		domain.WalletLedgerAccount,
+	).Scan(
+		&r.Number,
+		&r.Issued,
+		&r.VATRate,
+		&r.TripID,
+		&r.Client.ID,
+		&r.Client.FirstName,
+		&r.Client.LastName,
+		&r.Begin,
+		&r.End,
+
+		&fromAddr,
+		&toAddr,
+		&fromLat,
+		&fromLng,
+		&toLat,
+		&toLng,
+		&r.Distance,
+
+		&r.CarMake,
+		&r.CarModel,
+		&r.CarPlate,
+
+		&r.TimePrice.Amount,
+		&r.DistancePrice.Amount,
+		&r.Discount.Amount,
+		&r.Total.Amount,
+		&r.Total.Currency,
+		&caps,
+		&r.PromoCode,
+
+		&r.PaymentStatus,
+		&balance,
+		&balanceCurrency,
+	)
+	if err != nil {
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	r.UserID = r.Client.ID
+	r.From = domain.Address(fromAddr, toPoint(fromLat, fromLng))
+	r.To = domain.Address(toAddr, toPoint(toLat, toLng))
+	r.Caps = decodeCaps(caps)
+
+	r.TimePrice.Currency = r.Total.Currency
+	r.DistancePrice.Currency = r.Total.Currency
+	r.Discount.Currency = r.Total.Currency
+
+	if balance != nil {
+		b := domain.NewMoney(*balance, balanceCurrency)
+		r.Balance = &b
+	}
+
+	return r, nil
+}
@@ -0,0 +1,11 @@
+package receipt
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Usecase interface {
+	Get(ctx context.Context, ss *domain.Session, tripID int, format string) (*Document, error)
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/receipt"
+)
+
+type Usecase struct {
+	receiptRepo receipt.Repository
+	ctxTimeout  time.Duration
+
+	renderers map[string]receipt.Renderer
+}
+
+func New(rr receipt.Repository, t time.Duration, rs ...receipt.Renderer) *Usecase {
+	renderers := make(map[string]receipt.Renderer, len(rs))
+	for _, r := range rs {
+		renderers[r.Format()] = r
+	}
+
+	return &Usecase{
+		receiptRepo: rr,
+		ctxTimeout:  t,
+
+		renderers: renderers,
+	}
+}
+
+// Get renders the receipt of a trip. Clients get only their own receipts, staff members any.
+func (u *Usecase) Get(ctx context.Context, ss *domain.Session, tripID int, format string) (*receipt.Document, error) {
+	renderer, ok := u.renderers[format]
+	if !ok {
+		return nil, receipt.UnknownFormatError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	r, err := u.receiptRepo.GetByTrip(c, tripID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, receipt.ReceiptNotFoundError
+		}
+		return nil, err
+	}
+
+	if r.UserID != ss.UserID && !domain.IsStaff(ss) {
+		return nil, receipt.ReceiptNotOwnedError
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, r); err != nil {
+		return nil, err
+	}
+
+	return &receipt.Document{
+		Name:        r.Code() + "." + format,
+		ContentType: renderer.ContentType(),
+		Body:        buf.Bytes(),
+	}, nil
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/wascript3r/autonuoma/pkg/refund"
+	"github.com/wascript3r/autonuoma/pkg/session"
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+	httpjson "github.com/wascript3r/httputil/json"
+	"github.com/wascript3r/httputil/middleware"
+)
+
+type HTTPHandler struct {
+	refundUcase  refund.Usecase
+	sessionUcase session.Usecase
+}
+
+func NewHTTPHandler(ctx context.Context, r *httprouter.Router, staff *middleware.StackCtx, ru refund.Usecase, su session.Usecase) {
+	handler := &HTTPHandler{
+		refundUcase:  ru,
+		sessionUcase: su,
+	}
+
+	r.POST("/api/agent/refund", staff.Wrap(ctx, handler.Create))
+}
+
+func serveError(w http.ResponseWriter, err error) {
+	if err == refund.InvalidInputError {
+		httpjson.BadRequestCustom(w, refund.InvalidInputError, nil)
+		return
+	}
+
+	code := errcode.UnwrapErr(err, refund.UnknownError)
+	if code == refund.UnknownError {
+		httpjson.InternalErrorCustom(w, code, nil)
+		return
+	}
+
+	httpjson.ServeErr(w, code, nil)
+}
+
+func (h *HTTPHandler) Create(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+	req := &refund.CreateReq{}
+
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.refundUcase.Create(r.Context(), s, req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
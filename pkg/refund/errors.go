@@ -0,0 +1,49 @@
+package refund
+
+import (
+	"errors"
+
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+)
+
+var (
+	// Error codes
+
+	InvalidInputError = errcode.InvalidInputError
+	UnknownError      = errcode.UnknownError
+
+	TicketNotFoundError = errcode.New(
+		"ticket_not_found",
+		errors.New("ticket not found"),
+	)
+
+	TicketNotOwnedError = errcode.New(
+		"ticket_not_owned",
+		errors.New("ticket is not assigned to the agent"),
+	)
+
+	TripNotFoundError = errcode.New(
+		"trip_not_found",
+		errors.New("trip not found"),
+	)
+
+	PaymentNotFoundError = errcode.New(
+		"payment_not_found",
+		errors.New("payment not found"),
+	)
+
+	TargetNotOwnedError = errcode.New(
+		"refund_target_not_owned",
+		errors.New("trip or payment does not belong to the ticket client"),
+	)
+
+	TargetNotSettledError = errcode.New(
+		"refund_target_not_settled",
+		errors.New("only paid trips and successful payments can be refunded"),
+	)
+
+	RefundExceedsChargeError = errcode.New(
+		"refund_exceeds_charge",
+		errors.New("refunds cannot exceed the original charge"),
+	)
+)
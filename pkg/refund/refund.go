@@ -0,0 +1,36 @@
+package refund
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/user"
+)
+
+// Create
+
+type CreateReq struct {
+	TicketID  int          `json:"ticketID" validate:"required"`
+	TripID    *int         `json:"tripID" validate:"required_without=PaymentID,excluded_with=PaymentID"`
+	PaymentID *int         `json:"paymentID" validate:"required_without=TripID,excluded_with=TripID"`
+	Amount    domain.Money `json:"amount"`
+	Reason    string       `json:"reason" validate:"required,max=500"`
+}
+
+type CreateRes struct {
+	RefundID   int          `json:"refundID"`
+	Amount     domain.Money `json:"amount"`
+	Refundable domain.Money `json:"refundable"`
+}
+
+// GetByTicket
+
+type RefundInfo struct {
+	ID        int            `json:"id"`
+	Issuer    *user.UserInfo `json:"issuer"`
+	TripID    *int           `json:"tripID"`
+	PaymentID *int           `json:"paymentID"`
+	Amount    domain.Money   `json:"amount"`
+	Reason    string         `json:"reason"`
+	Time      time.Time      `json:"time"`
+}
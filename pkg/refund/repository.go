@@ -0,0 +1,27 @@
+package refund
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+)
+
+type Repository interface {
+	NewTx(ctx context.Context) (repository.Transaction, error)
+
+	GetTicketMeta(ctx context.Context, ticketID int) (*domain.TicketMeta, error)
+	GetTicketMetaTx(ctx context.Context, tx repository.Transaction, ticketID int) (*domain.TicketMeta, error)
+
+	// The Tx variants lock the trip or payment until the refund is inserted
+	GetTripTarget(ctx context.Context, tripID int) (*domain.RefundTarget, error)
+	GetTripTargetTx(ctx context.Context, tx repository.Transaction, tripID int) (*domain.RefundTarget, error)
+
+	GetPaymentTarget(ctx context.Context, paymentID int) (*domain.RefundTarget, error)
+	GetPaymentTargetTx(ctx context.Context, tx repository.Transaction, paymentID int) (*domain.RefundTarget, error)
+
+	Insert(ctx context.Context, r *domain.Refund) error
+	InsertTx(ctx context.Context, tx repository.Transaction, r *domain.Refund) error
+
+	GetByTicket(ctx context.Context, ticketID int) ([]*domain.Refund, error)
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
+)
+
+const (
+	insertSQL      = "INSERT INTO grąžinimai (suma, valiuta, priežastis, sukurta, fk_uzklausa, fk_vartotojas, fk_darbuotojas, fk_kelione, fk_mokejimas) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
+	getByTicketSQL = "SELECT g.id, g.fk_vartotojas, g.fk_kelione, g.fk_mokejimas, g.suma, g.valiuta, g.priežastis, g.sukurta, v.id, v.vardas, v.pavardė FROM grąžinimai g INNER JOIN vartotojai v ON (v.id = g.fk_darbuotojas) WHERE g.fk_uzklausa = $1 ORDER BY g.id ASC"
+
+	getTicketMetaSQL = "SELECT fk_klientas, fk_klientų_aptarnavimo_specialistas, užbaigta FROM užklausos WHERE id = $1"
+
+	getTripTargetSQL          = "SELECT r.fk_vartotojas, COALESCE(k.kaina, 0), k.valiuta, COALESCE(k.apmokėjimo_būsena = $2, false), (SELECT COALESCE(SUM(g.suma), 0) FROM grąžinimai g WHERE g.fk_kelione = k.id) FROM kelionės k INNER JOIN rezervacijos r ON (r.id = k.fk_rezervacija) WHERE k.id = $1"
+	getTripTargetForUpdateSQL = getTripTargetSQL + " FOR UPDATE OF k"
+
+	// Only top-ups, trip payments are refunded through their trip
+	getPaymentTargetSQL          = "SELECT m.fk_vartotojas, m.suma, m.valiuta, m.būsena = $2, (SELECT COALESCE(SUM(g.suma), 0) FROM grąžinimai g WHERE g.fk_mokejimas = m.id) FROM mokėjimai m WHERE m.id = $1 AND m.fk_kelione IS NULL"
+	getPaymentTargetForUpdateSQL = getPaymentTargetSQL + " FOR UPDATE OF m"
+)
+
+type PgRepo struct {
+	conn *sql.DB
+}
+
+func NewPgRepo(c *sql.DB) *PgRepo {
+	return &PgRepo{c}
+}
+
+func (p *PgRepo) NewTx(ctx context.Context) (repository.Transaction, error) {
+	return p.conn.BeginTx(ctx, nil)
+}
+
+func (p *PgRepo) getTicketMeta(ctx context.Context, q pgsql.Querier, ticketID int) (*domain.TicketMeta, error) {
+	m := &domain.TicketMeta{}
+
+	err := q.QueryRowContext(ctx, getTicketMetaSQL, ticketID).Scan(&m.ClientID, &m.AgentID, &m.Ended)
+	if err != nil {
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	return m, nil
+}
+
+func (p *PgRepo) GetTicketMeta(ctx context.Context, ticketID int) (*domain.TicketMeta, error) {
+	return p.getTicketMeta(ctx, p.conn, ticketID)
+}
+
+func (p *PgRepo) GetTicketMetaTx(ctx context.Context, tx repository.Transaction, ticketID int) (*domain.TicketMeta, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	m, err := p.getTicketMeta(ctx, sqlTx, ticketID)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (p *PgRepo) getTarget(ctx context.Context, q pgsql.Querier, query string, id int) (*domain.RefundTarget, error) {
+	t := &domain.RefundTarget{}
+
+	err := q.QueryRowContext(ctx, query, id, domain.SuccessfulPaymentStatus).Scan(
+		&t.UserID,
+		&t.Charge.Amount,
+		&t.Charge.Currency,
+		&t.Settled,
+		&t.Refunded.Amount,
+	)
+	if err != nil {
+		return nil, pgsql.ParseSQLError(err)
+	}
+	t.Refunded.Currency = t.Charge.Currency
+
+	return t, nil
+}
+
+func (p *PgRepo) getTargetTx(ctx context.Context, tx repository.Transaction, query string, id int) (*domain.RefundTarget, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	t, err := p.getTarget(ctx, sqlTx, query, id)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (p *PgRepo) GetTripTarget(ctx context.Context, tripID int) (*domain.RefundTarget, error) {
+	return p.getTarget(ctx, p.conn, getTripTargetSQL, tripID)
+}
+
+func (p *PgRepo) GetTripTargetTx(ctx context.Context, tx repository.Transaction, tripID int) (*domain.RefundTarget, error) {
+	return p.getTargetTx(ctx, tx, getTripTargetForUpdateSQL, tripID)
+}
+
+func (p *PgRepo) GetPaymentTarget(ctx context.Context, paymentID int) (*domain.RefundTarget, error) {
+	return p.getTarget(ctx, p.conn, getPaymentTargetSQL, paymentID)
+}
+
+func (p *PgRepo) GetPaymentTargetTx(ctx context.Context, tx repository.Transaction, paymentID int) (*domain.RefundTarget, error) {
+	return p.getTargetTx(ctx, tx, getPaymentTargetForUpdateSQL, paymentID)
+}
+
+func (p *PgRepo) insert(ctx context.Context, q pgsql.Querier, r *domain.Refund) error {
+	err := q.QueryRowContext(
+		ctx,
+		insertSQL,
+
+		r.Amount.Amount,
+		r.Amount.Currency,
+		r.Reason,
+		r.Created,
+		r.TicketID,
+		r.UserID,
+		r.IssuerID,
+		r.TripID,
+		r.PaymentID,
+	).Scan(&r.ID)
+
+	return pgsql.ParsePgError(err)
+}
+
+func (p *PgRepo) Insert(ctx context.Context, r *domain.Refund) error {
+	return p.insert(ctx, p.conn, r)
+}
+
+func (p *PgRepo) InsertTx(ctx context.Context, tx repository.Transaction, r *domain.Refund) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := p.insert(ctx, sqlTx, r)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (p *PgRepo) GetByTicket(ctx context.Context, ticketID int) ([]*domain.Refund, error) {
+	rows, err := p.conn.QueryContext(ctx, getByTicketSQL, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rs []*domain.Refund
+
+	for rows.Next() {
+		r := &domain.Refund{
+			TicketID:   ticketID,
+			IssuerMeta: &domain.UserMeta{},
+		}
+
+		err := rows.Scan(
+			&r.ID,
+			&r.UserID,
+			&r.TripID,
+			&r.PaymentID,
+			&r.Amount.Amount,
+			&r.Amount.Currency,
+			&r.Reason,
+			&r.Created,
+			&r.IssuerMeta.ID,
+			&r.IssuerMeta.FirstName,
+			&r.IssuerMeta.LastName,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		r.IssuerID = r.IssuerMeta.ID
+
+		rs = append(rs, r)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
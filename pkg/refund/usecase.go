@@ -0,0 +1,11 @@
+package refund
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Usecase interface {
+	Create(ctx context.Context, ss *domain.Session, req *CreateReq) (*CreateRes, error)
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/ledger"
+	"github.com/wascript3r/autonuoma/pkg/refund"
+)
+
+type Usecase struct {
+	refundRepo refund.Repository
+	ledgerRepo ledger.Repository
+	ctxTimeout time.Duration
+
+	validate refund.Validate
+}
+
+func New(rr refund.Repository, lr ledger.Repository, t time.Duration, v refund.Validate) *Usecase {
+	return &Usecase{
+		refundRepo: rr,
+		ledgerRepo: lr,
+		ctxTimeout: t,
+
+		validate: v,
+	}
+}
+
+func (u *Usecase) Create(ctx context.Context, ss *domain.Session, req *refund.CreateReq) (*refund.CreateRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, refund.InvalidInputError
+	}
+
+	if !req.Amount.IsPositive() {
+		return nil, refund.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.refundRepo.NewTx(c)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	meta, err := u.refundRepo.GetTicketMetaTx(c, tx, req.TicketID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, refund.TicketNotFoundError
+		}
+		return nil, err
+	}
+
+	// Agents compensate only on tickets assigned to them, admins on any
+	if ss.RoleID == domain.AgentRole && (meta.AgentID == nil || *meta.AgentID != ss.UserID) {
+		return nil, refund.TicketNotOwnedError
+	}
+
+	var target *domain.RefundTarget
+	if req.TripID != nil {
+		target, err = u.refundRepo.GetTripTargetTx(c, tx, *req.TripID)
+		if err == domain.ErrNotFound {
+			return nil, refund.TripNotFoundError
+		}
+	} else {
+		target, err = u.refundRepo.GetPaymentTargetTx(c, tx, *req.PaymentID)
+		if err == domain.ErrNotFound {
+			return nil, refund.PaymentNotFoundError
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if target.UserID != meta.ClientID {
+		return nil, refund.TargetNotOwnedError
+	}
+
+	if !target.Settled {
+		return nil, refund.TargetNotSettledError
+	}
+
+	refundable, err := target.Refundable()
+	if err != nil {
+		return nil, err
+	}
+
+	left, err := refundable.Sub(req.Amount)
+	if err == domain.ErrCurrencyMismatch || left.IsNegative() {
+		return nil, refund.RefundExceedsChargeError
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := &domain.Refund{
+		TicketID:  req.TicketID,
+		UserID:    meta.ClientID,
+		IssuerID:  ss.UserID,
+		TripID:    req.TripID,
+		PaymentID: req.PaymentID,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+		Created:   time.Now(),
+	}
+
+	err = u.refundRepo.InsertTx(c, tx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	e := domain.NewRefundEntry(r.UserID, r.Amount, r.Reason)
+	e.TripID = r.TripID
+	e.PaymentID = r.PaymentID
+
+	err = u.ledgerRepo.PostTx(c, tx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &refund.CreateRes{
+		RefundID:   r.ID,
+		Amount:     r.Amount,
+		Refundable: left,
+	}, nil
+}
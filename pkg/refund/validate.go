@@ -0,0 +1,5 @@
+package refund
+
+type Validate interface {
+	RawRequest(s interface{}) error
+}
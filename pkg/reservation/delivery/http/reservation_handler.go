@@ -8,8 +8,6 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/wascript3r/autonuoma/pkg/reservation"
 	"github.com/wascript3r/autonuoma/pkg/session"
-	sessionHandler "github.com/wascript3r/autonuoma/pkg/session/delivery/http"
-	"github.com/wascript3r/autonuoma/pkg/user"
 	"github.com/wascript3r/cryptopay/pkg/errcode"
 	httpjson "github.com/wascript3r/httputil/json"
 	"github.com/wascript3r/httputil/middleware"
@@ -18,29 +16,30 @@ import (
 type HTTPHandler struct {
 	resUcase     reservation.Usecase
 	sessionUcase session.Usecase
-	sessionMid   sessionHandler.Middleware
 }
 
-func NewHTTPHandler(ctx context.Context, r *httprouter.Router, su session.Usecase, sm sessionHandler.Middleware, auth *middleware.StackCtx, ru reservation.Usecase) {
+func NewHTTPHandler(ctx context.Context, r *httprouter.Router, su session.Usecase, client *middleware.StackCtx, staff *middleware.StackCtx, ru reservation.Usecase) {
 	handler := &HTTPHandler{
 		resUcase:     ru,
 		sessionUcase: su,
-		sessionMid:   sm,
 	}
 
-	r.POST("/api/reservation/create", auth.Wrap(ctx, handler.Create))
-	r.POST("/api/reservation/cancel", auth.Wrap(ctx, handler.Cancel))
-	r.GET("/api/reservation", auth.Wrap(ctx, handler.GetCurrent))
+	r.POST("/api/reservation/create", client.Wrap(ctx, handler.Create))
+	r.POST("/api/reservation/activate", client.Wrap(ctx, handler.Activate))
+	r.POST("/api/reservation/cancel", client.Wrap(ctx, handler.Cancel))
+	r.GET("/api/reservation", client.Wrap(ctx, handler.GetCurrent))
+
+	r.POST("/api/agent/reservation/cancel", staff.Wrap(ctx, handler.Cancel))
 }
 
 func serveError(w http.ResponseWriter, err error) {
-	if err == user.InvalidInputError {
-		httpjson.BadRequestCustom(w, user.InvalidInputError, nil)
+	if err == reservation.InvalidInputError {
+		httpjson.BadRequestCustom(w, reservation.InvalidInputError, nil)
 		return
 	}
 
-	code := errcode.UnwrapErr(err, user.UnknownError)
-	if code == user.UnknownError {
+	code := errcode.UnwrapErr(err, reservation.UnknownError)
+	if code == reservation.UnknownError {
 		httpjson.InternalErrorCustom(w, code, nil)
 		return
 	}
@@ -62,25 +61,53 @@ func (h *HTTPHandler) Create(ctx context.Context, w http.ResponseWriter, r *http
 		return
 	}
 
-	id, err := h.resUcase.Create(r.Context(), req, s.UserID)
+	res, err := h.resUcase.Create(r.Context(), s, req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
+
+func (h *HTTPHandler) Activate(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+	req := &reservation.ActivateReq{}
+
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	err = h.resUcase.Activate(r.Context(), s, req)
 	if err != nil {
 		serveError(w, err)
 		return
 	}
 
-	httpjson.ServeJSON(w, id)
+	httpjson.ServeJSON(w, nil)
 }
 
-func (h *HTTPHandler) Cancel(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+func (h *HTTPHandler) Cancel(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
 	req := &reservation.CancelReq{}
 
-	err := json.NewDecoder(r.Body).Decode(req)
+	err = json.NewDecoder(r.Body).Decode(req)
 	if err != nil {
 		httpjson.BadRequest(w, nil)
 		return
 	}
 
-	err = h.resUcase.Cancel(r.Context(), req.ReservationID)
+	err = h.resUcase.Cancel(r.Context(), s, req)
 	if err != nil {
 		serveError(w, err)
 		return
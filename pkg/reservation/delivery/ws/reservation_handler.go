@@ -0,0 +1,54 @@
+package ws
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/reservation"
+	"github.com/wascript3r/autonuoma/pkg/room"
+	sessionHandler "github.com/wascript3r/autonuoma/pkg/session/delivery/ws"
+	"github.com/wascript3r/gows"
+	"github.com/wascript3r/gows/pool"
+	"github.com/wascript3r/gows/router"
+)
+
+type WSHandler struct {
+	sessionMid sessionHandler.Middleware
+	roomUcase  room.Usecase
+
+	socketPool *pool.Pool
+}
+
+func NewWSHandler(reb reservation.EventBus, sm sessionHandler.Middleware, ru room.Usecase, socketPool *pool.Pool) {
+	handler := &WSHandler{
+		sessionMid: sm,
+		roomUcase:  ru,
+
+		socketPool: socketPool,
+	}
+
+	reb.Subscribe(reservation.ExpiredReservationEvent, handler.UserNotification("reservation/notification/expired", domain.ExpiredCancelReason))
+}
+
+// UserNotification notifies every authenticated socket of the reservation owner.
+func (w *WSHandler) UserNotification(method string, reason domain.CancelReason) func(context.Context, *domain.Reservation) {
+	return func(_ context.Context, r *domain.Reservation) {
+		rName, err := w.roomUcase.GetName(domain.AuthenticatedRoom)
+		if err != nil {
+			return
+		}
+
+		w.socketPool.EmitRoomFilter(pool.RoomName(rName), &router.Response{
+			Error:  nil,
+			Method: &method,
+			Data: &reservation.NotificationRes{
+				ReservationID: r.ID,
+				CarID:         r.CarID,
+				Reason:        string(reason),
+			},
+		}, func(s *gows.Socket) bool {
+			ss, ok := w.sessionMid.ExtractSession(s)
+			return ok && ss.UserID == r.UserID
+		})
+	}
+}
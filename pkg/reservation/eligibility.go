@@ -0,0 +1,15 @@
+package reservation
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type EligibilityRule interface {
+	Check(e *domain.Eligibility, now time.Time) error
+}
+
+type EligibilityPolicy interface {
+	Check(e *domain.Eligibility, now time.Time) error
+}
@@ -0,0 +1,26 @@
+package eligibility
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/reservation"
+)
+
+// Policy runs the rules in order and returns the first failure
+type Policy struct {
+	rules []reservation.EligibilityRule
+}
+
+func New(rules ...reservation.EligibilityRule) *Policy {
+	return &Policy{rules}
+}
+
+func (p *Policy) Check(e *domain.Eligibility, now time.Time) error {
+	for _, r := range p.rules {
+		if err := r.Check(e, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
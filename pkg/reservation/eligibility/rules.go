@@ -0,0 +1,73 @@
+package eligibility
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/reservation"
+)
+
+type RuleFunc func(e *domain.Eligibility, now time.Time) error
+
+func (f RuleFunc) Check(e *domain.Eligibility, now time.Time) error {
+	return f(e, now)
+}
+
+// EmailVerifiedRule requires the account email address to be verified
+func EmailVerifiedRule() RuleFunc {
+	return func(e *domain.Eligibility, _ time.Time) error {
+		if !e.EmailVerified {
+			return reservation.EmailNotVerifiedError
+		}
+		return nil
+	}
+}
+
+// LicenseRule requires a confirmed, unexpired driving licence
+func LicenseRule() RuleFunc {
+	return func(e *domain.Eligibility, now time.Time) error {
+		if e.LicenseStatus == nil {
+			return reservation.LicenseMissingError
+		}
+		if *e.LicenseStatus != domain.ConfirmedLicenseStatus {
+			return reservation.LicenseNotConfirmedError
+		}
+		if e.LicenseExpired(now) {
+			return reservation.LicenseExpiredError
+		}
+		return nil
+	}
+}
+
+func MinAgeRule(age int) RuleFunc {
+	return func(e *domain.Eligibility, now time.Time) error {
+		if e.Age(now) < age {
+			return reservation.AgeRequirementError
+		}
+		return nil
+	}
+}
+
+// MinBalanceRule requires the balance to be at least min
+func MinBalanceRule(min domain.Money) RuleFunc {
+	return func(e *domain.Eligibility, now time.Time) error {
+		less, err := e.Balance.LessThan(min)
+		if err != nil {
+			return err
+		}
+		if less {
+			return reservation.InsufficientBalanceError
+		}
+		return nil
+	}
+}
+
+// CarAgeRule enforces the minimum age set on the car itself
+func CarAgeRule() RuleFunc {
+	return func(e *domain.Eligibility, now time.Time) error {
+		if e.Age(now) < e.CarMinAge {
+			return reservation.CarAgeRestrictedError
+		}
+		return nil
+	}
+}
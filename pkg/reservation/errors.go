@@ -0,0 +1,99 @@
+package reservation
+
+import (
+	"errors"
+
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+)
+
+var (
+	// Error codes
+
+	InvalidInputError = errcode.InvalidInputError
+	UnknownError      = errcode.UnknownError
+
+	ReservationNotFoundError = errcode.New(
+		"reservation_not_found",
+		errors.New("reservation not found"),
+	)
+
+	ReservationNotOwnedError = errcode.New(
+		"reservation_not_owned",
+		errors.New("reservation is not owned by this user"),
+	)
+
+	CarNotFoundError = errcode.New(
+		"car_not_found",
+		errors.New("car not found"),
+	)
+
+	CarAlreadyReservedError = errcode.New(
+		"car_already_reserved",
+		errors.New("car is already reserved"),
+	)
+
+	ReservationAlreadyExistsError = errcode.New(
+		"reservation_already_exists",
+		errors.New("user already has a live reservation"),
+	)
+
+	InvalidStatusTransitionError = errcode.New(
+		"invalid_reservation_status",
+		errors.New("reservation cannot be moved to the requested status"),
+	)
+
+	EmailNotVerifiedError = errcode.New(
+		"email_not_verified",
+		errors.New("email address is not verified"),
+	)
+
+	LicenseMissingError = errcode.New(
+		"license_missing",
+		errors.New("driving licence is not submitted"),
+	)
+
+	LicenseNotConfirmedError = errcode.New(
+		"license_not_confirmed",
+		errors.New("driving licence is not confirmed"),
+	)
+
+	LicenseExpiredError = errcode.New(
+		"license_expired",
+		errors.New("driving licence is expired"),
+	)
+
+	AgeRequirementError = errcode.New(
+		"age_requirement_not_met",
+		errors.New("user does not meet the minimum age requirement"),
+	)
+
+	InsufficientBalanceError = errcode.New(
+		"insufficient_balance",
+		errors.New("balance is below the required minimum"),
+	)
+
+	CarAgeRestrictedError = errcode.New(
+		"car_age_restricted",
+		errors.New("user is too young to reserve this car"),
+	)
+
+	PromoNotFoundError = errcode.New(
+		"promo_not_found",
+		errors.New("promo code not found"),
+	)
+
+	PromoNotValidError = errcode.New(
+		"promo_not_valid",
+		errors.New("promo code is not valid at this time"),
+	)
+
+	PromoNotApplicableError = errcode.New(
+		"promo_not_applicable",
+		errors.New("promo code does not apply to this car"),
+	)
+
+	PromoUsageExceededError = errcode.New(
+		"promo_usage_exceeded",
+		errors.New("promo code usage limit is reached"),
+	)
+)
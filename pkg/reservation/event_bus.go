@@ -0,0 +1,39 @@
+package reservation
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Event uint32
+
+const (
+	ExpiredReservationEvent Event = iota
+	CreatedReservationEvent
+	CancelledReservationEvent
+	CompletedReservationEvent
+	InvalidEvent
+)
+
+func (e Event) String() string {
+	switch e {
+	case ExpiredReservationEvent:
+		return "ExpiredReservation"
+	case CreatedReservationEvent:
+		return "CreatedReservation"
+	case CancelledReservationEvent:
+		return "CancelledReservation"
+	case CompletedReservationEvent:
+		return "CompletedReservation"
+	default:
+		return "Invalid"
+	}
+}
+
+type EventHnd func(ctx context.Context, r *domain.Reservation)
+
+type EventBus interface {
+	Subscribe(Event, EventHnd)
+	Publish(Event, context.Context, *domain.Reservation)
+}
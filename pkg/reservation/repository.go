@@ -2,11 +2,40 @@ package reservation
 
 import (
 	"context"
+	"time"
+
 	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
 )
 
 type Repository interface {
-	Create(ctx context.Context, cardID int, userID int) (int, error)
-	Cancel(ctx context.Context, reservationID int) error
+	NewTx(ctx context.Context) (repository.Transaction, error)
+
+	LockCarTx(ctx context.Context, tx repository.Transaction, carID int) error
+	LockUserTx(ctx context.Context, tx repository.Transaction, userID int) error
+
+	Create(ctx context.Context, carID int, userID int, promoID *int) (int, error)
+	CreateTx(ctx context.Context, tx repository.Transaction, carID int, userID int, promoID *int) (int, error)
+
+	Get(ctx context.Context, reservationID int) (*domain.Reservation, error)
+	GetTx(ctx context.Context, tx repository.Transaction, reservationID int) (*domain.Reservation, error)
+
+	SetStatus(ctx context.Context, reservationID int, status domain.ReservationStatus) error
+	SetStatusTx(ctx context.Context, tx repository.Transaction, reservationID int, status domain.ReservationStatus) error
+
+	Cancel(ctx context.Context, reservationID int, status domain.ReservationStatus, reason domain.CancelReason) error
+	CancelTx(ctx context.Context, tx repository.Transaction, reservationID int, status domain.ReservationStatus, reason domain.CancelReason) error
+
+	GetOverdueTx(ctx context.Context, tx repository.Transaction, createdBefore time.Time) ([]*domain.Reservation, error)
+
+	IsCarReserved(ctx context.Context, carID int) (bool, error)
+	IsCarReservedTx(ctx context.Context, tx repository.Transaction, carID int) (bool, error)
+
+	HasReservation(ctx context.Context, userID int) (bool, error)
+	HasReservationTx(ctx context.Context, tx repository.Transaction, userID int) (bool, error)
+
+	GetEligibility(ctx context.Context, userID, carID int) (*domain.Eligibility, error)
+	GetEligibilityTx(ctx context.Context, tx repository.Transaction, userID, carID int) (*domain.Eligibility, error)
+
 	GetCurrent(ctx context.Context, userID int) (*domain.Reservation, error)
 }
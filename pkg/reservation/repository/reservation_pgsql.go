@@ -3,16 +3,37 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"time"
+
 	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
 	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
-	"time"
 )
 
 const (
-	createReservationSQL     = "INSERT INTO rezervacijos (sukurta, fk_automobilis, fk_vartotojas) VALUES ($1, $2, $3) RETURNING id"
-	cancelReservationSQL     = "UPDATE rezervacijos SET atšaukta = $2 WHERE id = $1"
-	getCurrentReservationSQl = "SELECT sukurta, id, fk_automobilis, fk_vartotojas FROM rezervacijos WHERE atšaukta is NULL AND fk_vartotojas = $1"
+	// Pending, active and in-trip reservations hold the car
+	liveStatusesSQL = "(1, 2, 3)"
+
+	lockCarSQL  = "SELECT id FROM automobiliai WHERE id = $1 AND pašalintas = false FOR UPDATE"
+	lockUserSQL = "SELECT id FROM vartotojai WHERE id = $1 FOR UPDATE"
+
+	createReservationSQL = "INSERT INTO rezervacijos (sukurta, būsena, fk_automobilis, fk_vartotojas, fk_akcija) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	setStatusSQL         = "UPDATE rezervacijos SET būsena = $2 WHERE id = $1"
+	cancelSQL            = "UPDATE rezervacijos SET būsena = $2, atšaukta = $3, atšaukimo_priežastis = $4 WHERE id = $1"
+
+	getReservationSQL          = "SELECT sukurta, id, fk_automobilis, fk_vartotojas, būsena FROM rezervacijos WHERE id = $1"
+	getReservationForUpdateSQL = getReservationSQL + " FOR UPDATE"
+
+	isCarReservedSQL  = "SELECT EXISTS(SELECT 1 FROM rezervacijos WHERE fk_automobilis = $1 AND būsena IN " + liveStatusesSQL + ")"
+	hasReservationSQL = "SELECT EXISTS(SELECT 1 FROM rezervacijos WHERE fk_vartotojas = $1 AND būsena IN " + liveStatusesSQL + ")"
+
+	// Pending and active reservations are still waiting for a trip
+	getOverdueSQL = "SELECT sukurta, id, fk_automobilis, fk_vartotojas, būsena FROM rezervacijos WHERE būsena IN (1, 2) AND sukurta < $1 ORDER BY id ASC FOR UPDATE SKIP LOCKED"
+
+	// A confirmed licence takes precedence over newer submissions that are still under review
+	getEligibilitySQL = "SELECT v.gimimo_data, v.el_paštas_patvirtintas IS NOT NULL, v.balansas, v.valiuta, p.būsena, p.galiojimo_pabaiga, a.minimalus_amžius FROM vartotojai v CROSS JOIN automobiliai a LEFT JOIN LATERAL (SELECT būsena, galiojimo_pabaiga FROM vairuotojo_pažymėjimai WHERE fk_vartotojas = v.id ORDER BY (būsena = $3) DESC, id DESC LIMIT 1) p ON true WHERE v.id = $1 AND a.id = $2"
+
+	getCurrentReservationSQL = "SELECT sukurta, id, fk_automobilis, fk_vartotojas, būsena FROM rezervacijos WHERE fk_vartotojas = $1 AND būsena IN " + liveStatusesSQL
 )
 
 type PgRepo struct {
@@ -23,31 +44,265 @@ func NewPgRepo(c *sql.DB) *PgRepo {
 	return &PgRepo{c}
 }
 
-func (p *PgRepo) Create(ctx context.Context, carID int, userID int) (int, error) {
+func (p *PgRepo) NewTx(ctx context.Context) (repository.Transaction, error) {
+	return p.conn.BeginTx(ctx, nil)
+}
+
+func (p *PgRepo) lockTx(ctx context.Context, tx repository.Transaction, query string, id int) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := sqlTx.QueryRowContext(ctx, query, id).Scan(&id)
+	if err != nil {
+		sqlTx.Rollback()
+		return pgsql.ParseSQLError(err)
+	}
+
+	return nil
+}
+
+func (p *PgRepo) LockCarTx(ctx context.Context, tx repository.Transaction, carID int) error {
+	return p.lockTx(ctx, tx, lockCarSQL, carID)
+}
+
+func (p *PgRepo) LockUserTx(ctx context.Context, tx repository.Transaction, userID int) error {
+	return p.lockTx(ctx, tx, lockUserSQL, userID)
+}
+
+func (p *PgRepo) create(ctx context.Context, q pgsql.Querier, carID int, userID int, promoID *int) (int, error) {
 	var reservationID int
-	err := p.conn.QueryRowContext(ctx, createReservationSQL, time.Now(), carID, userID).Scan(&reservationID)
+
+	err := q.QueryRowContext(ctx, createReservationSQL, time.Now(), domain.PendingReservationStatus, carID, userID, promoID).Scan(&reservationID)
 	if err != nil {
-		return 0, err
+		return 0, pgsql.ParsePgError(err)
 	}
+
 	return reservationID, nil
 }
 
-func (p *PgRepo) Cancel(ctx context.Context, reservationID int) error {
-	if err := p.conn.QueryRowContext(ctx, cancelReservationSQL, reservationID, time.Now()).Err(); err != nil {
-		return pgsql.ParseSQLError(err)
+func (p *PgRepo) Create(ctx context.Context, carID int, userID int, promoID *int) (int, error) {
+	return p.create(ctx, p.conn, carID, userID, promoID)
+}
+
+func (p *PgRepo) CreateTx(ctx context.Context, tx repository.Transaction, carID int, userID int, promoID *int) (int, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return 0, repository.ErrTxMismatch
 	}
-	return nil
+
+	id, err := p.create(ctx, sqlTx, carID, userID, promoID)
+	if err != nil {
+		sqlTx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
 }
 
-func (p *PgRepo) GetCurrent(ctx context.Context, userID int) (*domain.Reservation, error) {
+func scanReservation(row pgsql.Row) (*domain.Reservation, error) {
 	r := &domain.Reservation{}
 
-	err := p.conn.QueryRowContext(ctx, getCurrentReservationSQl, userID).Scan(&r.CreatedAt, &r.ID, &r.CarID, &r.UserID)
-	fmt.Println(err)
-	fmt.Println(userID)
+	err := row.Scan(&r.CreatedAt, &r.ID, &r.CarID, &r.UserID, &r.Status)
 	if err != nil {
 		return nil, pgsql.ParseSQLError(err)
 	}
 
 	return r, nil
 }
+
+func (p *PgRepo) get(ctx context.Context, q pgsql.Querier, reservationID int, forUpdate bool) (*domain.Reservation, error) {
+	var query string
+
+	if forUpdate {
+		query = getReservationForUpdateSQL
+	} else {
+		query = getReservationSQL
+	}
+
+	return scanReservation(q.QueryRowContext(ctx, query, reservationID))
+}
+
+func (p *PgRepo) Get(ctx context.Context, reservationID int) (*domain.Reservation, error) {
+	return p.get(ctx, p.conn, reservationID, false)
+}
+
+func (p *PgRepo) GetTx(ctx context.Context, tx repository.Transaction, reservationID int) (*domain.Reservation, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	r, err := p.get(ctx, sqlTx, reservationID, true)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (p *PgRepo) setStatus(ctx context.Context, q pgsql.Querier, reservationID int, status domain.ReservationStatus) error {
+	_, err := q.ExecContext(ctx, setStatusSQL, reservationID, status)
+	return err
+}
+
+func (p *PgRepo) SetStatus(ctx context.Context, reservationID int, status domain.ReservationStatus) error {
+	return p.setStatus(ctx, p.conn, reservationID, status)
+}
+
+func (p *PgRepo) SetStatusTx(ctx context.Context, tx repository.Transaction, reservationID int, status domain.ReservationStatus) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := p.setStatus(ctx, sqlTx, reservationID, status)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (p *PgRepo) cancel(ctx context.Context, q pgsql.Querier, reservationID int, status domain.ReservationStatus, reason domain.CancelReason) error {
+	_, err := q.ExecContext(ctx, cancelSQL, reservationID, status, time.Now(), reason)
+	return err
+}
+
+func (p *PgRepo) Cancel(ctx context.Context, reservationID int, status domain.ReservationStatus, reason domain.CancelReason) error {
+	return p.cancel(ctx, p.conn, reservationID, status, reason)
+}
+
+func (p *PgRepo) CancelTx(ctx context.Context, tx repository.Transaction, reservationID int, status domain.ReservationStatus, reason domain.CancelReason) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := p.cancel(ctx, sqlTx, reservationID, status, reason)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (p *PgRepo) GetOverdueTx(ctx context.Context, tx repository.Transaction, createdBefore time.Time) ([]*domain.Reservation, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	rows, err := sqlTx.QueryContext(ctx, getOverdueSQL, createdBefore)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	var rs []*domain.Reservation
+
+	for rows.Next() {
+		r, err := scanReservation(rows)
+		if err != nil {
+			rows.Close()
+			sqlTx.Rollback()
+			return nil, err
+		}
+		rs = append(rs, r)
+	}
+
+	if err := rows.Close(); err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+func (p *PgRepo) exists(ctx context.Context, q pgsql.Querier, query string, id int) (bool, error) {
+	var exists bool
+
+	err := q.QueryRowContext(ctx, query, id).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (p *PgRepo) existsTx(ctx context.Context, tx repository.Transaction, query string, id int) (bool, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return false, repository.ErrTxMismatch
+	}
+
+	exists, err := p.exists(ctx, sqlTx, query, id)
+	if err != nil {
+		sqlTx.Rollback()
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (p *PgRepo) IsCarReserved(ctx context.Context, carID int) (bool, error) {
+	return p.exists(ctx, p.conn, isCarReservedSQL, carID)
+}
+
+func (p *PgRepo) IsCarReservedTx(ctx context.Context, tx repository.Transaction, carID int) (bool, error) {
+	return p.existsTx(ctx, tx, isCarReservedSQL, carID)
+}
+
+func (p *PgRepo) HasReservation(ctx context.Context, userID int) (bool, error) {
+	return p.exists(ctx, p.conn, hasReservationSQL, userID)
+}
+
+func (p *PgRepo) HasReservationTx(ctx context.Context, tx repository.Transaction, userID int) (bool, error) {
+	return p.existsTx(ctx, tx, hasReservationSQL, userID)
+}
+
+func (p *PgRepo) getEligibility(ctx context.Context, q pgsql.Querier, userID, carID int) (*domain.Eligibility, error) {
+	e := &domain.Eligibility{}
+
+	err := q.QueryRowContext(ctx, getEligibilitySQL, userID, carID, domain.ConfirmedLicenseStatus).Scan(
+		&e.BirthDate,
+		&e.EmailVerified,
+		&e.Balance.Amount,
+		&e.Balance.Currency,
+		&e.LicenseStatus,
+		&e.LicenseExpiration,
+		&e.CarMinAge,
+	)
+	if err != nil {
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	return e, nil
+}
+
+func (p *PgRepo) GetEligibility(ctx context.Context, userID, carID int) (*domain.Eligibility, error) {
+	return p.getEligibility(ctx, p.conn, userID, carID)
+}
+
+func (p *PgRepo) GetEligibilityTx(ctx context.Context, tx repository.Transaction, userID, carID int) (*domain.Eligibility, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	e, err := p.getEligibility(ctx, sqlTx, userID, carID)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (p *PgRepo) GetCurrent(ctx context.Context, userID int) (*domain.Reservation, error) {
+	return scanReservation(p.conn.QueryRowContext(ctx, getCurrentReservationSQL, userID))
+}
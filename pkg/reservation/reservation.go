@@ -1,9 +1,32 @@
 package reservation
 
+// Create
+
 type CreateReq struct {
-	CarID int `json:"carID" validate:"required"`
+	CarID     int    `json:"carID" validate:"required"`
+	PromoCode string `json:"promoCode" validate:"omitempty,max=32"`
+}
+
+type CreateRes struct {
+	ReservationID int `json:"reservationID"`
 }
 
+// Activate
+
+type ActivateReq struct {
+	ReservationID int `json:"reservationID" validate:"required"`
+}
+
+// Cancel
+
 type CancelReq struct {
 	ReservationID int `json:"reservationID" validate:"required"`
 }
+
+// Notification
+
+type NotificationRes struct {
+	ReservationID int    `json:"reservationID"`
+	CarID         int    `json:"carID"`
+	Reason        string `json:"reason"`
+}
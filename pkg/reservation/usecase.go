@@ -2,11 +2,14 @@ package reservation
 
 import (
 	"context"
+
 	"github.com/wascript3r/autonuoma/pkg/domain"
 )
 
 type Usecase interface {
-	Create(ctx context.Context, req *CreateReq, uid int) (int, error)
-	Cancel(ctx context.Context, reservationId int) error
+	Create(ctx context.Context, ss *domain.Session, req *CreateReq) (*CreateRes, error)
+	Activate(ctx context.Context, ss *domain.Session, req *ActivateReq) error
+	Cancel(ctx context.Context, ss *domain.Session, req *CancelReq) error
 	GetCurrent(ctx context.Context, userID int) (*domain.Reservation, error)
+	ExpireOverdue(ctx context.Context) (int, error)
 }
@@ -2,42 +2,278 @@ package usecase
 
 import (
 	"context"
+	"time"
+
 	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/promo"
+	"github.com/wascript3r/autonuoma/pkg/repository"
 	"github.com/wascript3r/autonuoma/pkg/reservation"
 )
 
 type Usecase struct {
-	resRepo reservation.Repository
+	resRepo    reservation.Repository
+	promoRepo  promo.Repository
+	ctxTimeout time.Duration
+
+	eventBus reservation.EventBus
+	validate reservation.Validate
+	policy   reservation.EligibilityPolicy
+
+	holdWindow time.Duration
 }
 
-func New(rr reservation.Repository) *Usecase {
+func New(rr reservation.Repository, pr promo.Repository, t time.Duration, eb reservation.EventBus, v reservation.Validate, ep reservation.EligibilityPolicy, holdWindow time.Duration) *Usecase {
 	return &Usecase{
-		resRepo: rr,
+		resRepo:    rr,
+		promoRepo:  pr,
+		ctxTimeout: t,
+
+		eventBus: eb,
+		validate: v,
+		policy:   ep,
+
+		holdWindow: holdWindow,
 	}
 }
 
-func (u *Usecase) Create(ctx context.Context, req *reservation.CreateReq, uid int) (int, error) {
-	var id, err = u.resRepo.Create(ctx, req.CarID, uid)
+func (u *Usecase) Create(ctx context.Context, ss *domain.Session, req *reservation.CreateReq) (*reservation.CreateRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, reservation.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.resRepo.NewTx(c)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	// Lock both rows so concurrent reservations of the same car
+	// or by the same user are serialized
+	err = u.resRepo.LockCarTx(c, tx, req.CarID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, reservation.CarNotFoundError
+		}
+		return nil, err
+	}
+
+	err = u.resRepo.LockUserTx(c, tx, ss.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := u.resRepo.GetEligibilityTx(c, tx, ss.UserID, req.CarID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = u.policy.Check(e, time.Now()); err != nil {
+		return nil, err
+	}
+
+	exists, err := u.resRepo.HasReservationTx(c, tx, ss.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, reservation.ReservationAlreadyExistsError
+	}
+
+	reserved, err := u.resRepo.IsCarReservedTx(c, tx, req.CarID)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, reservation.CarAlreadyReservedError
+	}
+
+	var promoID *int
+	if req.PromoCode != "" {
+		p, err := u.redeem(c, tx, ss.UserID, req.CarID, req.PromoCode)
+		if err != nil {
+			return nil, err
+		}
+		promoID = &p.ID
+	}
+
+	id, err := u.resRepo.CreateTx(c, tx, req.CarID, ss.UserID, promoID)
+	if err != nil {
+		if err == domain.ErrExists {
+			return nil, reservation.CarAlreadyReservedError
+		}
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	u.eventBus.Publish(reservation.CreatedReservationEvent, ctx, &domain.Reservation{
+		ID:     id,
+		CarID:  req.CarID,
+		UserID: ss.UserID,
+		Status: domain.PendingReservationStatus,
+	})
+
+	return &reservation.CreateRes{ReservationID: id}, nil
+}
+
+// redeem checks that the promo code can be applied to the reservation of the car.
+// The promotion stays locked until the reservation is created, so the usage limits hold.
+func (u *Usecase) redeem(ctx context.Context, tx repository.Transaction, uid, carID int, code string) (*domain.Promotion, error) {
+	p, err := u.promoRepo.GetByCodeTx(ctx, tx, domain.NormalizePromoCode(code))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, reservation.PromoNotFoundError
+		}
+		return nil, err
+	}
+
+	if !p.Valid(time.Now()) {
+		return nil, reservation.PromoNotValidError
+	}
+
+	car, err := u.promoRepo.GetCarTx(ctx, tx, carID)
+	if err != nil {
+		return nil, err
 	}
 
-	return id, nil
+	if !p.AppliesTo(car) {
+		return nil, reservation.PromoNotApplicableError
+	}
+
+	usage, err := u.promoRepo.GetUsageTx(ctx, tx, p.ID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Exhausted(usage) {
+		return nil, reservation.PromoUsageExceededError
+	}
+
+	return p, nil
 }
 
-func (u *Usecase) Cancel(ctx context.Context, reservationID int) error {
-	err := u.resRepo.Cancel(ctx, reservationID)
+// transition moves the reservation to the given status. Only the owner
+// may do so unless staffOverride is set and the session belongs to an agent or an admin.
+func (u *Usecase) transition(ctx context.Context, ss *domain.Session, reservationID int, status domain.ReservationStatus, staffOverride bool) error {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.resRepo.NewTx(c)
 	if err != nil {
 		return err
 	}
+
+	defer tx.Rollback()
+
+	r, err := u.resRepo.GetTx(c, tx, reservationID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return reservation.ReservationNotFoundError
+		}
+		return err
+	}
+
+	if r.UserID != ss.UserID && !(staffOverride && domain.IsStaff(ss)) {
+		return reservation.ReservationNotOwnedError
+	}
+
+	if !r.Status.CanTransition(status) {
+		return reservation.InvalidStatusTransitionError
+	}
+
+	if status == domain.CancelledReservationStatus {
+		reason := domain.UserCancelReason
+		if r.UserID != ss.UserID {
+			reason = domain.StaffCancelReason
+		}
+		err = u.resRepo.CancelTx(c, tx, reservationID, status, reason)
+	} else {
+		err = u.resRepo.SetStatusTx(c, tx, reservationID, status)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	r.Status = status
+
+	if status == domain.CancelledReservationStatus {
+		u.eventBus.Publish(reservation.CancelledReservationEvent, ctx, r)
+	}
+
 	return nil
 }
 
+func (u *Usecase) Activate(ctx context.Context, ss *domain.Session, req *reservation.ActivateReq) error {
+	if err := u.validate.RawRequest(req); err != nil {
+		return reservation.InvalidInputError
+	}
+
+	return u.transition(ctx, ss, req.ReservationID, domain.ActiveReservationStatus, false)
+}
+
+func (u *Usecase) Cancel(ctx context.Context, ss *domain.Session, req *reservation.CancelReq) error {
+	if err := u.validate.RawRequest(req); err != nil {
+		return reservation.InvalidInputError
+	}
+
+	return u.transition(ctx, ss, req.ReservationID, domain.CancelledReservationStatus, true)
+}
+
 func (u *Usecase) GetCurrent(ctx context.Context, userID int) (*domain.Reservation, error) {
-	r := &domain.Reservation{}
-	r, err := u.resRepo.GetCurrent(ctx, userID)
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	r, err := u.resRepo.GetCurrent(c, userID)
 	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, reservation.ReservationNotFoundError
+		}
 		return nil, err
 	}
+
 	return r, nil
 }
+
+func (u *Usecase) ExpireOverdue(ctx context.Context) (int, error) {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.resRepo.NewTx(c)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	rs, err := u.resRepo.GetOverdueTx(c, tx, time.Now().Add(-u.holdWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, r := range rs {
+		err = u.resRepo.CancelTx(c, tx, r.ID, domain.ExpiredReservationStatus, domain.ExpiredCancelReason)
+		if err != nil {
+			return 0, err
+		}
+		r.Status = domain.ExpiredReservationStatus
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	for _, r := range rs {
+		u.eventBus.Publish(reservation.ExpiredReservationEvent, ctx, r)
+	}
+
+	return len(rs), nil
+}
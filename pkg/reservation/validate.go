@@ -0,0 +1,5 @@
+package reservation
+
+type Validate interface {
+	RawRequest(s interface{}) error
+}
@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/periodic"
+	"github.com/wascript3r/autonuoma/pkg/reservation"
+	"github.com/wascript3r/cryptopay/pkg/logger"
+)
+
+// NewExpiryWorker periodically expires reservations which were not turned
+// into a trip within the hold window.
+func NewExpiryWorker(ru reservation.Usecase, log logger.Usecase, interval time.Duration) *periodic.Worker {
+	return periodic.NewWorker(
+		ru.ExpireOverdue,
+		log,
+		interval,
+		"Cannot expire overdue reservations",
+		"Expired %d overdue reservation(s)",
+	)
+}
@@ -44,6 +44,8 @@ func (u *Usecase) Submit(ctx context.Context, userID int, role domain.Role, req
 		return err
 	}
 
+	defer tx.Rollback()
+
 	meta, err := u.ticketRepo.GetMetaTx(c, tx, req.TicketID)
 	if err != nil {
 		if err == domain.ErrNotFound {
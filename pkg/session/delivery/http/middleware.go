@@ -12,6 +12,8 @@ type Middleware interface {
 	Authenticated(next httputil.HandleCtx) httputil.HandleCtx
 	NotAuthenticated(next httprouter.Handle) httprouter.Handle
 	HasRole(role domain.Role) func(next httputil.HandleCtx) httputil.HandleCtx
+	HasAnyRole(roles ...domain.Role) func(next httputil.HandleCtx) httputil.HandleCtx
+	HasScope(scope domain.APIScope) func(next httputil.HandleCtx) httputil.HandleCtx
 	SetSessionCookie(w http.ResponseWriter, ss *domain.Session)
 	DeleteSessionCookie(w http.ResponseWriter)
 }
@@ -4,9 +4,11 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/wascript3r/autonuoma/pkg/apikey"
 	"github.com/wascript3r/autonuoma/pkg/domain"
 	"github.com/wascript3r/autonuoma/pkg/session"
 	"github.com/wascript3r/cryptopay/pkg/errcode"
@@ -14,25 +16,44 @@ import (
 	httpjson "github.com/wascript3r/httputil/json"
 )
 
+const bearerPrefix = "Bearer "
+
 type HTTPMiddleware struct {
 	cookieName     string
 	cookieLifetime time.Duration
 	secureCookie   bool
 
 	sessionUcase session.Usecase
+	apiKeyUcase  apikey.Usecase
 }
 
-func NewHTTPMiddleware(cookieName string, cookieLifetime time.Duration, secureCookie bool, su session.Usecase) *HTTPMiddleware {
+func NewHTTPMiddleware(cookieName string, cookieLifetime time.Duration, secureCookie bool, su session.Usecase, au apikey.Usecase) *HTTPMiddleware {
 	return &HTTPMiddleware{
 		cookieName:     cookieName,
 		cookieLifetime: cookieLifetime,
 		secureCookie:   secureCookie,
 
 		sessionUcase: su,
+		apiKeyUcase:  au,
+	}
+}
+
+// BearerToken returns the token of the Authorization header
+func BearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	if len(h) <= len(bearerPrefix) || !strings.EqualFold(h[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
 	}
+	return strings.TrimSpace(h[len(bearerPrefix):]), true
 }
 
+// ExtractSessionID prefers the bearer token over the session cookie.
+// The bearer token can also be an API key.
 func (h *HTTPMiddleware) ExtractSessionID(r *http.Request) (string, error) {
+	if token, ok := BearerToken(r); ok {
+		return token, nil
+	}
+
 	cookie, err := r.Cookie(h.cookieName)
 	if err != nil {
 		return "", err
@@ -40,6 +61,25 @@ func (h *HTTPMiddleware) ExtractSessionID(r *http.Request) (string, error) {
 	return cookie.Value, nil
 }
 
+// validate returns the session of the session ID or a session
+// acting on behalf of the API key owner
+func (h *HTTPMiddleware) validate(ctx context.Context, id string) (*domain.Session, error) {
+	if !apikey.IsKey(id) {
+		return h.sessionUcase.Validate(ctx, id)
+	}
+
+	k, err := h.apiKeyUcase.Authenticate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Session{
+		UserID: k.UserID,
+		RoleID: k.RoleID,
+		APIKey: k,
+	}, nil
+}
+
 func (h *HTTPMiddleware) SetSessionCookie(w http.ResponseWriter, ss *domain.Session) {
 	cookie := &http.Cookie{
 		Name:     h.cookieName,
@@ -64,7 +104,19 @@ func (h *HTTPMiddleware) DeleteSessionCookie(w http.ResponseWriter) {
 	http.SetCookie(w, cookie)
 }
 
+// Authenticated does not accept API keys, see HasScope
 func (h *HTTPMiddleware) Authenticated(next httputil.HandleCtx) httputil.HandleCtx {
+	return h.authenticated("", next)
+}
+
+// HasScope also accepts API keys with the given scope
+func (h *HTTPMiddleware) HasScope(scope domain.APIScope) func(next httputil.HandleCtx) httputil.HandleCtx {
+	return func(next httputil.HandleCtx) httputil.HandleCtx {
+		return h.authenticated(scope, next)
+	}
+}
+
+func (h *HTTPMiddleware) authenticated(scope domain.APIScope, next httputil.HandleCtx) httputil.HandleCtx {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		sessionID, err := h.ExtractSessionID(r)
 		if err != nil {
@@ -72,11 +124,11 @@ func (h *HTTPMiddleware) Authenticated(next httputil.HandleCtx) httputil.HandleC
 			return
 		}
 
-		s, err := h.sessionUcase.Validate(ctx, sessionID)
+		s, err := h.validate(ctx, sessionID)
 		if err != nil {
 			code := errcode.UnwrapErr(err, session.UnknownError)
 
-			if err == session.NotAuthenticatedError || err == session.SessionExpiredError {
+			if err == session.NotAuthenticatedError || err == session.SessionExpiredError || err == apikey.InvalidAPIKeyError {
 				if err == session.SessionExpiredError {
 					h.DeleteSessionCookie(w)
 				}
@@ -88,6 +140,11 @@ func (h *HTTPMiddleware) Authenticated(next httputil.HandleCtx) httputil.HandleC
 			httpjson.InternalErrorCustom(w, code, nil)
 			return
 		}
+
+		if !domain.HasScope(s, scope) {
+			httpjson.ForbiddenCustom(w, session.InsufficientScopeError, nil)
+			return
+		}
 		ctx = h.sessionUcase.StoreCtx(ctx, s)
 
 		next(ctx, w, r, p)
@@ -141,3 +198,24 @@ func (h *HTTPMiddleware) HasRole(role domain.Role) func(next httputil.HandleCtx)
 		)
 	}
 }
+
+func (h *HTTPMiddleware) HasAnyRole(roles ...domain.Role) func(next httputil.HandleCtx) httputil.HandleCtx {
+	return func(next httputil.HandleCtx) httputil.HandleCtx {
+		return h.Authenticated(
+			func(ctx context.Context, w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+				s, err := h.sessionUcase.LoadCtx(ctx)
+				if err != nil {
+					httpjson.InternalError(w, nil)
+					return
+				}
+
+				if !domain.HasAnyRole(s, roles...) {
+					httpjson.ForbiddenCustom(w, session.InsufficientPermissionsError, nil)
+					return
+				}
+
+				next(ctx, w, r, p)
+			},
+		)
+	}
+}
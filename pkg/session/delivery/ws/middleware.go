@@ -10,6 +10,8 @@ type Middleware interface {
 	Authenticated(next router.Handler) router.Handler
 	NotAuthenticated(next router.Handler) router.Handler
 	HasRole(role domain.Role) func(next router.Handler) router.Handler
+	ExtractSession(s *gows.Socket) (*domain.Session, bool)
 	SetSession(s *gows.Socket, ss *domain.Session)
 	DeleteSession(s *gows.Socket)
+	GetSockets(sessionIDs ...string) []*gows.Socket
 }
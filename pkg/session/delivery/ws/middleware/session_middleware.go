@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"sync"
 
 	"github.com/wascript3r/autonuoma/pkg/domain"
 	"github.com/wascript3r/autonuoma/pkg/session"
@@ -14,10 +15,54 @@ const DefaultSessionKey = "sess"
 type WSMiddleware struct {
 	sessionKey   string
 	sessionUcase session.Usecase
+
+	// Authenticated sockets by session ID
+	mx      *sync.RWMutex
+	sockets map[string]map[gows.UUID]*gows.Socket
+}
+
+func NewWSMiddleware(sessionKey string, su session.Usecase, ev gows.EventBus) *WSMiddleware {
+	w := &WSMiddleware{
+		sessionKey:   sessionKey,
+		sessionUcase: su,
+
+		mx:      &sync.RWMutex{},
+		sockets: make(map[string]map[gows.UUID]*gows.Socket),
+	}
+
+	ev.Subscribe(gows.DisconnectEvent, w.handleDisconnect)
+	return w
+}
+
+func (w *WSMiddleware) handleDisconnect(_ context.Context, s *gows.Socket, _ *gows.Request) {
+	w.DeleteSession(s)
 }
 
-func NewWSMiddleware(sessionKey string, su session.Usecase) *WSMiddleware {
-	return &WSMiddleware{sessionKey, su}
+func (w *WSMiddleware) register(s *gows.Socket, sessionID string) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	ss, ok := w.sockets[sessionID]
+	if !ok {
+		ss = make(map[gows.UUID]*gows.Socket)
+		w.sockets[sessionID] = ss
+	}
+	ss[s.GetUUID()] = s
+}
+
+func (w *WSMiddleware) unregister(s *gows.Socket, sessionID string) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	ss, ok := w.sockets[sessionID]
+	if !ok {
+		return
+	}
+
+	delete(ss, s.GetUUID())
+	if len(ss) == 0 {
+		delete(w.sockets, sessionID)
+	}
 }
 
 func (w *WSMiddleware) ExtractSession(s *gows.Socket) (*domain.Session, bool) {
@@ -30,11 +75,36 @@ func (w *WSMiddleware) ExtractSession(s *gows.Socket) (*domain.Session, bool) {
 }
 
 func (w *WSMiddleware) SetSession(s *gows.Socket, ss *domain.Session) {
+	if old, ok := w.ExtractSession(s); ok {
+		w.unregister(s, old.ID)
+	}
+
 	s.SetData(w.sessionKey, ss)
+	w.register(s, ss.ID)
 }
 
 func (w *WSMiddleware) DeleteSession(s *gows.Socket) {
+	ss, ok := w.ExtractSession(s)
+	if !ok {
+		return
+	}
+
 	s.DeleteData(w.sessionKey)
+	w.unregister(s, ss.ID)
+}
+
+// GetSockets returns the sockets authenticated with any of the given sessions
+func (w *WSMiddleware) GetSockets(sessionIDs ...string) []*gows.Socket {
+	w.mx.RLock()
+	defer w.mx.RUnlock()
+
+	var sockets []*gows.Socket
+	for _, id := range sessionIDs {
+		for _, s := range w.sockets[id] {
+			sockets = append(sockets, s)
+		}
+	}
+	return sockets
 }
 
 func (w *WSMiddleware) Authenticated(next router.Handler) router.Handler {
@@ -45,11 +115,20 @@ func (w *WSMiddleware) Authenticated(next router.Handler) router.Handler {
 			return
 		}
 
-		if w.sessionUcase.IsExpired(ss) {
+		// The stored session is only a snapshot, the sliding expiration
+		// is kept up to date by validating it on every request
+		ss, err := w.sessionUcase.Validate(ctx, ss.ID)
+		switch err {
+		case nil:
+		case session.NotAuthenticatedError, session.SessionExpiredError:
 			w.DeleteSession(s)
 			router.WriteErr(s, session.SessionExpiredError, &r.Method)
 			return
+		default:
+			router.WriteInternalError(s, &r.Method)
+			return
 		}
+		w.SetSession(s, ss)
 		ctx = w.sessionUcase.StoreCtx(ctx, ss)
 
 		next(ctx, s, r)
@@ -64,11 +143,16 @@ func (w *WSMiddleware) NotAuthenticated(next router.Handler) router.Handler {
 			return
 		}
 
-		if w.sessionUcase.IsExpired(ss) {
+		_, err := w.sessionUcase.Validate(ctx, ss.ID)
+		if err == session.NotAuthenticatedError || err == session.SessionExpiredError {
 			w.DeleteSession(s)
 			next(ctx, s, r)
 			return
 		}
+		if err != nil {
+			router.WriteInternalError(s, &r.Method)
+			return
+		}
 
 		router.WriteErr(s, session.AlreadyAuthenticatedError, &r.Method)
 	}
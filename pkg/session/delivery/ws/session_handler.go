@@ -0,0 +1,33 @@
+package ws
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/session"
+	"github.com/wascript3r/gows/router"
+)
+
+const revokedReason = "session revoked"
+
+type WSHandler struct {
+	sessionMid Middleware
+}
+
+func NewWSHandler(seb session.EventBus, sm Middleware) {
+	handler := &WSHandler{
+		sessionMid: sm,
+	}
+
+	seb.Subscribe(session.RevokedEvent, handler.RevokedNotification("session/revoked"))
+}
+
+// RevokedNotification notifies and disconnects the sockets of revoked sessions
+func (w *WSHandler) RevokedNotification(method string) func(context.Context, *session.Revocation) {
+	return func(ctx context.Context, r *session.Revocation) {
+		for _, s := range w.sessionMid.GetSockets(r.SessionIDs...) {
+			w.sessionMid.DeleteSession(s)
+			router.WriteRes(s, &method, nil)
+			s.Disconnect(ctx, revokedReason)
+		}
+	}
+}
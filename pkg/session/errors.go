@@ -23,6 +23,11 @@ var (
 		errors.New("insufficient permissions"),
 	)
 
+	InsufficientScopeError = errcode.New(
+		"insufficient_scope",
+		errors.New("api key is not allowed here"),
+	)
+
 	AlreadyAuthenticatedError = errcode.New(
 		"already_authenticated",
 		errors.New("already authenticated"),
@@ -37,4 +42,14 @@ var (
 		"token_expired",
 		errors.New("token is expired"),
 	)
+
+	SessionNotFoundError = errcode.New(
+		"session_not_found",
+		errors.New("session not found"),
+	)
+
+	CurrentSessionError = errcode.New(
+		"current_session",
+		errors.New("current session cannot be revoked, log out instead"),
+	)
 )
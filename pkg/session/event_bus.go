@@ -0,0 +1,28 @@
+package session
+
+import (
+	"context"
+)
+
+type Event uint32
+
+const (
+	RevokedEvent Event = iota
+	InvalidEvent
+)
+
+func (e Event) String() string {
+	switch e {
+	case RevokedEvent:
+		return "Revoked"
+	default:
+		return "Invalid"
+	}
+}
+
+type EventHnd func(ctx context.Context, r *Revocation)
+
+type EventBus interface {
+	Subscribe(Event, EventHnd)
+	Publish(Event, context.Context, *Revocation)
+}
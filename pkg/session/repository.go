@@ -2,6 +2,7 @@ package session
 
 import (
 	"context"
+	"time"
 
 	"github.com/wascript3r/autonuoma/pkg/domain"
 )
@@ -9,5 +10,9 @@ import (
 type Repository interface {
 	Insert(ctx context.Context, ss *domain.Session) error
 	Get(ctx context.Context, id string) (*domain.Session, error)
+	GetByUser(ctx context.Context, uid int, t time.Time) ([]*domain.Session, error)
+	Touch(ctx context.Context, id string, lastSeen, expiration time.Time) error
 	Delete(ctx context.Context, id string) error
+	DeleteByUser(ctx context.Context, uid int, exceptID string) ([]string, error)
+	DeleteExpired(ctx context.Context, t time.Time, limit int) (int, error)
 }
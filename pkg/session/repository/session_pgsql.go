@@ -3,15 +3,20 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/wascript3r/autonuoma/pkg/domain"
 	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
 )
 
 const (
-	insertSQL = "INSERT INTO sesijos (id, fk_vartotojas, galiojimo_pabaiga) VALUES ($1, $2, $3)"
-	getSQL    = "SELECT s.id, s.fk_vartotojas, s.galiojimo_pabaiga, v.rolė FROM sesijos s INNER JOIN vartotojai v ON v.id = s.fk_vartotojas INNER JOIN rolės r ON r.id = v.rolė WHERE s.id = $1"
-	deleteSQL = "DELETE FROM sesijos WHERE id = $1"
+	insertSQL        = "INSERT INTO sesijos (id, fk_vartotojas, galiojimo_pabaiga, sukurta, paskutinį_kartą_matyta, ip, naršyklė) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	getSQL           = "SELECT s.id, s.fk_vartotojas, s.galiojimo_pabaiga, v.rolė, s.sukurta, s.paskutinį_kartą_matyta, s.ip, s.naršyklė FROM sesijos s INNER JOIN vartotojai v ON v.id = s.fk_vartotojas INNER JOIN rolės r ON r.id = v.rolė WHERE s.id = $1"
+	getByUserSQL     = "SELECT s.id, s.fk_vartotojas, s.galiojimo_pabaiga, v.rolė, s.sukurta, s.paskutinį_kartą_matyta, s.ip, s.naršyklė FROM sesijos s INNER JOIN vartotojai v ON v.id = s.fk_vartotojas WHERE s.fk_vartotojas = $1 AND s.galiojimo_pabaiga > $2 ORDER BY s.paskutinį_kartą_matyta DESC"
+	touchSQL         = "UPDATE sesijos SET paskutinį_kartą_matyta = $2, galiojimo_pabaiga = $3 WHERE id = $1"
+	deleteSQL        = "DELETE FROM sesijos WHERE id = $1"
+	deleteByUserSQL  = "DELETE FROM sesijos WHERE fk_vartotojas = $1 AND id <> $2 RETURNING id"
+	deleteExpiredSQL = "DELETE FROM sesijos WHERE id IN (SELECT id FROM sesijos WHERE galiojimo_pabaiga <= $1 LIMIT $2)"
 )
 
 type PgRepo struct {
@@ -23,14 +28,14 @@ func NewPgRepo(c *sql.DB) *PgRepo {
 }
 
 func (p *PgRepo) Insert(ctx context.Context, ss *domain.Session) error {
-	_, err := p.conn.ExecContext(ctx, insertSQL, ss.ID, ss.UserID, ss.Expiration)
+	_, err := p.conn.ExecContext(ctx, insertSQL, ss.ID, ss.UserID, ss.Expiration, ss.CreatedAt, ss.LastSeen, ss.IP, ss.UserAgent)
 	return err
 }
 
 func (p PgRepo) Get(ctx context.Context, id string) (*domain.Session, error) {
 	s := &domain.Session{}
 
-	err := p.conn.QueryRowContext(ctx, getSQL, id).Scan(&s.ID, &s.UserID, &s.Expiration, &s.RoleID)
+	err := p.conn.QueryRowContext(ctx, getSQL, id).Scan(&s.ID, &s.UserID, &s.Expiration, &s.RoleID, &s.CreatedAt, &s.LastSeen, &s.IP, &s.UserAgent)
 	if err != nil {
 		return nil, pgsql.ParseSQLError(err)
 	}
@@ -38,7 +43,82 @@ func (p PgRepo) Get(ctx context.Context, id string) (*domain.Session, error) {
 	return s, nil
 }
 
+// GetByUser returns the sessions of the user that are not expired at t
+func (p PgRepo) GetByUser(ctx context.Context, uid int, t time.Time) ([]*domain.Session, error) {
+	rows, err := p.conn.QueryContext(ctx, getByUserSQL, uid, t)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []*domain.Session
+
+	for rows.Next() {
+		s := &domain.Session{}
+
+		err := rows.Scan(&s.ID, &s.UserID, &s.Expiration, &s.RoleID, &s.CreatedAt, &s.LastSeen, &s.IP, &s.UserAgent)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		ss = append(ss, s)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return ss, nil
+}
+
+func (p PgRepo) Touch(ctx context.Context, id string, lastSeen, expiration time.Time) error {
+	_, err := p.conn.ExecContext(ctx, touchSQL, id, lastSeen, expiration)
+	return err
+}
+
 func (p PgRepo) Delete(ctx context.Context, id string) error {
 	_, err := p.conn.ExecContext(ctx, deleteSQL, id)
 	return err
 }
+
+// DeleteByUser deletes all sessions of the user except exceptID and returns the deleted IDs
+func (p PgRepo) DeleteByUser(ctx context.Context, uid int, exceptID string) ([]string, error) {
+	rows, err := p.conn.QueryContext(ctx, deleteByUserSQL, uid, exceptID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+
+	for rows.Next() {
+		var id string
+
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// DeleteExpired deletes at most limit sessions expired at t
+func (p PgRepo) DeleteExpired(ctx context.Context, t time.Time, limit int) (int, error) {
+	res, err := p.conn.ExecContext(ctx, deleteExpiredSQL, t, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
@@ -0,0 +1,34 @@
+package session
+
+import "time"
+
+// Revocation carries the IDs of deleted sessions
+type Revocation struct {
+	SessionIDs []string
+}
+
+// GetAll
+
+// SessionInfo never exposes the session ID itself, ID is its hash
+type SessionInfo struct {
+	ID        string    `json:"id"`
+	Current   bool      `json:"current"`
+	CreatedAt time.Time `json:"createdAt"`
+	LastSeen  time.Time `json:"lastSeen"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+}
+
+type GetAllRes struct {
+	Sessions []*SessionInfo `json:"sessions"`
+}
+
+// Revoke
+
+type RevokeReq struct {
+	ID string `json:"id"`
+}
+
+type RevokeOthersRes struct {
+	Revoked int `json:"revoked"`
+}
@@ -7,10 +7,15 @@ import (
 )
 
 type Usecase interface {
-	Create(ctx context.Context, userID int) (*domain.Session, error)
+	Create(ctx context.Context, userID int, ip, userAgent string) (*domain.Session, error)
 	IsExpired(ss *domain.Session) bool
 	Validate(ctx context.Context, id string) (*domain.Session, error)
 	Delete(ctx context.Context, id string) error
+	GetAll(ctx context.Context, ss *domain.Session) (*GetAllRes, error)
+	Revoke(ctx context.Context, ss *domain.Session, req *RevokeReq) error
+	RevokeOthers(ctx context.Context, ss *domain.Session) (*RevokeOthersRes, error)
+	NotifyRevoked(ctx context.Context, ids []string)
+	PurgeExpired(ctx context.Context) (int, error)
 	GenTempToken(ss *domain.Session) (string, error)
 	ValidateTempToken(ctx context.Context, token string) (*domain.Session, error)
 	StoreCtx(ctx context.Context, ss *domain.Session) context.Context
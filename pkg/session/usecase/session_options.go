@@ -5,11 +5,19 @@ import "time"
 const (
 	DefaultSessionExpiration = time.Hour
 	DefaultTokenExpiration   = 30 * time.Second
+	DefaultCleanupBatchSize  = 1000
 )
 
 type Options struct {
 	SessionLifetime time.Duration
 	TokenLifetime   time.Duration
+
+	// Activity extends the session by SessionLifetime, but never
+	// beyond MaxLifetime after it was created
+	Sliding     bool
+	MaxLifetime time.Duration
+
+	CleanupBatchSize int
 }
 
 type Option func(*Options)
@@ -27,6 +35,12 @@ func newOptions(opt ...Option) *Options {
 	if opts.TokenLifetime == 0 {
 		opts.TokenLifetime = DefaultTokenExpiration
 	}
+	if opts.MaxLifetime < opts.SessionLifetime {
+		opts.MaxLifetime = opts.SessionLifetime
+	}
+	if opts.CleanupBatchSize == 0 {
+		opts.CleanupBatchSize = DefaultCleanupBatchSize
+	}
 
 	return opts
 }
@@ -42,3 +56,16 @@ func TokenLifetime(t time.Duration) Option {
 		o.TokenLifetime = t
 	}
 }
+
+func SlidingExpiration(maxLifetime time.Duration) Option {
+	return func(o *Options) {
+		o.Sliding = true
+		o.MaxLifetime = maxLifetime
+	}
+}
+
+func CleanupBatchSize(n int) Option {
+	return func(o *Options) {
+		o.CleanupBatchSize = n
+	}
+}
@@ -2,47 +2,66 @@ package usecase
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/wascript3r/autonuoma/pkg/domain"
 	"github.com/wascript3r/autonuoma/pkg/session"
 	"github.com/wascript3r/gocipher/encoder"
+	"github.com/wascript3r/gocipher/sha256"
 )
 
 const (
 	ctxKey = "session"
+
+	// Last-seen time is not written on every request
+	touchInterval = time.Minute
+
+	maxUserAgentLen = 255
 )
 
 type Usecase struct {
 	sessionRepo session.Repository
 	ctxTimeout  time.Duration
 
-	generator session.Generator
-	cipher    session.Cipher
-	opts      *Options
+	generator       session.Generator
+	cipher          session.Cipher
+	sessionEventBus session.EventBus
+	opts            *Options
 }
 
-func New(sr session.Repository, t time.Duration, g session.Generator, c session.Cipher, opt ...Option) *Usecase {
+func New(sr session.Repository, t time.Duration, g session.Generator, c session.Cipher, seb session.EventBus, opt ...Option) *Usecase {
 	return &Usecase{
 		sessionRepo: sr,
 		ctxTimeout:  t,
 
-		generator: g,
-		cipher:    c,
-		opts:      newOptions(opt...),
+		generator:       g,
+		cipher:          c,
+		sessionEventBus: seb,
+		opts:            newOptions(opt...),
 	}
 }
 
-func (u *Usecase) Create(ctx context.Context, userID int) (*domain.Session, error) {
+func (u *Usecase) Create(ctx context.Context, userID int, ip, userAgent string) (*domain.Session, error) {
 	id, err := u.generator.GenerateID()
 	if err != nil {
 		return nil, err
 	}
 
+	if len(userAgent) > maxUserAgentLen {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLen], "")
+	}
+
+	now := time.Now()
 	ss := &domain.Session{
 		ID:         id,
 		UserID:     userID,
-		Expiration: time.Now().Add(u.opts.SessionLifetime),
+		Expiration: u.expiration(now, now),
+
+		CreatedAt: now,
+		LastSeen:  now,
+		IP:        ip,
+		UserAgent: userAgent,
 	}
 
 	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
@@ -56,6 +75,15 @@ func (u *Usecase) Create(ctx context.Context, userID int) (*domain.Session, erro
 	return ss, nil
 }
 
+// expiration of a session created at c and last seen at t
+func (u *Usecase) expiration(c, t time.Time) time.Time {
+	exp := t.Add(u.opts.SessionLifetime)
+	if max := c.Add(u.opts.MaxLifetime); exp.After(max) {
+		return max
+	}
+	return exp
+}
+
 func (u *Usecase) IsExpired(ss *domain.Session) bool {
 	return time.Now().After(ss.Expiration)
 }
@@ -77,6 +105,20 @@ func (u *Usecase) Validate(ctx context.Context, id string) (*domain.Session, err
 		return nil, session.SessionExpiredError
 	}
 
+	if now := time.Now(); now.Sub(s.LastSeen) >= touchInterval {
+		exp := s.Expiration
+		if u.opts.Sliding {
+			exp = u.expiration(s.CreatedAt, now)
+		}
+
+		err = u.sessionRepo.Touch(c, id, now, exp)
+		if err != nil {
+			return nil, err
+		}
+		s.LastSeen = now
+		s.Expiration = exp
+	}
+
 	return s, nil
 }
 
@@ -84,7 +126,120 @@ func (u *Usecase) Delete(ctx context.Context, id string) error {
 	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
 	defer cancel()
 
-	return u.sessionRepo.Delete(c, id)
+	err := u.sessionRepo.Delete(c, id)
+	if err != nil {
+		return err
+	}
+
+	u.NotifyRevoked(ctx, []string{id})
+	return nil
+}
+
+// PurgeExpired deletes expired sessions in batches and returns the number of deleted ones
+func (u *Usecase) PurgeExpired(ctx context.Context) (int, error) {
+	now := time.Now()
+	total := 0
+
+	for {
+		c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+		n, err := u.sessionRepo.DeleteExpired(c, now, u.opts.CleanupBatchSize)
+		cancel()
+		if err != nil {
+			return total, err
+		}
+
+		total += n
+		if n < u.opts.CleanupBatchSize || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
+
+// publicID identifies a session to its owner without disclosing the cookie value
+func publicID(id string) string {
+	return string(encoder.HexEncode(sha256.Compute([]byte(id))))
+}
+
+func (u *Usecase) GetAll(ctx context.Context, ss *domain.Session) (*session.GetAllRes, error) {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	sessions, err := u.sessionRepo.GetByUser(c, ss.UserID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	res := &session.GetAllRes{
+		Sessions: make([]*session.SessionInfo, len(sessions)),
+	}
+	for i, s := range sessions {
+		res.Sessions[i] = &session.SessionInfo{
+			ID:        publicID(s.ID),
+			Current:   s.ID == ss.ID,
+			CreatedAt: s.CreatedAt,
+			LastSeen:  s.LastSeen,
+			IP:        s.IP,
+			UserAgent: s.UserAgent,
+		}
+	}
+
+	return res, nil
+}
+
+func (u *Usecase) Revoke(ctx context.Context, ss *domain.Session, req *session.RevokeReq) error {
+	if req.ID == publicID(ss.ID) {
+		return session.CurrentSessionError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	sessions, err := u.sessionRepo.GetByUser(c, ss.UserID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sessions {
+		if publicID(s.ID) != req.ID {
+			continue
+		}
+
+		err = u.sessionRepo.Delete(c, s.ID)
+		if err != nil {
+			return err
+		}
+
+		u.NotifyRevoked(ctx, []string{s.ID})
+		return nil
+	}
+
+	return session.SessionNotFoundError
+}
+
+// RevokeOthers logs the user out everywhere except the current session
+func (u *Usecase) RevokeOthers(ctx context.Context, ss *domain.Session) (*session.RevokeOthersRes, error) {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	ids, err := u.sessionRepo.DeleteByUser(c, ss.UserID, ss.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.NotifyRevoked(ctx, ids)
+	return &session.RevokeOthersRes{Revoked: len(ids)}, nil
+}
+
+// NotifyRevoked announces sessions that are already deleted,
+// including the ones deleted outside of this usecase
+func (u *Usecase) NotifyRevoked(ctx context.Context, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+
+	u.sessionEventBus.Publish(session.RevokedEvent, ctx, &session.Revocation{
+		SessionIDs: ids,
+	})
 }
 
 func (u *Usecase) GenTempToken(ss *domain.Session) (string, error) {
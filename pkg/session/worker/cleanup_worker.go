@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/periodic"
+	"github.com/wascript3r/autonuoma/pkg/session"
+	"github.com/wascript3r/cryptopay/pkg/logger"
+)
+
+// NewCleanupWorker periodically purges expired sessions, which
+// otherwise are deleted only when they are presented.
+func NewCleanupWorker(su session.Usecase, log logger.Usecase, interval time.Duration) *periodic.Worker {
+	return periodic.NewWorker(
+		su.PurgeExpired,
+		log,
+		interval,
+		"Cannot purge expired sessions",
+		"Purged %d expired session(s)",
+	)
+}
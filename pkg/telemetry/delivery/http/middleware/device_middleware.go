@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/wascript3r/autonuoma/pkg/apikey"
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	sessionMid "github.com/wascript3r/autonuoma/pkg/session/delivery/http/middleware"
+	"github.com/wascript3r/autonuoma/pkg/telemetry"
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+	httpjson "github.com/wascript3r/httputil/json"
+)
+
+const (
+	DeviceTokenHeader = "X-Device-Token"
+
+	ctxKey = "device_car"
+)
+
+type HTTPMiddleware struct {
+	apiKeyUcase apikey.Usecase
+}
+
+func NewHTTPMiddleware(au apikey.Usecase) *HTTPMiddleware {
+	return &HTTPMiddleware{au}
+}
+
+// Authenticated lets through devices presenting an API key with the
+// telemetry scope, as a bearer token or in the device token header.
+// The key is bound to the only car the device may report for.
+func (h *HTTPMiddleware) Authenticated(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		key, ok := sessionMid.BearerToken(r)
+		if !ok {
+			key = r.Header.Get(DeviceTokenHeader)
+		}
+
+		k, err := h.apiKeyUcase.Authenticate(r.Context(), key)
+		if err != nil {
+			if err == apikey.InvalidAPIKeyError {
+				httpjson.ForbiddenCustom(w, telemetry.InvalidDeviceTokenError, nil)
+				return
+			}
+
+			httpjson.InternalErrorCustom(w, errcode.UnwrapErr(err, telemetry.UnknownError), nil)
+			return
+		}
+
+		if !k.HasScope(domain.TelemetryScope) || k.CarID == nil {
+			httpjson.ForbiddenCustom(w, telemetry.InvalidDeviceTokenError, nil)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ctxKey, *k.CarID)
+		next(w, r.WithContext(ctx), p)
+	}
+}
+
+// LoadCarID returns the car of the authenticated device
+func LoadCarID(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(ctxKey).(int)
+	return id, ok
+}
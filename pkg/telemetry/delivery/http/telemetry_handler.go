@@ -0,0 +1,89 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/wascript3r/autonuoma/pkg/telemetry"
+	deviceMid "github.com/wascript3r/autonuoma/pkg/telemetry/delivery/http/middleware"
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+	httpjson "github.com/wascript3r/httputil/json"
+	"github.com/wascript3r/httputil/middleware"
+)
+
+type HTTPHandler struct {
+	telemetryUcase telemetry.Usecase
+}
+
+func NewHTTPHandler(r *httprouter.Router, device *middleware.Stack, tu telemetry.Usecase) {
+	handler := &HTTPHandler{
+		telemetryUcase: tu,
+	}
+
+	r.POST("/api/telemetry", device.Wrap(handler.Report))
+	r.POST("/api/telemetry/track", device.Wrap(handler.Track))
+}
+
+func serveError(w http.ResponseWriter, err error) {
+	if err == telemetry.InvalidInputError {
+		httpjson.BadRequestCustom(w, telemetry.InvalidInputError, nil)
+		return
+	}
+
+	code := errcode.UnwrapErr(err, telemetry.UnknownError)
+	if code == telemetry.UnknownError {
+		httpjson.InternalErrorCustom(w, code, nil)
+		return
+	}
+
+	httpjson.ServeErr(w, code, nil)
+}
+
+func (h *HTTPHandler) Report(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	carID, ok := deviceMid.LoadCarID(r.Context())
+	if !ok {
+		httpjson.InternalError(w, nil)
+		return
+	}
+
+	req := &telemetry.ReportReq{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	err = h.telemetryUcase.Report(r.Context(), carID, req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, nil)
+}
+
+func (h *HTTPHandler) Track(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	carID, ok := deviceMid.LoadCarID(r.Context())
+	if !ok {
+		httpjson.InternalError(w, nil)
+		return
+	}
+
+	req := &telemetry.TrackReq{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.telemetryUcase.Track(r.Context(), carID, req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
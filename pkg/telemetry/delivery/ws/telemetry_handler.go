@@ -0,0 +1,117 @@
+package ws
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/reservation"
+	"github.com/wascript3r/autonuoma/pkg/room"
+	"github.com/wascript3r/autonuoma/pkg/telemetry"
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+	"github.com/wascript3r/gows"
+	"github.com/wascript3r/gows/middleware"
+	"github.com/wascript3r/gows/pool"
+	"github.com/wascript3r/gows/router"
+)
+
+type WSHandler struct {
+	roomUcase room.Usecase
+
+	socketPool *pool.Pool
+}
+
+func NewWSHandler(r *router.Router, auth *middleware.Stack, teb telemetry.EventBus, reb reservation.EventBus, ru room.Usecase, socketPool *pool.Pool) {
+	handler := &WSHandler{
+		roomUcase: ru,
+
+		socketPool: socketPool,
+	}
+
+	teb.Subscribe(telemetry.UpdatedTelemetryEvent, handler.PositionNotification("cars/live/position"))
+
+	reb.Subscribe(reservation.CreatedReservationEvent, handler.AvailabilityNotification("cars/live/availability", false))
+	reb.Subscribe(reservation.CancelledReservationEvent, handler.AvailabilityNotification("cars/live/availability", true))
+	reb.Subscribe(reservation.ExpiredReservationEvent, handler.AvailabilityNotification("cars/live/availability", true))
+	reb.Subscribe(reservation.CompletedReservationEvent, handler.AvailabilityNotification("cars/live/availability", true))
+
+	r.HandleMethod("cars/live/subscribe", auth.Wrap(handler.Subscribe))
+	r.HandleMethod("cars/live/unsubscribe", auth.Wrap(handler.Unsubscribe))
+}
+
+func serveError(s *gows.Socket, r *router.Request, err error) {
+	code := errcode.UnwrapErr(err, telemetry.UnknownError)
+	router.WriteErr(s, code, &r.Method)
+}
+
+func (w *WSHandler) emit(method string, data interface{}) {
+	rName, err := w.roomUcase.GetName(domain.CarsLiveRoom)
+	if err != nil {
+		return
+	}
+
+	w.socketPool.EmitRoom(pool.RoomName(rName), &router.Response{
+		Error:  nil,
+		Method: &method,
+		Data:   data,
+	})
+}
+
+// PositionNotification publishes only the positions of available cars,
+// cars in use would reveal where their renters are
+func (w *WSHandler) PositionNotification(method string) telemetry.EventHnd {
+	return func(_ context.Context, t *domain.Telemetry) {
+		if !t.Available {
+			return
+		}
+
+		w.emit(method, &telemetry.PositionRes{
+			CarID:      t.CarID,
+			Latitude:   t.Position.Lat,
+			Longitude:  t.Position.Lng,
+			FuelLevel:  t.FuelLevel,
+			Odometer:   t.Odometer,
+			ReportedAt: t.ReportedAt,
+		})
+	}
+}
+
+func (w *WSHandler) AvailabilityNotification(method string, available bool) reservation.EventHnd {
+	return func(_ context.Context, r *domain.Reservation) {
+		w.emit(method, &telemetry.AvailabilityRes{
+			CarID:     r.CarID,
+			Available: available,
+		})
+	}
+}
+
+func (w *WSHandler) Subscribe(_ context.Context, s *gows.Socket, r *router.Request) {
+	rName, err := w.roomUcase.GetName(domain.CarsLiveRoom)
+	if err != nil {
+		serveError(s, r, err)
+		return
+	}
+
+	err = w.socketPool.JoinRoom(s, pool.RoomName(rName))
+	if err != nil {
+		serveError(s, r, err)
+		return
+	}
+
+	router.WriteRes(s, &r.Method, nil)
+}
+
+func (w *WSHandler) Unsubscribe(_ context.Context, s *gows.Socket, r *router.Request) {
+	rName, err := w.roomUcase.GetName(domain.CarsLiveRoom)
+	if err != nil {
+		serveError(s, r, err)
+		return
+	}
+
+	err = w.socketPool.LeaveRoom(s, pool.RoomName(rName))
+	if err != nil {
+		serveError(s, r, err)
+		return
+	}
+
+	router.WriteRes(s, &r.Method, nil)
+}
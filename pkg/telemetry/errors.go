@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"errors"
+
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+)
+
+var (
+	// Error codes
+
+	InvalidInputError = errcode.InvalidInputError
+	UnknownError      = errcode.UnknownError
+
+	CarNotFoundError = errcode.New(
+		"car_not_found",
+		errors.New("car not found"),
+	)
+
+	InvalidDeviceTokenError = errcode.New(
+		"invalid_device_token",
+		errors.New("invalid device token"),
+	)
+
+	CarNotAllowedError = errcode.New(
+		"car_not_allowed",
+		errors.New("device is not allowed to report for this car"),
+	)
+
+	NoActiveTripError = errcode.New(
+		"no_active_trip",
+		errors.New("car has no active trip"),
+	)
+
+	InvalidTrackPointError = errcode.New(
+		"invalid_track_point",
+		errors.New("track point is recorded outside of the trip"),
+	)
+)
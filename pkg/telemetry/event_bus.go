@@ -0,0 +1,30 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Event uint32
+
+const (
+	UpdatedTelemetryEvent Event = iota
+	InvalidEvent
+)
+
+func (e Event) String() string {
+	switch e {
+	case UpdatedTelemetryEvent:
+		return "UpdatedTelemetry"
+	default:
+		return "Invalid"
+	}
+}
+
+type EventHnd func(ctx context.Context, t *domain.Telemetry)
+
+type EventBus interface {
+	Subscribe(Event, EventHnd)
+	Publish(Event, context.Context, *domain.Telemetry)
+}
@@ -0,0 +1,14 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Repository interface {
+	Update(ctx context.Context, t *domain.Telemetry) error
+	GetActiveTrip(ctx context.Context, carID int) (int, time.Time, error)
+	AddTrackPoints(ctx context.Context, tripID int, ps []*domain.TrackPoint) (int, error)
+}
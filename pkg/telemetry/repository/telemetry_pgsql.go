@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
+)
+
+const (
+	// Reported positions are also recorded as the track of the car's ongoing trip
+	updateSQL = "WITH a AS (UPDATE automobiliai a SET pozicijos_platuma = $2, pozicijos_ilguma = $3, kuro_lygis = $4, rida = $5, telemetrija_gauta = $6 WHERE a.id = $1 AND a.pašalintas = false RETURNING a.id, NOT EXISTS(SELECT 1 FROM rezervacijos r WHERE r.fk_automobilis = a.id AND r.būsena IN (1, 2, 3)) AS laisvas), t AS (INSERT INTO kelionių_taškai (fk_kelione, platuma, ilguma, užfiksuota) SELECT k.id, $2, $3, $6 FROM a INNER JOIN rezervacijos r ON (r.fk_automobilis = a.id AND r.būsena = 3) INNER JOIN kelionės k ON (k.fk_rezervacija = r.id AND k.pabaigos_laikas IS NULL) ON CONFLICT DO NOTHING) SELECT laisvas FROM a"
+
+	getActiveTripSQL = "SELECT k.id, k.pradžios_laikas FROM kelionės k INNER JOIN rezervacijos r ON (r.id = k.fk_rezervacija) WHERE r.fk_automobilis = $1 AND r.būsena = 3 AND k.pabaigos_laikas IS NULL"
+	// Retried batches are ignored by the unique recording time per trip and
+	// batches arriving after the trip has ended are dropped
+	addTrackPointsSQL = "INSERT INTO kelionių_taškai (fk_kelione, platuma, ilguma, užfiksuota) SELECT $1, p.platuma, p.ilguma, p.užfiksuota FROM UNNEST($2::float8[], $3::float8[], $4::timestamptz[]) AS p (platuma, ilguma, užfiksuota) WHERE EXISTS(SELECT 1 FROM kelionės k WHERE k.id = $1 AND k.pabaigos_laikas IS NULL) ON CONFLICT DO NOTHING"
+)
+
+type PgRepo struct {
+	conn *sql.DB
+}
+
+func NewPgRepo(c *sql.DB) *PgRepo {
+	return &PgRepo{c}
+}
+
+func (p *PgRepo) Update(ctx context.Context, t *domain.Telemetry) error {
+	err := p.conn.QueryRowContext(
+		ctx,
+		updateSQL,
+
+		t.CarID,
+		t.Position.Lat,
+		t.Position.Lng,
+		t.FuelLevel,
+		t.Odometer,
+		t.ReportedAt,
+	).Scan(&t.Available)
+	if err != nil {
+		return pgsql.ParseSQLError(err)
+	}
+
+	return nil
+}
+
+func (p *PgRepo) GetActiveTrip(ctx context.Context, carID int) (int, time.Time, error) {
+	var (
+		tripID int
+		begin  time.Time
+	)
+
+	err := p.conn.QueryRowContext(ctx, getActiveTripSQL, carID).Scan(&tripID, &begin)
+	if err != nil {
+		return 0, time.Time{}, pgsql.ParseSQLError(err)
+	}
+
+	return tripID, begin, nil
+}
+
+func (p *PgRepo) AddTrackPoints(ctx context.Context, tripID int, ps []*domain.TrackPoint) (int, error) {
+	var (
+		lats = make(pq.Float64Array, len(ps))
+		lngs = make(pq.Float64Array, len(ps))
+		ts   = make(pq.StringArray, len(ps))
+	)
+
+	for i, pt := range ps {
+		lats[i] = pt.Lat
+		lngs[i] = pt.Lng
+		ts[i] = pt.RecordedAt.Format(time.RFC3339Nano)
+	}
+
+	res, err := p.conn.ExecContext(ctx, addTrackPointsSQL, tripID, lats, lngs, ts)
+	if err != nil {
+		return 0, pgsql.ParsePgError(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n), nil
+}
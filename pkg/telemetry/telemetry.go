@@ -0,0 +1,46 @@
+package telemetry
+
+import "time"
+
+// Report
+
+type ReportReq struct {
+	CarID     int     `json:"carID" validate:"required"`
+	Latitude  float64 `json:"lat" validate:"latitude"`
+	Longitude float64 `json:"lng" validate:"longitude"`
+	FuelLevel float64 `json:"fuelLevel" validate:"gte=0,lte=100"`
+	Odometer  float64 `json:"odometer" validate:"gte=0"`
+}
+
+// Track
+
+type TrackPointReq struct {
+	Latitude   float64   `json:"lat" validate:"latitude"`
+	Longitude  float64   `json:"lng" validate:"longitude"`
+	RecordedAt time.Time `json:"recordedAt" validate:"required"`
+}
+
+type TrackReq struct {
+	CarID  int              `json:"carID" validate:"required"`
+	Points []*TrackPointReq `json:"points" validate:"required,min=1,max=500,dive,required"`
+}
+
+type TrackRes struct {
+	Accepted int `json:"accepted"`
+}
+
+// Live feed
+
+type PositionRes struct {
+	CarID      int       `json:"carID"`
+	Latitude   float64   `json:"lat"`
+	Longitude  float64   `json:"lng"`
+	FuelLevel  float64   `json:"fuelLevel"`
+	Odometer   float64   `json:"odometer"`
+	ReportedAt time.Time `json:"reportedAt"`
+}
+
+type AvailabilityRes struct {
+	CarID     int  `json:"carID"`
+	Available bool `json:"available"`
+}
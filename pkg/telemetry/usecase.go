@@ -0,0 +1,8 @@
+package telemetry
+
+import "context"
+
+type Usecase interface {
+	Report(ctx context.Context, deviceCarID int, req *ReportReq) error
+	Track(ctx context.Context, deviceCarID int, req *TrackReq) (*TrackRes, error)
+}
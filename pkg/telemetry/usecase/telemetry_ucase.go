@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/telemetry"
+)
+
+// Tolerated difference between the vehicle and the server clocks
+const trackClockSkew = time.Minute
+
+type Usecase struct {
+	telemetryRepo telemetry.Repository
+	ctxTimeout    time.Duration
+
+	eventBus telemetry.EventBus
+	validate telemetry.Validate
+}
+
+func New(tr telemetry.Repository, t time.Duration, eb telemetry.EventBus, v telemetry.Validate) *Usecase {
+	return &Usecase{
+		telemetryRepo: tr,
+		ctxTimeout:    t,
+
+		eventBus: eb,
+		validate: v,
+	}
+}
+
+// Report updates the car the reporting device is bound to
+func (u *Usecase) Report(ctx context.Context, deviceCarID int, req *telemetry.ReportReq) error {
+	if err := u.validate.RawRequest(req); err != nil {
+		return telemetry.InvalidInputError
+	}
+
+	if req.CarID != deviceCarID {
+		return telemetry.CarNotAllowedError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	t := &domain.Telemetry{
+		CarID:      req.CarID,
+		Position:   domain.Point{Lat: req.Latitude, Lng: req.Longitude},
+		FuelLevel:  req.FuelLevel,
+		Odometer:   req.Odometer,
+		ReportedAt: time.Now(),
+	}
+
+	err := u.telemetryRepo.Update(c, t)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return telemetry.CarNotFoundError
+		}
+		return err
+	}
+
+	u.eventBus.Publish(telemetry.UpdatedTelemetryEvent, ctx, t)
+
+	return nil
+}
+
+// Track records a batch of points of the ongoing trip of the car the
+// reporting device is bound to
+func (u *Usecase) Track(ctx context.Context, deviceCarID int, req *telemetry.TrackReq) (*telemetry.TrackRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, telemetry.InvalidInputError
+	}
+
+	if req.CarID != deviceCarID {
+		return nil, telemetry.CarNotAllowedError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tripID, begin, err := u.telemetryRepo.GetActiveTrip(c, req.CarID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, telemetry.NoActiveTripError
+		}
+		return nil, err
+	}
+
+	from := begin.Add(-trackClockSkew)
+	to := time.Now().Add(trackClockSkew)
+
+	ps := make([]*domain.TrackPoint, len(req.Points))
+	for i, p := range req.Points {
+		if p.RecordedAt.Before(from) || p.RecordedAt.After(to) {
+			return nil, telemetry.InvalidTrackPointError
+		}
+
+		ps[i] = &domain.TrackPoint{
+			Point:      domain.Point{Lat: p.Latitude, Lng: p.Longitude},
+			RecordedAt: p.RecordedAt,
+		}
+	}
+
+	n, err := u.telemetryRepo.AddTrackPoints(c, tripID, ps)
+	if err != nil {
+		return nil, err
+	}
+
+	return &telemetry.TrackRes{Accepted: n}, nil
+}
@@ -0,0 +1,5 @@
+package telemetry
+
+type Validate interface {
+	RawRequest(s interface{}) error
+}
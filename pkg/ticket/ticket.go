@@ -5,6 +5,7 @@ import (
 
 	"github.com/wascript3r/autonuoma/pkg/domain"
 	"github.com/wascript3r/autonuoma/pkg/message"
+	"github.com/wascript3r/autonuoma/pkg/refund"
 	"github.com/wascript3r/autonuoma/pkg/review"
 	"github.com/wascript3r/autonuoma/pkg/user"
 )
@@ -47,6 +48,7 @@ type GetFullReq struct {
 type GetFullRes struct {
 	Ticket   *TicketInfo            `json:"ticket"`
 	Messages []*message.MessageInfo `json:"messages"`
+	Refunds  []*refund.RefundInfo   `json:"refunds"`
 }
 
 // GetAll
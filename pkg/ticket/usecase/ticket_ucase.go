@@ -7,6 +7,7 @@ import (
 
 	"github.com/wascript3r/autonuoma/pkg/domain"
 	"github.com/wascript3r/autonuoma/pkg/message"
+	"github.com/wascript3r/autonuoma/pkg/refund"
 	"github.com/wascript3r/autonuoma/pkg/review"
 	"github.com/wascript3r/autonuoma/pkg/ticket"
 	"github.com/wascript3r/autonuoma/pkg/user"
@@ -16,17 +17,19 @@ type Usecase struct {
 	ticketRepo  ticket.Repository
 	messageRepo message.Repository
 	reviewRepo  review.Repository
+	refundRepo  refund.Repository
 	ctxTimeout  time.Duration
 
 	ticketEventBus ticket.EventBus
 	validate       ticket.Validate
 }
 
-func New(tr ticket.Repository, mr message.Repository, rr review.Repository, t time.Duration, teb ticket.EventBus, v ticket.Validate) *Usecase {
+func New(tr ticket.Repository, mr message.Repository, rr review.Repository, rfr refund.Repository, t time.Duration, teb ticket.EventBus, v ticket.Validate) *Usecase {
 	return &Usecase{
 		ticketRepo:  tr,
 		messageRepo: mr,
 		reviewRepo:  rr,
+		refundRepo:  rfr,
 		ctxTimeout:  t,
 
 		ticketEventBus: teb,
@@ -47,6 +50,8 @@ func (u *Usecase) Create(ctx context.Context, clientID int, req *ticket.CreateRe
 		return nil, err
 	}
 
+	defer tx.Rollback()
+
 	_, err = u.ticketRepo.GetLastActiveIDTx(c, tx, clientID)
 	if err != domain.ErrNotFound {
 		if err != nil {
@@ -103,6 +108,8 @@ func (u *Usecase) Accept(ctx context.Context, agentID int, req *ticket.AcceptReq
 		return err
 	}
 
+	defer tx.Rollback()
+
 	meta, err := u.ticketRepo.GetMetaTx(c, tx, req.TicketID)
 	if err != nil {
 		if err == domain.ErrNotFound {
@@ -150,6 +157,8 @@ func (u *Usecase) End(ctx context.Context, userID int, role domain.Role, req *ti
 		return err
 	}
 
+	defer tx.Rollback()
+
 	meta, err := u.ticketRepo.GetMetaTx(c, tx, req.TicketID)
 	if err != nil {
 		if err == domain.ErrNotFound {
@@ -224,6 +233,11 @@ func (u *Usecase) GetFull(ctx context.Context, userID int, role domain.Role, req
 		return nil, err
 	}
 
+	rfs, err := u.refundRepo.GetByTicket(c, req.TicketID)
+	if err != nil {
+		return nil, err
+	}
+
 	messages := make([]*message.MessageInfo, len(ms))
 	for i, m := range ms {
 		messages[i] = &message.MessageInfo{
@@ -237,6 +251,23 @@ func (u *Usecase) GetFull(ctx context.Context, userID int, role domain.Role, req
 		}
 	}
 
+	refunds := make([]*refund.RefundInfo, len(rfs))
+	for i, r := range rfs {
+		refunds[i] = &refund.RefundInfo{
+			ID: r.ID,
+			Issuer: &user.UserInfo{
+				ID:        r.IssuerMeta.ID,
+				FirstName: r.IssuerMeta.FirstName,
+				LastName:  r.IssuerMeta.LastName,
+			},
+			TripID:    r.TripID,
+			PaymentID: r.PaymentID,
+			Amount:    r.Amount,
+			Reason:    r.Reason,
+			Time:      r.Created,
+		}
+	}
+
 	res := &ticket.GetFullRes{
 		Ticket: &ticket.TicketInfo{
 			ID:      req.TicketID,
@@ -245,6 +276,7 @@ func (u *Usecase) GetFull(ctx context.Context, userID int, role domain.Role, req
 			Review:  nil,
 		},
 		Messages: messages,
+		Refunds:  refunds,
 	}
 	if rs != nil {
 		res.Ticket.Review = &review.ReviewInfo{
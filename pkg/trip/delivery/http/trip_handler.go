@@ -3,40 +3,45 @@ package http
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"github.com/wascript3r/autonuoma/pkg/trip"
 	"net/http"
 	"strconv"
 
 	"github.com/julienschmidt/httprouter"
-	"github.com/wascript3r/autonuoma/pkg/user"
+	"github.com/wascript3r/autonuoma/pkg/session"
+	"github.com/wascript3r/autonuoma/pkg/trip"
 	"github.com/wascript3r/cryptopay/pkg/errcode"
 	httpjson "github.com/wascript3r/httputil/json"
 	"github.com/wascript3r/httputil/middleware"
 )
 
 type HTTPHandler struct {
-	tripUsecase trip.Usecase
+	tripUsecase  trip.Usecase
+	sessionUcase session.Usecase
 }
 
-func NewHTTPHandler(ctx context.Context, r *httprouter.Router, auth *middleware.StackCtx, tu trip.Usecase) {
+func NewHTTPHandler(ctx context.Context, r *httprouter.Router, client *middleware.StackCtx, staff *middleware.StackCtx, tu trip.Usecase, su session.Usecase) {
 	handler := &HTTPHandler{
-		tripUsecase: tu,
+		tripUsecase:  tu,
+		sessionUcase: su,
 	}
 
-	r.POST("/api/trip/start", auth.Wrap(ctx, handler.StartTrip))
-	r.POST("/api/trip/end", auth.Wrap(ctx, handler.EndTrip))
-	r.GET("/api/trip/:id", auth.Wrap(ctx, handler.GetById))
+	r.POST("/api/trip/start", client.Wrap(ctx, handler.StartTrip))
+	r.POST("/api/trip/end", client.Wrap(ctx, handler.EndTrip))
+	r.POST("/api/trip/pay", client.Wrap(ctx, handler.PayTrip))
+	r.GET("/api/trip/:id", client.Wrap(ctx, handler.GetById))
+
+	r.POST("/api/agent/trip/end", staff.Wrap(ctx, handler.EndTrip))
+	r.GET("/api/agent/trip/:id", staff.Wrap(ctx, handler.GetById))
 }
 
 func serveError(w http.ResponseWriter, err error) {
-	if err == user.InvalidInputError {
-		httpjson.BadRequestCustom(w, user.InvalidInputError, nil)
+	if err == trip.InvalidInputError {
+		httpjson.BadRequestCustom(w, trip.InvalidInputError, nil)
 		return
 	}
 
-	code := errcode.UnwrapErr(err, user.UnknownError)
-	if code == user.UnknownError {
+	code := errcode.UnwrapErr(err, trip.UnknownError)
+	if code == trip.UnknownError {
 		httpjson.InternalErrorCustom(w, code, nil)
 		return
 	}
@@ -44,18 +49,21 @@ func serveError(w http.ResponseWriter, err error) {
 	httpjson.ServeErr(w, code, nil)
 }
 
-func (h *HTTPHandler) StartTrip(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+func (h *HTTPHandler) StartTrip(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
 	req := &trip.StartReq{}
-	fmt.Println("trip start in handler")
 
-	err := json.NewDecoder(r.Body).Decode(req)
-	fmt.Println(err)
+	err = json.NewDecoder(r.Body).Decode(req)
 	if err != nil {
 		httpjson.BadRequest(w, nil)
 		return
 	}
 
-	res, err := h.tripUsecase.Start(r.Context(), req)
+	res, err := h.tripUsecase.Start(r.Context(), s, req)
 	if err != nil {
 		serveError(w, err)
 		return
@@ -64,30 +72,66 @@ func (h *HTTPHandler) StartTrip(_ context.Context, w http.ResponseWriter, r *htt
 	httpjson.ServeJSON(w, res)
 }
 
-func (h *HTTPHandler) EndTrip(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+func (h *HTTPHandler) EndTrip(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
 	req := &trip.EndReq{}
 
-	err := json.NewDecoder(r.Body).Decode(req)
+	err = json.NewDecoder(r.Body).Decode(req)
 	if err != nil {
 		httpjson.BadRequest(w, nil)
 		return
 	}
 
-	err = h.tripUsecase.End(r.Context(), req)
+	res, err := h.tripUsecase.End(r.Context(), s, req)
 	if err != nil {
 		serveError(w, err)
 		return
 	}
 
-	httpjson.ServeJSON(w, nil)
+	httpjson.ServeJSON(w, res)
 }
 
-func (h *HTTPHandler) GetById(_ context.Context, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	id, err := strconv.Atoi(ps.ByName("id"))
+func (h *HTTPHandler) PayTrip(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+	req := &trip.PayReq{}
+
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.tripUsecase.Pay(r.Context(), s, req)
 	if err != nil {
 		serveError(w, err)
+		return
 	}
-	res, err := h.tripUsecase.GetById(r.Context(), id)
+
+	httpjson.ServeJSON(w, res)
+}
+
+func (h *HTTPHandler) GetById(ctx context.Context, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+
+	id, err := strconv.Atoi(ps.ByName("id"))
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.tripUsecase.GetById(r.Context(), s, id)
 	if err != nil {
 		serveError(w, err)
 		return
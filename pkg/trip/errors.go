@@ -0,0 +1,59 @@
+package trip
+
+import (
+	"errors"
+
+	"github.com/wascript3r/cryptopay/pkg/errcode"
+)
+
+var (
+	// Error codes
+
+	InvalidInputError = errcode.InvalidInputError
+	UnknownError      = errcode.UnknownError
+
+	TripNotFoundError = errcode.New(
+		"trip_not_found",
+		errors.New("trip not found"),
+	)
+
+	ReservationNotFoundError = errcode.New(
+		"reservation_not_found",
+		errors.New("reservation not found"),
+	)
+
+	ReservationNotOwnedError = errcode.New(
+		"reservation_not_owned",
+		errors.New("reservation is not owned by this user"),
+	)
+
+	ReservationNotActiveError = errcode.New(
+		"reservation_not_active",
+		errors.New("reservation is not active"),
+	)
+
+	TripNotOwnedError = errcode.New(
+		"trip_not_owned",
+		errors.New("trip is not owned by this user"),
+	)
+
+	TripAlreadyEndedError = errcode.New(
+		"trip_already_ended",
+		errors.New("trip is already ended"),
+	)
+
+	TripNotEndedError = errcode.New(
+		"trip_not_ended",
+		errors.New("trip is not ended"),
+	)
+
+	TripAlreadyPaidError = errcode.New(
+		"trip_already_paid",
+		errors.New("trip is already paid"),
+	)
+
+	InsufficientBalanceError = errcode.New(
+		"insufficient_balance",
+		errors.New("insufficient balance, trip payment is pending"),
+	)
+)
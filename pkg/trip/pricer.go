@@ -0,0 +1,11 @@
+package trip
+
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+type Pricer interface {
+	Calculate(t *domain.Tariff, begin, end time.Time, distance float64) (*domain.PriceBreakdown, error)
+}
@@ -0,0 +1,125 @@
+package pricer
+
+import (
+	"math"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
+
+const (
+	minutesInHour = 60
+	minutesInDay  = 24 * minutesInHour
+)
+
+type timeCost struct {
+	days    int
+	hours   int
+	minutes int
+	price   int64
+}
+
+type Pricer struct{}
+
+func New() Pricer {
+	return Pricer{}
+}
+
+func billedMinutes(d time.Duration) int {
+	m := int(math.Ceil(d.Minutes()))
+	if m < 1 {
+		return 1
+	}
+	return m
+}
+
+// hourCost picks the cheapest way to bill the given minutes using hour and minute tariffs.
+// Rounding up to a whole hour is allowed when it is cheaper than per-minute billing.
+func hourCost(t *domain.Tariff, minutes int) timeCost {
+	best := timeCost{minutes: minutes, price: int64(minutes) * t.MinutePrice}
+	if t.HourPrice <= 0 || minutes == 0 {
+		return best
+	}
+
+	full := minutes / minutesInHour
+	rest := minutes % minutesInHour
+
+	candidates := []timeCost{
+		{hours: full, minutes: rest, price: int64(full)*t.HourPrice + int64(rest)*t.MinutePrice},
+	}
+	if rest > 0 {
+		candidates = append(candidates, timeCost{hours: full + 1, price: int64(full+1) * t.HourPrice})
+	}
+
+	for _, c := range candidates {
+		if c.price < best.price {
+			best = c
+		}
+	}
+	return best
+}
+
+// dayCost picks the cheapest combination of day, hour and minute tariffs.
+func dayCost(t *domain.Tariff, minutes int) timeCost {
+	best := hourCost(t, minutes)
+	if t.DayPrice <= 0 {
+		return best
+	}
+
+	maxDays := (minutes + minutesInDay - 1) / minutesInDay
+	for d := 1; d <= maxDays; d++ {
+		rest := minutes - d*minutesInDay
+		if rest < 0 {
+			rest = 0
+		}
+
+		c := hourCost(t, rest)
+		c.days = d
+		c.price += int64(d) * t.DayPrice
+
+		if c.price < best.price {
+			best = c
+		}
+	}
+	return best
+}
+
+func (p Pricer) Calculate(t *domain.Tariff, begin, end time.Time, distance float64) (*domain.PriceBreakdown, error) {
+	duration := end.Sub(begin)
+	if distance < 0 {
+		distance = 0
+	}
+
+	tc := dayCost(t, billedMinutes(duration))
+	timePrice := domain.NewMoney(tc.price, t.Currency)
+	distancePrice, err := domain.NewMoney(t.KilometerPrice, t.Currency).MulFloat(distance)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := timePrice.Add(distancePrice)
+	if err != nil {
+		return nil, err
+	}
+
+	var caps []domain.PriceCap
+	if tc.hours > 0 {
+		caps = append(caps, domain.HourPriceCap)
+	}
+	if tc.days > 0 {
+		caps = append(caps, domain.DayPriceCap)
+	}
+
+	return &domain.PriceBreakdown{
+		Duration:      duration,
+		Distance:      distance,
+		Days:          tc.days,
+		Hours:         tc.hours,
+		Minutes:       tc.minutes,
+		TimePrice:     timePrice,
+		DistancePrice: distancePrice,
+		Discount:      domain.NewMoney(0, t.Currency),
+		Total:         total,
+		Caps:          caps,
+	}, nil
+}
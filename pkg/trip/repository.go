@@ -2,12 +2,35 @@ package trip
 
 import (
 	"context"
-	"github.com/wascript3r/autonuoma/pkg/domain"
 	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
 )
 
 type Repository interface {
+	NewTx(ctx context.Context) (repository.Transaction, error)
+
 	Start(ctx context.Context, endLng string, endLat string, reservationID int) (int, time.Time, error)
-	End(ctx context.Context, tripID int, price float32) error
+	StartTx(ctx context.Context, tx repository.Transaction, endLng string, endLat string, reservationID int) (int, time.Time, error)
+
+	GetTrack(ctx context.Context, tripID int) ([]domain.Point, error)
+	GetTrackTx(ctx context.Context, tx repository.Transaction, tripID int) ([]domain.Point, error)
+
+	End(ctx context.Context, tripID int, end time.Time, to *domain.Point, pb *domain.PriceBreakdown) error
+	EndTx(ctx context.Context, tx repository.Transaction, tripID int, end time.Time, to *domain.Point, pb *domain.PriceBreakdown) error
+
+	MoveCar(ctx context.Context, carID int, p domain.Point) error
+	MoveCarTx(ctx context.Context, tx repository.Transaction, carID int, p domain.Point) error
+
+	GetMeta(ctx context.Context, tripID int) (*domain.TripMeta, error)
+	GetMetaTx(ctx context.Context, tx repository.Transaction, tripID int) (*domain.TripMeta, error)
+
+	AddPayment(ctx context.Context, tripID, uid int, amount domain.Money, status domain.PaymentStatus) error
+	AddPaymentTx(ctx context.Context, tx repository.Transaction, tripID, uid int, amount domain.Money, status domain.PaymentStatus) error
+
+	SetPaymentStatus(ctx context.Context, tripID int, status domain.PaymentStatus) error
+	SetPaymentStatusTx(ctx context.Context, tx repository.Transaction, tripID int, status domain.PaymentStatus) error
+
 	GetByReservationId(ctx context.Context, reservationID int) (*domain.Trip, error)
 }
@@ -3,16 +3,33 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"strings"
+	"time"
+
 	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
 	"github.com/wascript3r/autonuoma/pkg/repository/pgsql"
-	"time"
 )
 
 const (
-	startTripSQL   = "INSERT INTO kelionės (pradžios_laikas, pabaigos_taško_ilguma, pabaigos_taško_platuma, fk_rezervacija, kaina) VALUES ($1, $2, $3, $4, 0) RETURNING id, pradžios_laikas"
-	endTripSQL     = "UPDATE kelionės SET pabaigos_laikas = $2, kaina = $3 WHERE id = $1"
-	getTripByIdSQL = "SELECT pradžios_laikas, pabaigos_taško_platuma, pabaigos_taško_ilguma, id, fk_rezervacija FROM kelionės WHERE fk_rezervacija = $1"
+	startTripSQL = "INSERT INTO kelionės (pradžios_laikas, pradžios_taško_platuma, pradžios_taško_ilguma, pabaigos_taško_ilguma, pabaigos_taško_platuma, fk_rezervacija, kaina) SELECT $1, a.pozicijos_platuma, a.pozicijos_ilguma, $2, $3, r.id, 0 FROM rezervacijos r INNER JOIN automobiliai a ON (a.id = r.fk_automobilis) WHERE r.id = $4 RETURNING id, pradžios_laikas"
+	// Trips ended without a final position reported by the car are flagged for review
+	endTripSQL     = "UPDATE kelionės SET pabaigos_laikas = $2, kaina = $3, laiko_kaina = $4, atstumo_kaina = $5, valiuta = $10, nuolaida = $11, fk_akcija = $12, atstumas = $6, taikyti_limitai = $7, pabaigos_taško_platuma = COALESCE($8, pabaigos_taško_platuma), pabaigos_taško_ilguma = COALESCE($9, pabaigos_taško_ilguma), be_telemetrijos = ($8 IS NULL) WHERE id = $1"
+	getTripByIdSQL = "SELECT k.pradžios_laikas, k.pabaigos_taško_platuma, k.pabaigos_taško_ilguma, k.id, k.fk_rezervacija, r.fk_vartotojas, k.atstumas, k.kaina, k.valiuta, k.be_telemetrijos FROM kelionės k INNER JOIN rezervacijos r ON (r.id = k.fk_rezervacija) WHERE k.fk_rezervacija = $1"
+
+	getMetaSQL          = "SELECT r.id, r.fk_vartotojas, k.pradžios_laikas, k.pabaigos_laikas, k.pradžios_taško_platuma, k.pradžios_taško_ilguma, k.pabaigos_taško_platuma, k.pabaigos_taško_ilguma, k.kaina, k.valiuta, k.apmokėjimo_būsena, r.fk_akcija, a.id, a.minutės_kaina, a.valandos_kaina, a.paros_kaina, a.kilometro_kaina, a.valiuta FROM kelionės k INNER JOIN rezervacijos r ON (r.id = k.fk_rezervacija) INNER JOIN automobiliai a ON (a.id = r.fk_automobilis) WHERE k.id = $1"
+	getMetaForUpdateSQL = getMetaSQL + " FOR UPDATE OF k"
+
+	// Trip charges are stored as negative sums, top-ups as positive ones
+	addPaymentSQL           = "INSERT INTO mokėjimai (suma, valiuta, būsena, sukurta, fk_vartotojas, fk_kelione) VALUES ($3, $4, $5, $6, $2, $1)"
+	setTripPaymentStatusSQL = "UPDATE kelionės SET apmokėjimo_būsena = $2 WHERE id = $1"
+	setPaymentStatusSQL     = "UPDATE mokėjimai SET būsena = $2 WHERE fk_kelione = $1"
+
+	// Track points are recorded from the telemetry and track batches reported by the car
+	getTrackSQL = "SELECT platuma, ilguma FROM kelionių_taškai WHERE fk_kelione = $1 ORDER BY užfiksuota ASC"
+	moveCarSQL  = "UPDATE automobiliai SET pozicijos_platuma = $2, pozicijos_ilguma = $3 WHERE id = $1"
+
+	capsSeparator = ","
 )
 
 type PgRepo struct {
@@ -23,23 +40,311 @@ func NewPgRepo(c *sql.DB) *PgRepo {
 	return &PgRepo{c}
 }
 
+func (p *PgRepo) NewTx(ctx context.Context) (repository.Transaction, error) {
+	return p.conn.BeginTx(ctx, nil)
+}
+
+func (p *PgRepo) start(ctx context.Context, q pgsql.Querier, endLng string, endLat string, reservationID int) (int, time.Time, error) {
+	var (
+		tripID    int
+		createdAt time.Time
+	)
+
+	err := q.QueryRowContext(ctx, startTripSQL, time.Now(), endLng, endLat, reservationID).Scan(&tripID, &createdAt)
+	if err != nil {
+		return 0, time.Time{}, pgsql.ParseSQLError(err)
+	}
+
+	return tripID, createdAt, nil
+}
+
 func (p *PgRepo) Start(ctx context.Context, endLng string, endLat string, reservationID int) (int, time.Time, error) {
-	fmt.Println("trip start")
-	var tripID int
-	var createdAt time.Time
-	err := p.conn.QueryRowContext(ctx, startTripSQL, time.Now(), endLng, endLat, reservationID).Scan(&tripID, &createdAt)
+	return p.start(ctx, p.conn, endLng, endLat, reservationID)
+}
+
+func (p *PgRepo) StartTx(ctx context.Context, tx repository.Transaction, endLng string, endLat string, reservationID int) (int, time.Time, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return 0, time.Time{}, repository.ErrTxMismatch
+	}
 
-	fmt.Println(err)
+	tripID, createdAt, err := p.start(ctx, sqlTx, endLng, endLat, reservationID)
 	if err != nil {
-		return 0, time.Now(), err
+		sqlTx.Rollback()
+		return 0, time.Time{}, err
 	}
+
 	return tripID, createdAt, nil
 }
 
-func (p *PgRepo) End(ctx context.Context, tripID int, price float32) error {
-	if err := p.conn.QueryRowContext(ctx, endTripSQL, tripID, time.Now(), price).Err(); err != nil {
-		return pgsql.ParseSQLError(err)
+func encodeCaps(caps []domain.PriceCap) string {
+	s := make([]string, len(caps))
+	for i, c := range caps {
+		s[i] = string(c)
+	}
+	return strings.Join(s, capsSeparator)
+}
+
+func (p *PgRepo) end(ctx context.Context, q pgsql.Querier, tripID int, end time.Time, to *domain.Point, pb *domain.PriceBreakdown) error {
+	var toLat, toLng *float64
+	if to != nil {
+		toLat, toLng = &to.Lat, &to.Lng
+	}
+
+	var promoID *int
+	if pb.Promotion != nil {
+		promoID = &pb.Promotion.ID
+	}
+
+	_, err := q.ExecContext(
+		ctx,
+		endTripSQL,
+
+		tripID,
+		end,
+		pb.Total.Amount,
+		pb.TimePrice.Amount,
+		pb.DistancePrice.Amount,
+		pb.Distance,
+		encodeCaps(pb.Caps),
+		toLat,
+		toLng,
+		pb.Total.Currency,
+		pb.Discount.Amount,
+		promoID,
+	)
+	return err
+}
+
+func (p *PgRepo) End(ctx context.Context, tripID int, end time.Time, to *domain.Point, pb *domain.PriceBreakdown) error {
+	return p.end(ctx, p.conn, tripID, end, to, pb)
+}
+
+func (p *PgRepo) EndTx(ctx context.Context, tx repository.Transaction, tripID int, end time.Time, to *domain.Point, pb *domain.PriceBreakdown) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := p.end(ctx, sqlTx, tripID, end, to, pb)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (p *PgRepo) getTrack(ctx context.Context, q pgsql.Querier, tripID int) ([]domain.Point, error) {
+	rows, err := q.QueryContext(ctx, getTrackSQL, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ps []domain.Point
+
+	for rows.Next() {
+		var pt domain.Point
+
+		err := rows.Scan(&pt.Lat, &pt.Lng)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ps = append(ps, pt)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+func (p *PgRepo) GetTrack(ctx context.Context, tripID int) ([]domain.Point, error) {
+	return p.getTrack(ctx, p.conn, tripID)
+}
+
+func (p *PgRepo) GetTrackTx(ctx context.Context, tx repository.Transaction, tripID int) ([]domain.Point, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	ps, err := p.getTrack(ctx, sqlTx, tripID)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+func (p *PgRepo) moveCar(ctx context.Context, q pgsql.Querier, carID int, pt domain.Point) error {
+	_, err := q.ExecContext(ctx, moveCarSQL, carID, pt.Lat, pt.Lng)
+	return err
+}
+
+func (p *PgRepo) MoveCar(ctx context.Context, carID int, pt domain.Point) error {
+	return p.moveCar(ctx, p.conn, carID, pt)
+}
+
+func (p *PgRepo) MoveCarTx(ctx context.Context, tx repository.Transaction, carID int, pt domain.Point) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
 	}
+
+	err := p.moveCar(ctx, sqlTx, carID, pt)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func toPoint(lat, lng *float64) *domain.Point {
+	if lat == nil || lng == nil {
+		return nil
+	}
+	return &domain.Point{Lat: *lat, Lng: *lng}
+}
+
+func (p *PgRepo) getMeta(ctx context.Context, q pgsql.Querier, tripID int, forUpdate bool) (*domain.TripMeta, error) {
+	var (
+		query                          string
+		fromLat, fromLng, toLat, toLng *float64
+		price                          *int64
+		currency                       *domain.Currency
+	)
+
+	if forUpdate {
+		query = getMetaForUpdateSQL
+	} else {
+		query = getMetaSQL
+	}
+
+	m := &domain.TripMeta{
+		Car: &domain.Car{},
+	}
+
+	err := q.QueryRowContext(ctx, query, tripID).Scan(
+		&m.ReservationID,
+		&m.UserID,
+		&m.Begin,
+		&m.End,
+
+		&fromLat,
+		&fromLng,
+		&toLat,
+		&toLng,
+
+		&price,
+		&currency,
+		&m.PaymentStatus,
+		&m.PromotionID,
+
+		&m.Car.ID,
+		&m.Car.MinutePrice.Amount,
+		&m.Car.HourPrice.Amount,
+		&m.Car.DayPrice.Amount,
+		&m.Car.KilometerPrice.Amount,
+		&m.Car.MinutePrice.Currency,
+	)
+	if err != nil {
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	m.From = toPoint(fromLat, fromLng)
+	m.To = toPoint(toLat, toLng)
+	m.Car.HourPrice.Currency = m.Car.MinutePrice.Currency
+	m.Car.DayPrice.Currency = m.Car.MinutePrice.Currency
+	m.Car.KilometerPrice.Currency = m.Car.MinutePrice.Currency
+
+	// The price is unknown until the trip is ended
+	m.Price = domain.NewMoney(0, m.Car.MinutePrice.Currency)
+	if price != nil && currency != nil {
+		m.Price = domain.NewMoney(*price, *currency)
+	}
+
+	return m, nil
+}
+
+func (p *PgRepo) GetMeta(ctx context.Context, tripID int) (*domain.TripMeta, error) {
+	return p.getMeta(ctx, p.conn, tripID, false)
+}
+
+func (p *PgRepo) GetMetaTx(ctx context.Context, tx repository.Transaction, tripID int) (*domain.TripMeta, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	m, err := p.getMeta(ctx, sqlTx, tripID, true)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (p *PgRepo) addPayment(ctx context.Context, q pgsql.Querier, tripID, uid int, amount domain.Money, status domain.PaymentStatus) error {
+	_, err := q.ExecContext(ctx, addPaymentSQL, tripID, uid, -amount.Amount, amount.Currency, status, time.Now())
+	if err != nil {
+		return pgsql.ParsePgError(err)
+	}
+
+	_, err = q.ExecContext(ctx, setTripPaymentStatusSQL, tripID, status)
+	return err
+}
+
+func (p *PgRepo) AddPayment(ctx context.Context, tripID, uid int, amount domain.Money, status domain.PaymentStatus) error {
+	return p.addPayment(ctx, p.conn, tripID, uid, amount, status)
+}
+
+func (p *PgRepo) AddPaymentTx(ctx context.Context, tx repository.Transaction, tripID, uid int, amount domain.Money, status domain.PaymentStatus) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := p.addPayment(ctx, sqlTx, tripID, uid, amount, status)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (p *PgRepo) setPaymentStatus(ctx context.Context, q pgsql.Querier, tripID int, status domain.PaymentStatus) error {
+	_, err := q.ExecContext(ctx, setPaymentStatusSQL, tripID, status)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.ExecContext(ctx, setTripPaymentStatusSQL, tripID, status)
+	return err
+}
+
+func (p *PgRepo) SetPaymentStatus(ctx context.Context, tripID int, status domain.PaymentStatus) error {
+	return p.setPaymentStatus(ctx, p.conn, tripID, status)
+}
+
+func (p *PgRepo) SetPaymentStatusTx(ctx context.Context, tx repository.Transaction, tripID int, status domain.PaymentStatus) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := p.setPaymentStatus(ctx, sqlTx, tripID, status)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
 	return nil
 }
 
@@ -47,8 +352,7 @@ func (p *PgRepo) GetByReservationId(ctx context.Context, reservationID int) (*do
 	t := &domain.Trip{}
 	t.ReservationID = reservationID
 
-	err := p.conn.QueryRowContext(ctx, getTripByIdSQL, reservationID).Scan(&t.Begin, &t.EndLat, &t.EndLng, &t.ID, &t.ReservationID)
-	fmt.Println(err)
+	err := p.conn.QueryRowContext(ctx, getTripByIdSQL, reservationID).Scan(&t.Begin, &t.EndLat, &t.EndLng, &t.ID, &t.ReservationID, &t.UserID, &t.Distance, &t.Price.Amount, &t.Price.Currency, &t.Untracked)
 	if err != nil {
 		return nil, pgsql.ParseSQLError(err)
 	}
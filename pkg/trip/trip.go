@@ -1,6 +1,10 @@
 package trip
 
-import "time"
+import (
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+)
 
 type StartReq struct {
 	ReservationID int    `json:"reservationID" validate:"required"`
@@ -13,9 +17,44 @@ type StartRes struct {
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+// End
+
 type EndReq struct {
-	TripID int     `json:"tripID" validate:"required"`
-	Price  float32 `json:"price" validate:"required"`
+	TripID int `json:"tripID" validate:"required"`
+}
+
+type PriceInfo struct {
+	Days          int               `json:"days"`
+	Hours         int               `json:"hours"`
+	Minutes       int               `json:"minutes"`
+	Distance      float64           `json:"distance"`
+	TimePrice     domain.Money      `json:"timePrice"`
+	DistancePrice domain.Money      `json:"distancePrice"`
+	Discount      domain.Money      `json:"discount"`
+	PromoCode     *string           `json:"promoCode"`
+	Total         domain.Money      `json:"total"`
+	Caps          []domain.PriceCap `json:"caps"`
+}
+
+type EndRes struct {
+	TripID  int          `json:"tripID"`
+	Begin   time.Time    `json:"begin"`
+	End     time.Time    `json:"end"`
+	Price   *PriceInfo   `json:"price"`
+	Balance domain.Money `json:"balance"`
+	Receipt string       `json:"receipt"`
+}
+
+// Pay
+
+type PayReq struct {
+	TripID int `json:"tripID" validate:"required"`
+}
+
+type PayRes struct {
+	TripID  int          `json:"tripID"`
+	Price   domain.Money `json:"price"`
+	Balance domain.Money `json:"balance"`
 }
 
 type GetReq struct {
@@ -2,11 +2,13 @@ package trip
 
 import (
 	"context"
+
 	"github.com/wascript3r/autonuoma/pkg/domain"
 )
 
 type Usecase interface {
-	Start(ctx context.Context, req *StartReq) (*StartRes, error)
-	End(ctx context.Context, req *EndReq) error
-	GetById(ctx context.Context, reservationID int) (*domain.Trip, error)
+	Start(ctx context.Context, ss *domain.Session, req *StartReq) (*StartRes, error)
+	End(ctx context.Context, ss *domain.Session, req *EndReq) (*EndRes, error)
+	Pay(ctx context.Context, ss *domain.Session, req *PayReq) (*PayRes, error)
+	GetById(ctx context.Context, ss *domain.Session, reservationID int) (*domain.Trip, error)
 }
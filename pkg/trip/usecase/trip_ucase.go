@@ -2,45 +2,372 @@ package usecase
 
 import (
 	"context"
-	"fmt"
+	"time"
+
 	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/ledger"
+	"github.com/wascript3r/autonuoma/pkg/promo"
+	"github.com/wascript3r/autonuoma/pkg/receipt"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+	"github.com/wascript3r/autonuoma/pkg/reservation"
 	"github.com/wascript3r/autonuoma/pkg/trip"
+	"github.com/wascript3r/autonuoma/pkg/user"
 )
 
 type Usecase struct {
-	tripRepo trip.Repository
+	tripRepo    trip.Repository
+	resRepo     reservation.Repository
+	userRepo    user.Repository
+	ledgerRepo  ledger.Repository
+	promoRepo   promo.Repository
+	receiptRepo receipt.Repository
+	ctxTimeout  time.Duration
+
+	resEventBus reservation.EventBus
+	pricer      trip.Pricer
+	validate    trip.Validate
+
+	vatRate int
 }
 
-func New(tr trip.Repository) *Usecase {
+func New(tr trip.Repository, rr reservation.Repository, ur user.Repository, lr ledger.Repository, pr promo.Repository, rcr receipt.Repository, t time.Duration, reb reservation.EventBus, p trip.Pricer, v trip.Validate, vatRate int) *Usecase {
 	return &Usecase{
-		tripRepo: tr,
+		tripRepo:    tr,
+		resRepo:     rr,
+		userRepo:    ur,
+		ledgerRepo:  lr,
+		promoRepo:   pr,
+		receiptRepo: rcr,
+		ctxTimeout:  t,
+
+		resEventBus: reb,
+		pricer:      p,
+		validate:    v,
+
+		vatRate: vatRate,
 	}
 }
 
-func (u *Usecase) Start(ctx context.Context, req *trip.StartReq) (*trip.StartRes, error) {
-	var id, createdAt, err = u.tripRepo.Start(ctx, req.EndLng, req.EndLat, req.ReservationID)
-	fmt.Println("trip start")
-	fmt.Println(err)
+func (u *Usecase) Start(ctx context.Context, ss *domain.Session, req *trip.StartReq) (*trip.StartRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, trip.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.tripRepo.NewTx(c)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	r, err := u.resRepo.GetTx(c, tx, req.ReservationID)
 	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, trip.ReservationNotFoundError
+		}
+		return nil, err
+	}
+
+	if r.UserID != ss.UserID {
+		return nil, trip.ReservationNotOwnedError
+	}
+
+	if !r.Status.CanTransition(domain.InTripReservationStatus) {
+		return nil, trip.ReservationNotActiveError
+	}
+
+	id, createdAt, err := u.tripRepo.StartTx(c, tx, req.EndLng, req.EndLat, req.ReservationID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.resRepo.SetStatusTx(c, tx, req.ReservationID, domain.InTripReservationStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
 
 	return &trip.StartRes{TripID: id, CreatedAt: createdAt}, nil
 }
 
-func (u *Usecase) End(ctx context.Context, req *trip.EndReq) error {
-	err := u.tripRepo.End(ctx, req.TripID, req.Price)
+func toPriceInfo(pb *domain.PriceBreakdown) *trip.PriceInfo {
+	caps := pb.Caps
+	if caps == nil {
+		caps = []domain.PriceCap{}
+	}
+
+	var code *string
+	if pb.Promotion != nil {
+		code = &pb.Promotion.Code
+	}
+
+	return &trip.PriceInfo{
+		Days:          pb.Days,
+		Hours:         pb.Hours,
+		Minutes:       pb.Minutes,
+		Distance:      pb.Distance,
+		TimePrice:     pb.TimePrice,
+		DistancePrice: pb.DistancePrice,
+		Discount:      pb.Discount,
+		PromoCode:     code,
+		Total:         pb.Total,
+		Caps:          caps,
+	}
+}
+
+// charge debits the trip price from the user's wallet if the balance is sufficient.
+// It returns the resulting balance and whether the amount was debited.
+func (u *Usecase) charge(ctx context.Context, tx repository.Transaction, uid, tripID int, amount domain.Money) (domain.Money, bool, error) {
+	balance, err := u.userRepo.GetBalanceTx(ctx, tx, uid)
 	if err != nil {
-		return err
+		return domain.Money{}, false, err
+	}
+
+	less, err := balance.LessThan(amount)
+	if err != nil {
+		return domain.Money{}, false, err
+	}
+	if less {
+		return balance, false, nil
 	}
-	return nil
+
+	// Free trips have nothing to post
+	if amount.IsZero() {
+		return balance, true, nil
+	}
+
+	err = u.ledgerRepo.PostTx(ctx, tx, domain.NewTripChargeEntry(uid, tripID, amount))
+	if err != nil {
+		return domain.Money{}, false, err
+	}
+
+	balance, err = balance.Sub(amount)
+	if err != nil {
+		return domain.Money{}, false, err
+	}
+
+	return balance, true, nil
 }
 
-func (u *Usecase) GetById(ctx context.Context, reservationID int) (*domain.Trip, error) {
-	t := &domain.Trip{}
-	t, err := u.tripRepo.GetByReservationId(ctx, reservationID)
+// route returns the driven distance and the final position of the trip
+// reported by the car. Without any reported points nothing is known about
+// the route, so no distance is billed and the final position is unknown.
+func route(meta *domain.TripMeta, track []domain.Point) (float64, *domain.Point) {
+	if len(track) == 0 {
+		return 0, nil
+	}
+
+	ps := track
+	if meta.From != nil {
+		ps = append([]domain.Point{*meta.From}, track...)
+	}
+
+	last := track[len(track)-1]
+	return domain.TrackDistance(ps), &last
+}
+
+func (u *Usecase) End(ctx context.Context, ss *domain.Session, req *trip.EndReq) (*trip.EndRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, trip.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.tripRepo.NewTx(c)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	meta, err := u.tripRepo.GetMetaTx(c, tx, req.TripID)
 	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, trip.TripNotFoundError
+		}
 		return nil, err
 	}
+
+	if meta.UserID != ss.UserID && !domain.IsStaff(ss) {
+		return nil, trip.TripNotOwnedError
+	}
+
+	if meta.End != nil {
+		return nil, trip.TripAlreadyEndedError
+	}
+
+	track, err := u.tripRepo.GetTrackTx(c, tx, req.TripID)
+	if err != nil {
+		return nil, err
+	}
+	distance, endPoint := route(meta, track)
+
+	end := time.Now()
+	pb, err := u.pricer.Calculate(domain.NewTariff(meta.Car), meta.Begin, end, distance)
+	if err != nil {
+		return nil, err
+	}
+
+	// The promotion was checked when the reservation was made,
+	// it is honoured even if it has expired since
+	if meta.PromotionID != nil {
+		p, err := u.promoRepo.GetByReservationTx(c, tx, meta.ReservationID)
+		if err != nil {
+			return nil, err
+		}
+		pb.ApplyPromotion(p)
+	}
+
+	err = u.tripRepo.EndTx(c, tx, req.TripID, end, endPoint, pb)
+	if err != nil {
+		return nil, err
+	}
+
+	if endPoint != nil {
+		err = u.tripRepo.MoveCarTx(c, tx, meta.Car.ID, *endPoint)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = u.resRepo.SetStatusTx(c, tx, meta.ReservationID, domain.CompletedReservationStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, paid, err := u.charge(c, tx, meta.UserID, req.TripID, pb.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.PendingPaymentStatus
+	if paid {
+		status = domain.SuccessfulPaymentStatus
+	}
+
+	err = u.tripRepo.AddPaymentTx(c, tx, req.TripID, meta.UserID, pb.Total, status)
+	if err != nil {
+		return nil, err
+	}
+
+	receiptNumber, err := u.receiptRepo.IssueTx(c, tx, req.TripID, u.vatRate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	u.resEventBus.Publish(reservation.CompletedReservationEvent, ctx, &domain.Reservation{
+		ID:     meta.ReservationID,
+		CarID:  meta.Car.ID,
+		UserID: meta.UserID,
+		Status: domain.CompletedReservationStatus,
+	})
+
+	if !paid {
+		return nil, trip.InsufficientBalanceError
+	}
+
+	return &trip.EndRes{
+		TripID:  req.TripID,
+		Begin:   meta.Begin,
+		End:     end,
+		Price:   toPriceInfo(pb),
+		Balance: balance,
+		Receipt: domain.ReceiptNumber(receiptNumber),
+	}, nil
+}
+
+func (u *Usecase) Pay(ctx context.Context, ss *domain.Session, req *trip.PayReq) (*trip.PayRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, trip.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.tripRepo.NewTx(c)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	meta, err := u.tripRepo.GetMetaTx(c, tx, req.TripID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, trip.TripNotFoundError
+		}
+		return nil, err
+	}
+
+	if meta.UserID != ss.UserID {
+		return nil, trip.TripNotOwnedError
+	}
+
+	if meta.End == nil || meta.PaymentStatus == nil {
+		return nil, trip.TripNotEndedError
+	}
+
+	if *meta.PaymentStatus != domain.PendingPaymentStatus {
+		return nil, trip.TripAlreadyPaidError
+	}
+
+	balance, paid, err := u.charge(c, tx, meta.UserID, req.TripID, meta.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	if !paid {
+		return nil, trip.InsufficientBalanceError
+	}
+
+	err = u.tripRepo.SetPaymentStatusTx(c, tx, req.TripID, domain.SuccessfulPaymentStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &trip.PayRes{
+		TripID:  req.TripID,
+		Price:   meta.Price,
+		Balance: balance,
+	}, nil
+}
+
+func (u *Usecase) GetById(ctx context.Context, ss *domain.Session, reservationID int) (*domain.Trip, error) {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	t, err := u.tripRepo.GetByReservationId(c, reservationID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, trip.TripNotFoundError
+		}
+		return nil, err
+	}
+
+	if t.UserID != ss.UserID && !domain.IsStaff(ss) {
+		return nil, trip.TripNotOwnedError
+	}
+
+	track, err := u.tripRepo.GetTrack(c, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	t.Polyline = domain.EncodePolyline(track)
+
 	return t, nil
 }
@@ -0,0 +1,5 @@
+package trip
+
+type Validate interface {
+	RawRequest(s interface{}) error
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/user"
+)
+
+var statementCSVHeader = []string{"time", "type", "reference", "description", "amount", "currency", "status"}
+
+// escapeCSVCell keeps spreadsheets from evaluating text cells as formulas
+func escapeCSVCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// Amounts are formatted by the app, only the text cells are escaped
+func statementCSVRow(t time.Time, typ, ref, desc string, m domain.Money, status string) []string {
+	return []string{t.Format(time.RFC3339), escapeCSVCell(typ), escapeCSVCell(ref), escapeCSVCell(desc), m.String(), string(m.Currency), escapeCSVCell(status)}
+}
+
+// writeStatementCSV writes the statement lines framed by the opening and closing balance rows
+func writeStatementCSV(w io.Writer, res *user.StatementRes) error {
+	cw := csv.NewWriter(w)
+
+	rows := [][]string{
+		statementCSVHeader,
+		statementCSVRow(res.From, "opening_balance", "", "", res.OpeningBalance, ""),
+	}
+
+	for _, l := range res.Lines {
+		ref := ""
+		if l.Reference != nil {
+			ref = strconv.Itoa(*l.Reference)
+		}
+		rows = append(rows, statementCSVRow(l.Time, string(l.Type), ref, l.Description, l.Amount, l.Status))
+	}
+
+	rows = append(rows, statementCSVRow(res.To, "closing_balance", "", "", res.ClosingBalance, ""))
+
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -1,12 +1,18 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"mime"
+	"net"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/lockout"
+	lockoutHandler "github.com/wascript3r/autonuoma/pkg/lockout/delivery/http"
 	"github.com/wascript3r/autonuoma/pkg/session"
 	sessionHandler "github.com/wascript3r/autonuoma/pkg/session/delivery/http"
 	"github.com/wascript3r/autonuoma/pkg/user"
@@ -21,7 +27,7 @@ type HTTPHandler struct {
 	sessionMid   sessionHandler.Middleware
 }
 
-func NewHTTPHandler(ctx context.Context, r *httprouter.Router, auth *middleware.StackCtx, notAuth *middleware.Stack, uu user.Usecase, su session.Usecase, sm sessionHandler.Middleware) {
+func NewHTTPHandler(ctx context.Context, r *httprouter.Router, auth *middleware.StackCtx, notAuth *middleware.Stack, admin *middleware.StackCtx, statements *middleware.StackCtx, uu user.Usecase, su session.Usecase, sm sessionHandler.Middleware) {
 	handler := &HTTPHandler{
 		userUcase:    uu,
 		sessionUcase: su,
@@ -30,16 +36,41 @@ func NewHTTPHandler(ctx context.Context, r *httprouter.Router, auth *middleware.
 
 	r.POST("/api/user/register", notAuth.Wrap(handler.RegisterUser))
 	r.POST("/api/user/authenticate", notAuth.Wrap(handler.AuthenticateUser))
+	r.POST("/api/user/2fa/challenge/setup", notAuth.Wrap(handler.SetupTwoFactorChallenge))
+	r.POST("/api/user/2fa/verify", notAuth.Wrap(handler.VerifyTwoFactor))
+	r.POST("/api/user/password/forgot", notAuth.Wrap(handler.ForgotPassword))
+	r.POST("/api/user/password/reset", notAuth.Wrap(handler.ResetPassword))
 	r.GET("/api/user/token", auth.Wrap(ctx, handler.GetToken))
 	r.GET("/api/user/logout", auth.Wrap(ctx, handler.LogoutUser))
 	r.GET("/api/user/info", auth.Wrap(ctx, handler.UserInfo))
 	r.GET("/api/user", auth.Wrap(ctx, handler.UserData))
 	r.POST("/api/user/update", auth.Wrap(ctx, handler.UpdateUser))
-	r.GET("/api/user/trips", auth.Wrap(ctx, handler.GetTrips))
-	r.GET("/api/user/payment", auth.Wrap(ctx, handler.Payment))
+	r.POST("/api/user/email/verify", handler.VerifyEmail)
+	r.POST("/api/user/email/resend", auth.Wrap(ctx, handler.ResendVerification))
+	r.GET("/api/user/trips", statements.Wrap(ctx, handler.GetTrips))
+	r.GET("/api/user/statements", statements.Wrap(ctx, handler.GetStatement))
+	r.POST("/api/user/2fa/setup", auth.Wrap(ctx, handler.SetupTwoFactor))
+	r.POST("/api/user/2fa/enable", auth.Wrap(ctx, handler.EnableTwoFactor))
+	r.POST("/api/user/2fa/disable", auth.Wrap(ctx, handler.DisableTwoFactor))
+	r.POST("/api/user/2fa/backup-codes", auth.Wrap(ctx, handler.RegenerateBackupCodes))
+	r.POST("/api/admin/user/2fa/reset", admin.Wrap(ctx, handler.ResetTwoFactor))
+}
+
+// clientIP is the address of the direct peer, the app is not configured to trust proxy headers
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
 
 func serveError(w http.ResponseWriter, err error) {
+	if re, ok := err.(*lockout.RetryError); ok {
+		lockoutHandler.ServeRetryError(w, re)
+		return
+	}
+
 	if err == user.InvalidInputError {
 		httpjson.BadRequestCustom(w, user.InvalidInputError, nil)
 		return
@@ -80,17 +111,100 @@ func (h *HTTPHandler) AuthenticateUser(w http.ResponseWriter, r *http.Request, _
 		httpjson.BadRequest(w, nil)
 		return
 	}
+	req.IP = clientIP(r)
+	req.UserAgent = r.UserAgent()
 
 	s, res, err := h.userUcase.Authenticate(r.Context(), req)
 	if err != nil {
 		serveError(w, err)
 		return
 	}
-	h.sessionMid.SetSessionCookie(w, s)
+	if s != nil {
+		h.issueSession(w, s, res, req.Bearer)
+	}
 
 	httpjson.ServeJSON(w, res)
 }
 
+// issueSession hands the session to bearer clients in the response, to browsers in the cookie
+func (h *HTTPHandler) issueSession(w http.ResponseWriter, s *domain.Session, res *user.AuthenticateRes, bearer bool) {
+	if bearer {
+		res.Token = s.ID
+		return
+	}
+	h.sessionMid.SetSessionCookie(w, s)
+}
+
+func (h *HTTPHandler) VerifyEmail(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := &user.VerifyEmailReq{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	err = h.userUcase.VerifyEmail(r.Context(), req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, nil)
+}
+
+func (h *HTTPHandler) ResendVerification(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+
+	err = h.userUcase.ResendVerification(r.Context(), s.UserID)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, nil)
+}
+
+func (h *HTTPHandler) ForgotPassword(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := &user.ForgotPasswordReq{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	err = h.userUcase.RequestPasswordReset(r.Context(), req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, nil)
+}
+
+func (h *HTTPHandler) ResetPassword(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := &user.ResetPasswordReq{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	err = h.userUcase.ResetPassword(r.Context(), req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, nil)
+}
+
 func (h *HTTPHandler) GetToken(ctx context.Context, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	s, err := h.sessionUcase.LoadCtx(ctx)
 	if err != nil {
@@ -191,18 +305,182 @@ func (h *HTTPHandler) GetTrips(ctx context.Context, w http.ResponseWriter, _ *ht
 	httpjson.ServeJSON(w, res)
 }
 
-func (h *HTTPHandler) Payment(ctx context.Context, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+func (h *HTTPHandler) GetStatement(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+
+	q := r.URL.Query()
+	req := &user.StatementReq{
+		Month:  q.Get("month"),
+		Format: q.Get("format"),
+	}
+
+	res, err := h.userUcase.GetStatement(r.Context(), s.UserID, req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	if req.Format != user.CSVStatementFormat {
+		httpjson.ServeJSON(w, res)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := writeStatementCSV(&buf, res); err != nil {
+		serveError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "statement-" + res.Month + ".csv"}))
+	w.Write(buf.Bytes())
+}
+
+func (h *HTTPHandler) SetupTwoFactorChallenge(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := &user.TwoFactorChallengeReq{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.userUcase.SetupTwoFactorChallenge(r.Context(), req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
+
+func (h *HTTPHandler) VerifyTwoFactor(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := &user.TwoFactorVerifyReq{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+	req.IP = clientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	s, res, err := h.userUcase.VerifyTwoFactor(r.Context(), req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	h.issueSession(w, s, res.AuthenticateRes, req.Bearer)
+
+	httpjson.ServeJSON(w, res)
+}
+
+func (h *HTTPHandler) SetupTwoFactor(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+
+	res, err := h.userUcase.SetupTwoFactor(r.Context(), s.UserID)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
+
+func (h *HTTPHandler) EnableTwoFactor(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
+	if err != nil {
+		httpjson.InternalError(w, nil)
+		return
+	}
+
+	req := &user.TwoFactorCodeReq{}
+
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.userUcase.EnableTwoFactor(r.Context(), s.UserID, req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, res)
+}
+
+func (h *HTTPHandler) DisableTwoFactor(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	s, err := h.sessionUcase.LoadCtx(ctx)
 	if err != nil {
 		httpjson.InternalError(w, nil)
 		return
 	}
 
-	res, err := h.userUcase.CheckPayment(ctx, s.UserID)
+	req := &user.TwoFactorCodeReq{}
+
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	err = h.userUcase.DisableTwoFactor(r.Context(), s, req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, nil)
+}
+
+func (h *HTTPHandler) RegenerateBackupCodes(ctx context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	s, err := h.sessionUcase.LoadCtx(ctx)
 	if err != nil {
 		httpjson.InternalError(w, nil)
 		return
 	}
 
+	req := &user.TwoFactorCodeReq{}
+
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	res, err := h.userUcase.RegenerateBackupCodes(r.Context(), s.UserID, req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
 	httpjson.ServeJSON(w, res)
 }
+
+func (h *HTTPHandler) ResetTwoFactor(_ context.Context, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req := &user.ResetTwoFactorReq{}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		httpjson.BadRequest(w, nil)
+		return
+	}
+
+	err = h.userUcase.ResetTwoFactor(r.Context(), req)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	httpjson.ServeJSON(w, nil)
+}
@@ -19,10 +19,12 @@ import (
 var (
 	AuthenticatedRoom = pool.NewRoomConfig("auth", false)
 	AgentRoom         = pool.NewRoomConfig("agent", false)
+	CarsLiveRoom      = pool.NewRoomConfig("cars:live", false)
 
 	RoomConfigs = map[domain.Room]*pool.RoomConfig{
 		domain.AuthenticatedRoom: AuthenticatedRoom,
 		domain.AgentRoom:         AgentRoom,
+		domain.CarsLiveRoom:      CarsLiveRoom,
 	}
 )
 
@@ -23,4 +23,54 @@ var (
 		"invalid_credentials",
 		errors.New("invalid credentials"),
 	)
+
+	EmailAlreadyVerifiedError = errcode.New(
+		"email_already_verified",
+		errors.New("email is already verified"),
+	)
+
+	InvalidVerificationTokenError = errcode.New(
+		"invalid_verification_token",
+		errors.New("email verification token is invalid or expired"),
+	)
+
+	UserNotFoundError = errcode.New(
+		"user_not_found",
+		errors.New("user not found"),
+	)
+
+	InvalidLoginChallengeError = errcode.New(
+		"invalid_login_challenge",
+		errors.New("login challenge is invalid or expired"),
+	)
+
+	InvalidTwoFactorCodeError = errcode.New(
+		"invalid_two_factor_code",
+		errors.New("two-factor authentication code is invalid"),
+	)
+
+	TwoFactorAlreadyEnabledError = errcode.New(
+		"two_factor_already_enabled",
+		errors.New("two-factor authentication is already enabled"),
+	)
+
+	TwoFactorNotEnabledError = errcode.New(
+		"two_factor_not_enabled",
+		errors.New("two-factor authentication is not enabled"),
+	)
+
+	TwoFactorNotSetUpError = errcode.New(
+		"two_factor_not_set_up",
+		errors.New("two-factor authentication secret is not generated"),
+	)
+
+	TwoFactorRequiredError = errcode.New(
+		"two_factor_required",
+		errors.New("two-factor authentication is required for the role"),
+	)
+
+	InvalidResetTokenError = errcode.New(
+		"invalid_reset_token",
+		errors.New("password reset token is invalid or expired"),
+	)
 )
@@ -0,0 +1,11 @@
+package user
+
+import "time"
+
+type OTP interface {
+	GenerateSecret() (string, error)
+	// URI is the provisioning URI authenticator apps read from a QR code
+	URI(secret, account string) string
+	// Validate returns the time step the code was generated for
+	Validate(secret, code string, t time.Time) (int64, bool)
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/wascript3r/autonuoma/pkg/domain"
 	"github.com/wascript3r/autonuoma/pkg/repository"
@@ -11,22 +12,55 @@ type Repository interface {
 	NewTx(ctx context.Context) (repository.Transaction, error)
 
 	InsertIfNotExists(ctx context.Context, us *domain.User) error
+	InsertIfNotExistsTx(ctx context.Context, tx repository.Transaction, us *domain.User) error
 	EmailExists(ctx context.Context, email string) (bool, error)
 	GetCredentials(ctx context.Context, email string) (*domain.UserCredentials, error)
 
-	DeductBalance(ctx context.Context, id int, value int64) error
-	DeductBalanceTx(ctx context.Context, tx repository.Transaction, id int, value int64) error
-
-	AddBalance(ctx context.Context, id int, value int64) error
-	AddBalanceTx(ctx context.Context, tx repository.Transaction, id int, value int64) error
+	GetBalance(ctx context.Context, id int) (domain.Money, error)
+	GetBalanceTx(ctx context.Context, tx repository.Transaction, id int) (domain.Money, error)
 
 	GetData(ctx context.Context, uid int) (*UserProfile, error)
 	GetLicenseStatus(ctx context.Context, uid int) (string, error)
 
-	UpdateEmail(ctx context.Context, uid int, email string) error
 	UpdatePassword(ctx context.Context, uid int, hash string) error
+	UpdatePasswordTx(ctx context.Context, tx repository.Transaction, uid int, hash string) error
+
+	// InsertPasswordResetTx also invalidates the unused tokens issued to the user before
+	InsertPasswordResetTx(ctx context.Context, tx repository.Transaction, r *domain.PasswordReset) error
+	GetPasswordResetTx(ctx context.Context, tx repository.Transaction, tokenHash string) (*domain.PasswordReset, error)
+	UsePasswordResetTx(ctx context.Context, tx repository.Transaction, resetID int, t time.Time) error
+
+	// InsertEmailVerificationTx also invalidates the unused tokens issued to the user before
+	InsertEmailVerificationTx(ctx context.Context, tx repository.Transaction, v *domain.EmailVerification) error
+	GetEmailVerificationTx(ctx context.Context, tx repository.Transaction, tokenHash string) (*domain.EmailVerification, error)
+	UseEmailVerificationTx(ctx context.Context, tx repository.Transaction, verificationID int, t time.Time) error
+	// VerifyEmailTx sets the verified account email, returns domain.ErrExists if another account uses it
+	VerifyEmailTx(ctx context.Context, tx repository.Transaction, uid int, email string, t time.Time) error
+
+	GetTwoFactor(ctx context.Context, uid int) (*domain.TwoFactor, error)
+	GetTwoFactorTx(ctx context.Context, tx repository.Transaction, uid int) (*domain.TwoFactor, error)
+	// SetTwoFactorSecret returns domain.ErrExists if two-factor authentication is already enabled
+	SetTwoFactorSecret(ctx context.Context, uid int, secret []byte) error
+	EnableTwoFactorTx(ctx context.Context, tx repository.Transaction, uid int, step int64, t time.Time) error
+	SetTwoFactorStepTx(ctx context.Context, tx repository.Transaction, uid int, step int64) error
+	// DisableTwoFactorTx also deletes the backup codes
+	DisableTwoFactorTx(ctx context.Context, tx repository.Transaction, uid int) error
+	ReplaceBackupCodesTx(ctx context.Context, tx repository.Transaction, uid int, hashes []string) error
+	// UseBackupCodeTx returns domain.ErrNotFound without rolling back when the code is not usable
+	UseBackupCodeTx(ctx context.Context, tx repository.Transaction, uid int, hash string, t time.Time) error
+
+	InsertLoginChallenge(ctx context.Context, c *domain.LoginChallenge) error
+	GetLoginChallengeTx(ctx context.Context, tx repository.Transaction, tokenHash string) (*domain.LoginChallenge, error)
+	IncLoginChallengeAttemptsTx(ctx context.Context, tx repository.Transaction, challengeID int) error
+	DeleteLoginChallengeTx(ctx context.Context, tx repository.Transaction, challengeID int) error
+
+	DeleteSessionsTx(ctx context.Context, tx repository.Transaction, uid int) ([]string, error)
 
 	GetTrips(ctx context.Context, uid int) ([]*domain.UserTrip, error)
 
-	AddPayment(ctx context.Context, uid int, amount int64) error
+	// Statement periods include from and exclude to
+	GetTripsBetween(ctx context.Context, uid int, from, to time.Time) ([]*domain.UserTrip, error)
+	GetTopUpsBetween(ctx context.Context, uid int, from, to time.Time) ([]*domain.UserPayment, error)
+	GetWalletEntriesBetween(ctx context.Context, uid int, from, to time.Time) ([]*domain.WalletEntry, error)
+	GetBalanceAt(ctx context.Context, uid int, t time.Time) (domain.Money, error)
 }
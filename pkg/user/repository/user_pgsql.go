@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"strings"
 	"time"
 
@@ -14,17 +13,48 @@ import (
 )
 
 const (
-	insertIfNotExistsSQL = "INSERT INTO vartotojai (vardas, pavardė, el_paštas, gimimo_data, slaptažodis, balansas, asmens_kodas, rolė) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
+	insertIfNotExistsSQL = "INSERT INTO vartotojai (vardas, pavardė, el_paštas, gimimo_data, slaptažodis, balansas, valiuta, asmens_kodas, rolė) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
 	emailExistsSQL       = "SELECT EXISTS(SELECT 1 FROM vartotojai WHERE el_paštas = $1)"
-	getCredentialsSQL    = "SELECT id, rolė, slaptažodis FROM vartotojai WHERE el_paštas = $1"
-	deductBalanceSQL     = "UPDATE vartotojai SET balansas = balansas - $2 WHERE id = $1"
-	addBalanceSQL        = "UPDATE vartotojai SET balansas = balansas + $2 WHERE id = $1"
-	getDataSQL           = "SELECT vardas, pavardė, el_paštas, gimimo_data, balansas FROM vartotojai WHERE id = $1"
+	getCredentialsSQL    = "SELECT id, rolė, slaptažodis, totp_patvirtinta IS NOT NULL FROM vartotojai WHERE el_paštas = $1"
+	getDataSQL           = "SELECT v.vardas, v.pavardė, v.el_paštas, v.el_paštas_patvirtintas IS NOT NULL, (SELECT p.el_paštas FROM el_pašto_patvirtinimai p WHERE p.fk_vartotojas = v.id AND p.panaudota IS NULL AND p.galiojimo_pabaiga > now() AND p.el_paštas <> v.el_paštas ORDER BY p.id DESC LIMIT 1), v.gimimo_data, v.balansas, v.valiuta, (SELECT COALESCE(SUM(-m.suma), 0) FROM mokėjimai m WHERE m.fk_vartotojas = v.id AND m.fk_kelione IS NOT NULL AND m.būsena = $2) FROM vartotojai v WHERE v.id = $1"
 	getLicenseStatusSQL  = "SELECT b.name, p.galiojimo_pabaiga FROM vairuotojo_pažymėjimai p INNER JOIN vairuotojo_pažymėjimo_būsenos b ON (b.id = p.būsena) WHERE p.fk_vartotojas = $1 ORDER BY p.id DESC LIMIT 1"
-	updateEmailSQL       = "UPDATE vartotojai SET el_paštas = $2 WHERE id = $1"
 	updatePasswordSQL    = "UPDATE vartotojai SET slaptažodis = $2 WHERE id = $1"
-	getTripsSQL          = "SELECT k.id, k.pradžios_laikas, k.pabaigos_laikas, k.kaina FROM kelionės k, rezervacijos r WHERE k.fk_rezervacija = r.id AND r.fk_vartotojas = $1 AND k.pabaigos_laikas IS NOT NULL"
-	addPaymentSQL        = "INSERT INTO mokėjimai (suma, būsena, fk_vartotojas) VALUES ($1, $2, $3)"
+	getTripsSQL          = "SELECT k.id, k.pradžios_laikas, k.pabaigos_laikas, k.kaina, k.valiuta, b.name, r.pradzios_adresas, r.pabaigos_adresas, k.pradžios_taško_platuma, k.pradžios_taško_ilguma, k.pabaigos_taško_platuma, k.pabaigos_taško_ilguma FROM kelionės k INNER JOIN rezervacijos r ON (r.id = k.fk_rezervacija) LEFT JOIN mokėjimo_būsenos b ON (b.id = k.apmokėjimo_būsena) WHERE r.fk_vartotojas = $1 AND k.pabaigos_laikas IS NOT NULL"
+
+	insertPasswordResetSQL           = "WITH a AS (UPDATE slaptažodžio_atkūrimai SET panaudota = $4 WHERE fk_vartotojas = $1 AND panaudota IS NULL) INSERT INTO slaptažodžio_atkūrimai (fk_vartotojas, žetono_maiša, galiojimo_pabaiga) VALUES ($1, $2, $3) RETURNING id"
+	getPasswordResetForUpdateSQL     = "SELECT a.id, a.fk_vartotojas, v.el_paštas, a.žetono_maiša, a.galiojimo_pabaiga, a.panaudota FROM slaptažodžio_atkūrimai a INNER JOIN vartotojai v ON (v.id = a.fk_vartotojas) WHERE a.žetono_maiša = $1 FOR UPDATE OF a"
+	usePasswordResetSQL              = "UPDATE slaptažodžio_atkūrimai SET panaudota = $2 WHERE id = $1"
+	insertEmailVerificationSQL       = "WITH a AS (UPDATE el_pašto_patvirtinimai SET panaudota = $5 WHERE fk_vartotojas = $1 AND panaudota IS NULL) INSERT INTO el_pašto_patvirtinimai (fk_vartotojas, el_paštas, žetono_maiša, galiojimo_pabaiga) VALUES ($1, $2, $3, $4) RETURNING id"
+	getEmailVerificationForUpdateSQL = "SELECT id, fk_vartotojas, el_paštas, žetono_maiša, galiojimo_pabaiga, panaudota FROM el_pašto_patvirtinimai WHERE žetono_maiša = $1 FOR UPDATE"
+	useEmailVerificationSQL          = "UPDATE el_pašto_patvirtinimai SET panaudota = $2 WHERE id = $1"
+	verifyEmailSQL                   = "UPDATE vartotojai SET el_paštas = $2, el_paštas_patvirtintas = $3 WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM vartotojai WHERE el_paštas = $2 AND id <> $1)"
+
+	getTwoFactorSQL          = "SELECT id, el_paštas, rolė, totp_paslaptis, totp_patvirtinta, totp_žingsnis FROM vartotojai WHERE id = $1"
+	getTwoFactorForUpdateSQL = getTwoFactorSQL + " FOR UPDATE"
+	setTwoFactorSecretSQL    = "UPDATE vartotojai SET totp_paslaptis = $2 WHERE id = $1 AND totp_patvirtinta IS NULL"
+	enableTwoFactorSQL       = "UPDATE vartotojai SET totp_patvirtinta = $3, totp_žingsnis = $2 WHERE id = $1"
+	setTwoFactorStepSQL      = "UPDATE vartotojai SET totp_žingsnis = $2 WHERE id = $1"
+	disableTwoFactorSQL      = "UPDATE vartotojai SET totp_paslaptis = NULL, totp_patvirtinta = NULL, totp_žingsnis = 0 WHERE id = $1"
+	deleteBackupCodesSQL     = "DELETE FROM atsarginiai_kodai WHERE fk_vartotojas = $1"
+	insertBackupCodeSQL      = "INSERT INTO atsarginiai_kodai (fk_vartotojas, kodo_maiša) VALUES ($1, $2)"
+	useBackupCodeSQL         = "UPDATE atsarginiai_kodai SET panaudota = $3 WHERE fk_vartotojas = $1 AND kodo_maiša = $2 AND panaudota IS NULL"
+
+	insertLoginChallengeSQL       = "INSERT INTO prisijungimo_iššūkiai (fk_vartotojas, žetono_maiša, galiojimo_pabaiga) VALUES ($1, $2, $3) RETURNING id"
+	getLoginChallengeForUpdateSQL = "SELECT id, fk_vartotojas, žetono_maiša, galiojimo_pabaiga, bandymai FROM prisijungimo_iššūkiai WHERE žetono_maiša = $1 FOR UPDATE"
+	incLoginChallengeAttemptsSQL  = "UPDATE prisijungimo_iššūkiai SET bandymai = bandymai + 1 WHERE id = $1"
+	deleteLoginChallengeSQL       = "DELETE FROM prisijungimo_iššūkiai WHERE id = $1"
+
+	deleteSessionsSQL = "DELETE FROM sesijos WHERE fk_vartotojas = $1 RETURNING id"
+
+	getTripsBetweenSQL = getTripsSQL + " AND k.pabaigos_laikas >= $2 AND k.pabaigos_laikas < $3 ORDER BY k.pabaigos_laikas ASC"
+
+	// Trip payments are part of the trips, only top-ups are listed
+	getTopUpsBetweenSQL        = "SELECT m.id, m.sukurta, m.suma, m.valiuta, COALESCE(m.tiekėjas, ''), b.name FROM mokėjimai m INNER JOIN mokėjimo_būsenos b ON (b.id = m.būsena) WHERE m.fk_vartotojas = $1 AND m.fk_kelione IS NULL AND m.sukurta >= $2 AND m.sukurta < $3 ORDER BY m.sukurta ASC"
+	getWalletEntriesBetweenSQL = "SELECT j.id, j.tipas, j.aprašymas, j.sukurta, j.fk_kelione, j.fk_mokejimas, e.suma, e.valiuta, e.likutis FROM žurnalo_eilutės e INNER JOIN žurnalo_įrašai j ON (j.id = e.fk_irasas) WHERE e.fk_vartotojas = $1 AND e.sąskaita = $2 AND j.sukurta >= $3 AND j.sukurta < $4 ORDER BY j.id ASC"
+	getBalanceAtSQL            = "SELECT COALESCE((SELECT e.likutis FROM žurnalo_eilutės e INNER JOIN žurnalo_įrašai j ON (j.id = e.fk_irasas) WHERE e.fk_vartotojas = v.id AND e.sąskaita = $2 AND j.sukurta < $3 ORDER BY j.id DESC LIMIT 1), 0), v.valiuta FROM vartotojai v WHERE v.id = $1"
+
+	getBalanceSQL          = "SELECT balansas, valiuta FROM vartotojai WHERE id = $1"
+	getBalanceForUpdateSQL = getBalanceSQL + " FOR UPDATE"
 )
 
 type PgRepo struct {
@@ -39,8 +69,8 @@ func (p *PgRepo) NewTx(ctx context.Context) (repository.Transaction, error) {
 	return p.conn.BeginTx(ctx, nil)
 }
 
-func (p *PgRepo) InsertIfNotExists(ctx context.Context, us *domain.User) error {
-	err := p.conn.QueryRowContext(
+func (p *PgRepo) insertIfNotExists(ctx context.Context, q pgsql.Querier, us *domain.User) error {
+	err := q.QueryRowContext(
 		ctx,
 		insertIfNotExistsSQL,
 
@@ -49,7 +79,8 @@ func (p *PgRepo) InsertIfNotExists(ctx context.Context, us *domain.User) error {
 		us.Email,
 		us.BirthDate,
 		us.Password,
-		us.Balance,
+		us.Balance.Amount,
+		us.Balance.Currency,
 		us.PIN,
 		us.RoleID,
 	).Scan(&us.ID)
@@ -57,6 +88,25 @@ func (p *PgRepo) InsertIfNotExists(ctx context.Context, us *domain.User) error {
 	return pgsql.ParsePgError(err)
 }
 
+func (p *PgRepo) InsertIfNotExists(ctx context.Context, us *domain.User) error {
+	return p.insertIfNotExists(ctx, p.conn, us)
+}
+
+func (p *PgRepo) InsertIfNotExistsTx(ctx context.Context, tx repository.Transaction, us *domain.User) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := p.insertIfNotExists(ctx, sqlTx, us)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
 func (p PgRepo) EmailExists(ctx context.Context, email string) (bool, error) {
 	var exists bool
 	err := p.conn.QueryRowContext(ctx, emailExistsSQL, email).Scan(&exists)
@@ -69,7 +119,7 @@ func (p PgRepo) EmailExists(ctx context.Context, email string) (bool, error) {
 func (p PgRepo) GetCredentials(ctx context.Context, email string) (*domain.UserCredentials, error) {
 	c := &domain.UserCredentials{}
 
-	err := p.conn.QueryRowContext(ctx, getCredentialsSQL, email).Scan(&c.ID, &c.RoleID, &c.Password)
+	err := p.conn.QueryRowContext(ctx, getCredentialsSQL, email).Scan(&c.ID, &c.RoleID, &c.Password, &c.TwoFactor)
 	if err != nil {
 		return nil, pgsql.ParseSQLError(err)
 	}
@@ -77,44 +127,258 @@ func (p PgRepo) GetCredentials(ctx context.Context, email string) (*domain.UserC
 	return c, nil
 }
 
-func (p *PgRepo) deductBalance(ctx context.Context, q pgsql.Querier, id int, value int64) error {
-	res, err := q.ExecContext(ctx, deductBalanceSQL, id, value)
+func (p *PgRepo) getBalance(ctx context.Context, q pgsql.Querier, id int, forUpdate bool) (domain.Money, error) {
+	var (
+		query   string
+		balance domain.Money
+	)
+
+	if forUpdate {
+		query = getBalanceForUpdateSQL
+	} else {
+		query = getBalanceSQL
+	}
+
+	err := q.QueryRowContext(ctx, query, id).Scan(&balance.Amount, &balance.Currency)
 	if err != nil {
-		return pgsql.ParsePgError(err)
+		return domain.Money{}, pgsql.ParseSQLError(err)
 	}
 
-	n, err := res.RowsAffected()
+	return balance, nil
+}
+
+func (p *PgRepo) GetBalance(ctx context.Context, id int) (domain.Money, error) {
+	return p.getBalance(ctx, p.conn, id, false)
+}
+
+func (p *PgRepo) GetBalanceTx(ctx context.Context, tx repository.Transaction, id int) (domain.Money, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return domain.Money{}, repository.ErrTxMismatch
+	}
+
+	balance, err := p.getBalance(ctx, sqlTx, id, true)
 	if err != nil {
+		sqlTx.Rollback()
+		return domain.Money{}, err
+	}
+
+	return balance, nil
+}
+
+func (p *PgRepo) GetLicenseStatus(ctx context.Context, uid int) (string, error) {
+	var licenseStatus string
+	var licenseEndDate time.Time
+
+	if err := p.conn.QueryRowContext(ctx, getLicenseStatusSQL, uid).Scan(&licenseStatus, &licenseEndDate); err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	if len(licenseStatus) > 0 {
+		if licenseEndDate.Before(time.Now()) {
+			return "pasibaigęs galiojimas", nil
+		}
+
+		return strings.TrimSpace(licenseStatus), nil
+	}
+
+	return "nepateiktas", nil
+}
+
+func (p *PgRepo) GetData(ctx context.Context, uid int) (*user.UserProfile, error) {
+	u := &user.UserProfile{}
+	u.ID = uid
+
+	err := p.conn.QueryRowContext(ctx, getDataSQL, uid, domain.PendingPaymentStatus).Scan(&u.FirstName, &u.LastName, &u.Email, &u.EmailVerified, &u.PendingEmail, &u.Birthdate, &u.Balance.Amount, &u.Balance.Currency, &u.PendingPayments.Amount)
+	if err != nil {
+		return nil, pgsql.ParseSQLError(err)
+	}
+	u.PendingPayments.Currency = u.Balance.Currency
+
+	return u, nil
+}
+
+func (p *PgRepo) UpdatePassword(ctx context.Context, uid int, hash string) error {
+	if err := p.conn.QueryRowContext(ctx, updatePasswordSQL, uid, hash).Err(); err != nil {
+		return pgsql.ParseSQLError(err)
+	}
+	return nil
+}
+
+func (p *PgRepo) execTx(ctx context.Context, tx repository.Transaction, query string, args ...interface{}) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	_, err := sqlTx.ExecContext(ctx, query, args...)
+	if err != nil {
+		sqlTx.Rollback()
 		return err
 	}
-	if n == 0 {
-		return domain.ErrNotFound
+
+	return nil
+}
+
+func (p *PgRepo) UpdatePasswordTx(ctx context.Context, tx repository.Transaction, uid int, hash string) error {
+	return p.execTx(ctx, tx, updatePasswordSQL, uid, hash)
+}
+
+func (p *PgRepo) InsertPasswordResetTx(ctx context.Context, tx repository.Transaction, r *domain.PasswordReset) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := sqlTx.QueryRowContext(ctx, insertPasswordResetSQL, r.UserID, r.TokenHash, r.Expiration, time.Now()).Scan(&r.ID)
+	if err != nil {
+		sqlTx.Rollback()
+		return pgsql.ParsePgError(err)
+	}
+
+	return nil
+}
+
+func (p *PgRepo) GetPasswordResetTx(ctx context.Context, tx repository.Transaction, tokenHash string) (*domain.PasswordReset, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	r := &domain.PasswordReset{}
+
+	err := sqlTx.QueryRowContext(ctx, getPasswordResetForUpdateSQL, tokenHash).Scan(
+		&r.ID,
+		&r.UserID,
+		&r.Email,
+		&r.TokenHash,
+		&r.Expiration,
+		&r.UsedAt,
+	)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	return r, nil
+}
+
+func (p *PgRepo) UsePasswordResetTx(ctx context.Context, tx repository.Transaction, resetID int, t time.Time) error {
+	return p.execTx(ctx, tx, usePasswordResetSQL, resetID, t)
+}
+
+func (p *PgRepo) InsertEmailVerificationTx(ctx context.Context, tx repository.Transaction, v *domain.EmailVerification) error {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return repository.ErrTxMismatch
+	}
+
+	err := sqlTx.QueryRowContext(ctx, insertEmailVerificationSQL, v.UserID, v.Email, v.TokenHash, v.Expiration, time.Now()).Scan(&v.ID)
+	if err != nil {
+		sqlTx.Rollback()
+		return pgsql.ParsePgError(err)
 	}
 
 	return nil
 }
 
-func (p *PgRepo) DeductBalance(ctx context.Context, id int, value int64) error {
-	return p.deductBalance(ctx, p.conn, id, value)
+func (p *PgRepo) GetEmailVerificationTx(ctx context.Context, tx repository.Transaction, tokenHash string) (*domain.EmailVerification, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	v := &domain.EmailVerification{}
+
+	err := sqlTx.QueryRowContext(ctx, getEmailVerificationForUpdateSQL, tokenHash).Scan(
+		&v.ID,
+		&v.UserID,
+		&v.Email,
+		&v.TokenHash,
+		&v.Expiration,
+		&v.UsedAt,
+	)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	return v, nil
+}
+
+func (p *PgRepo) UseEmailVerificationTx(ctx context.Context, tx repository.Transaction, verificationID int, t time.Time) error {
+	return p.execTx(ctx, tx, useEmailVerificationSQL, verificationID, t)
 }
 
-func (p *PgRepo) DeductBalanceTx(ctx context.Context, tx repository.Transaction, id int, value int64) error {
+func (p *PgRepo) VerifyEmailTx(ctx context.Context, tx repository.Transaction, uid int, email string, t time.Time) error {
 	sqlTx, ok := tx.(*sql.Tx)
 	if !ok {
 		return repository.ErrTxMismatch
 	}
 
-	err := p.deductBalance(ctx, sqlTx, id, value)
+	res, err := sqlTx.ExecContext(ctx, verifyEmailSQL, uid, email, t)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	n, err := res.RowsAffected()
 	if err != nil {
 		sqlTx.Rollback()
 		return err
 	}
+	if n == 0 {
+		sqlTx.Rollback()
+		return domain.ErrExists
+	}
 
 	return nil
 }
 
-func (p *PgRepo) addBalance(ctx context.Context, q pgsql.Querier, id int, value int64) error {
-	res, err := q.ExecContext(ctx, addBalanceSQL, id, value)
+func (p *PgRepo) getTwoFactor(ctx context.Context, q pgsql.Querier, uid int, forUpdate bool) (*domain.TwoFactor, error) {
+	query := getTwoFactorSQL
+	if forUpdate {
+		query = getTwoFactorForUpdateSQL
+	}
+
+	t := &domain.TwoFactor{}
+
+	err := q.QueryRowContext(ctx, query, uid).Scan(
+		&t.UserID,
+		&t.Email,
+		&t.RoleID,
+		&t.Secret,
+		&t.EnabledAt,
+		&t.LastStep,
+	)
+	if err != nil {
+		return nil, pgsql.ParseSQLError(err)
+	}
+
+	return t, nil
+}
+
+func (p *PgRepo) GetTwoFactor(ctx context.Context, uid int) (*domain.TwoFactor, error) {
+	return p.getTwoFactor(ctx, p.conn, uid, false)
+}
+
+func (p *PgRepo) GetTwoFactorTx(ctx context.Context, tx repository.Transaction, uid int) (*domain.TwoFactor, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
+
+	t, err := p.getTwoFactor(ctx, sqlTx, uid, true)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (p *PgRepo) SetTwoFactorSecret(ctx context.Context, uid int, secret []byte) error {
+	res, err := p.conn.ExecContext(ctx, setTwoFactorSecretSQL, uid, secret)
 	if err != nil {
 		return err
 	}
@@ -124,94 +388,187 @@ func (p *PgRepo) addBalance(ctx context.Context, q pgsql.Querier, id int, value
 		return err
 	}
 	if n == 0 {
-		return domain.ErrNotFound
+		return domain.ErrExists
 	}
 
 	return nil
 }
 
-func (p *PgRepo) AddBalance(ctx context.Context, id int, value int64) error {
-	return p.addBalance(ctx, p.conn, id, value)
+func (p *PgRepo) EnableTwoFactorTx(ctx context.Context, tx repository.Transaction, uid int, step int64, t time.Time) error {
+	return p.execTx(ctx, tx, enableTwoFactorSQL, uid, step, t)
+}
+
+func (p *PgRepo) SetTwoFactorStepTx(ctx context.Context, tx repository.Transaction, uid int, step int64) error {
+	return p.execTx(ctx, tx, setTwoFactorStepSQL, uid, step)
 }
 
-func (p *PgRepo) AddBalanceTx(ctx context.Context, tx repository.Transaction, id int, value int64) error {
+// affectedTx runs the query and returns domain.ErrNotFound if no rows were changed,
+// the transaction is rolled back only on errors other than that
+func (p *PgRepo) affectedTx(ctx context.Context, tx repository.Transaction, query string, args ...interface{}) error {
 	sqlTx, ok := tx.(*sql.Tx)
 	if !ok {
 		return repository.ErrTxMismatch
 	}
 
-	err := p.addBalance(ctx, sqlTx, id, value)
+	res, err := sqlTx.ExecContext(ctx, query, args...)
+	if err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+
+	n, err := res.RowsAffected()
 	if err != nil {
 		sqlTx.Rollback()
 		return err
 	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
 
 	return nil
 }
 
-func (p *PgRepo) GetLicenseStatus(ctx context.Context, uid int) (string, error) {
-	var licenseStatus string
-	var licenseEndDate time.Time
+func (p *PgRepo) DisableTwoFactorTx(ctx context.Context, tx repository.Transaction, uid int) error {
+	if err := p.affectedTx(ctx, tx, disableTwoFactorSQL, uid); err != nil {
+		return err
+	}
+	return p.execTx(ctx, tx, deleteBackupCodesSQL, uid)
+}
 
-	if err := p.conn.QueryRowContext(ctx, getLicenseStatusSQL, uid).Scan(&licenseStatus, &licenseEndDate); err != nil && err != sql.ErrNoRows {
-		return "", err
+func (p *PgRepo) ReplaceBackupCodesTx(ctx context.Context, tx repository.Transaction, uid int, hashes []string) error {
+	if err := p.execTx(ctx, tx, deleteBackupCodesSQL, uid); err != nil {
+		return err
 	}
 
-	if len(licenseStatus) > 0 {
-		if licenseEndDate.Before(time.Now()) {
-			return "pasibaigęs galiojimas", nil
+	for _, h := range hashes {
+		if err := p.execTx(ctx, tx, insertBackupCodeSQL, uid, h); err != nil {
+			return err
 		}
-
-		return strings.TrimSpace(licenseStatus), nil
 	}
 
-	return "nepateiktas", nil
+	return nil
 }
 
-func (p *PgRepo) GetData(ctx context.Context, uid int) (*user.UserProfile, error) {
-	u := &user.UserProfile{}
-	u.ID = uid
+func (p *PgRepo) UseBackupCodeTx(ctx context.Context, tx repository.Transaction, uid int, hash string, t time.Time) error {
+	return p.affectedTx(ctx, tx, useBackupCodeSQL, uid, hash, t)
+}
+
+func (p *PgRepo) InsertLoginChallenge(ctx context.Context, c *domain.LoginChallenge) error {
+	err := p.conn.QueryRowContext(ctx, insertLoginChallengeSQL, c.UserID, c.TokenHash, c.Expiration).Scan(&c.ID)
+	return pgsql.ParsePgError(err)
+}
+
+func (p *PgRepo) GetLoginChallengeTx(ctx context.Context, tx repository.Transaction, tokenHash string) (*domain.LoginChallenge, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
+	}
 
-	err := p.conn.QueryRowContext(ctx, getDataSQL, uid).Scan(&u.FirstName, &u.LastName, &u.Email, &u.Birthdate, &u.Balance)
+	c := &domain.LoginChallenge{}
+
+	err := sqlTx.QueryRowContext(ctx, getLoginChallengeForUpdateSQL, tokenHash).Scan(
+		&c.ID,
+		&c.UserID,
+		&c.TokenHash,
+		&c.Expiration,
+		&c.Attempts,
+	)
 	if err != nil {
+		sqlTx.Rollback()
 		return nil, pgsql.ParseSQLError(err)
 	}
-	u.Balance /= 100
 
-	return u, nil
+	return c, nil
 }
 
-func (p *PgRepo) UpdateEmail(ctx context.Context, uid int, email string) error {
-	if err := p.conn.QueryRowContext(ctx, updateEmailSQL, uid, email).Err(); err != nil {
-		return pgsql.ParseSQLError(err)
+func (p *PgRepo) IncLoginChallengeAttemptsTx(ctx context.Context, tx repository.Transaction, challengeID int) error {
+	return p.execTx(ctx, tx, incLoginChallengeAttemptsSQL, challengeID)
+}
+
+func (p *PgRepo) DeleteLoginChallengeTx(ctx context.Context, tx repository.Transaction, challengeID int) error {
+	return p.execTx(ctx, tx, deleteLoginChallengeSQL, challengeID)
+}
+
+// DeleteSessionsTx returns the IDs of the deleted sessions
+func (p *PgRepo) DeleteSessionsTx(ctx context.Context, tx repository.Transaction, uid int) ([]string, error) {
+	sqlTx, ok := tx.(*sql.Tx)
+	if !ok {
+		return nil, repository.ErrTxMismatch
 	}
-	return nil
+
+	rows, err := sqlTx.QueryContext(ctx, deleteSessionsSQL, uid)
+	if err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	var ids []string
+
+	for rows.Next() {
+		var id string
+
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			sqlTx.Rollback()
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Close(); err != nil {
+		sqlTx.Rollback()
+		return nil, err
+	}
+
+	return ids, nil
 }
 
-func (p *PgRepo) UpdatePassword(ctx context.Context, uid int, hash string) error {
-	if err := p.conn.QueryRowContext(ctx, updatePasswordSQL, uid, hash).Err(); err != nil {
-		return pgsql.ParseSQLError(err)
+func toPoint(lat, lng *float64) *domain.Point {
+	if lat == nil || lng == nil {
+		return nil
 	}
-	return nil
+	return &domain.Point{Lat: *lat, Lng: *lng}
 }
 
 func scanRows(rows *sql.Rows) ([]*domain.UserTrip, error) {
 	var trips []*domain.UserTrip
 	for rows.Next() {
+		var (
+			paymentStatus                  sql.NullString
+			fromAddr, toAddr               *string
+			fromLat, fromLng, toLat, toLng *float64
+		)
 		trip := domain.UserTrip{
 			ID:    0,
 			Begin: time.Now(),
 			End:   time.Now(),
 			From:  "",
 			To:    "",
-			Price: 0,
+			Price: domain.Money{},
 		}
 
-		err := rows.Scan(&trip.ID, &trip.Begin, &trip.End, &trip.Price)
+		err := rows.Scan(
+			&trip.ID,
+			&trip.Begin,
+			&trip.End,
+			&trip.Price.Amount,
+			&trip.Price.Currency,
+			&paymentStatus,
+			&fromAddr,
+			&toAddr,
+			&fromLat,
+			&fromLng,
+			&toLat,
+			&toLng,
+		)
 		if err != nil {
 			rows.Close()
 			return nil, err
 		}
+		trip.PaymentStatus = strings.TrimSpace(paymentStatus.String)
+		trip.From = domain.Address(fromAddr, toPoint(fromLat, fromLng))
+		trip.To = domain.Address(toAddr, toPoint(toLat, toLng))
 
 		trips = append(trips, &trip)
 	}
@@ -224,10 +581,7 @@ func scanRows(rows *sql.Rows) ([]*domain.UserTrip, error) {
 }
 
 func (p *PgRepo) GetTrips(ctx context.Context, uid int) ([]*domain.UserTrip, error) {
-	fmt.Println("get trips")
-	fmt.Println(uid)
 	rows, err := p.conn.QueryContext(ctx, getTripsSQL, uid)
-	fmt.Println(err)
 	if err != nil {
 		if err != sql.ErrNoRows {
 			return nil, err
@@ -238,15 +592,88 @@ func (p *PgRepo) GetTrips(ctx context.Context, uid int) ([]*domain.UserTrip, err
 	return scanRows(rows)
 }
 
-func (p *PgRepo) AddPayment(ctx context.Context, uid int, amount int64) error {
-	if amount <= 0 {
-		if err := p.conn.QueryRowContext(ctx, addPaymentSQL, amount, 1, uid).Err(); err != nil {
-			return pgsql.ParseSQLError(err)
+func (p *PgRepo) GetTripsBetween(ctx context.Context, uid int, from, to time.Time) ([]*domain.UserTrip, error) {
+	rows, err := p.conn.QueryContext(ctx, getTripsBetweenSQL, uid, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRows(rows)
+}
+
+func (p *PgRepo) GetTopUpsBetween(ctx context.Context, uid int, from, to time.Time) ([]*domain.UserPayment, error) {
+	rows, err := p.conn.QueryContext(ctx, getTopUpsBetweenSQL, uid, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var ps []*domain.UserPayment
+
+	for rows.Next() {
+		pm := &domain.UserPayment{}
+
+		err := rows.Scan(&pm.ID, &pm.Created, &pm.Amount.Amount, &pm.Amount.Currency, &pm.Provider, &pm.Status)
+		if err != nil {
+			rows.Close()
+			return nil, err
 		}
-	} else {
-		if err := p.conn.QueryRowContext(ctx, addPaymentSQL, amount, 2, uid).Err(); err != nil {
-			return pgsql.ParseSQLError(err)
+		pm.Status = strings.TrimSpace(pm.Status)
+
+		ps = append(ps, pm)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+func (p *PgRepo) GetWalletEntriesBetween(ctx context.Context, uid int, from, to time.Time) ([]*domain.WalletEntry, error) {
+	rows, err := p.conn.QueryContext(ctx, getWalletEntriesBetweenSQL, uid, domain.WalletLedgerAccount, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var es []*domain.WalletEntry
+
+	for rows.Next() {
+		e := &domain.WalletEntry{}
+
+		err := rows.Scan(
+			&e.ID,
+			&e.Type,
+			&e.Description,
+			&e.CreatedAt,
+			&e.TripID,
+			&e.PaymentID,
+			&e.Amount.Amount,
+			&e.Amount.Currency,
+			&e.Balance.Amount,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, err
 		}
+		e.Balance.Currency = e.Amount.Currency
+
+		es = append(es, e)
 	}
-	return nil
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return es, nil
+}
+
+func (p *PgRepo) GetBalanceAt(ctx context.Context, uid int, t time.Time) (domain.Money, error) {
+	var m domain.Money
+
+	err := p.conn.QueryRowContext(ctx, getBalanceAtSQL, uid, domain.WalletLedgerAccount, t).Scan(&m.Amount, &m.Currency)
+	if err != nil {
+		return domain.Money{}, pgsql.ParseSQLError(err)
+	}
+
+	return m, nil
 }
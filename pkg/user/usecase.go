@@ -16,5 +16,16 @@ type Usecase interface {
 	GetData(ctx context.Context, uid int) (*UserProfile, error)
 	UpdateUser(ctx context.Context, uid int, data *UpdateReq) (*UpdateRes, error)
 	GetTrips(ctx context.Context, uid int) ([]*TripsRes, error)
-	CheckPayment(ctx context.Context, uid int) (*PaymentRes, error)
+	GetStatement(ctx context.Context, uid int, req *StatementReq) (*StatementRes, error)
+	VerifyEmail(ctx context.Context, req *VerifyEmailReq) error
+	ResendVerification(ctx context.Context, uid int) error
+	SetupTwoFactor(ctx context.Context, uid int) (*TwoFactorSetupRes, error)
+	EnableTwoFactor(ctx context.Context, uid int, req *TwoFactorCodeReq) (*BackupCodesRes, error)
+	DisableTwoFactor(ctx context.Context, ss *domain.Session, req *TwoFactorCodeReq) error
+	RegenerateBackupCodes(ctx context.Context, uid int, req *TwoFactorCodeReq) (*BackupCodesRes, error)
+	SetupTwoFactorChallenge(ctx context.Context, req *TwoFactorChallengeReq) (*TwoFactorSetupRes, error)
+	VerifyTwoFactor(ctx context.Context, req *TwoFactorVerifyReq) (*domain.Session, *TwoFactorVerifyRes, error)
+	ResetTwoFactor(ctx context.Context, req *ResetTwoFactorReq) error
+	RequestPasswordReset(ctx context.Context, req *ForgotPasswordReq) error
+	ResetPassword(ctx context.Context, req *ResetPasswordReq) error
 }
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+	"github.com/wascript3r/autonuoma/pkg/user"
+)
+
+// sendVerificationTx issues a verification token for email and queues the link to it
+func (u *Usecase) sendVerificationTx(ctx context.Context, tx repository.Transaction, uid int, email string) error {
+	token, err := generateToken()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	v := &domain.EmailVerification{
+		UserID:     uid,
+		Email:      email,
+		TokenHash:  hashToken(token),
+		Expiration: time.Now().Add(u.opts.VerificationLifetime),
+	}
+
+	err = u.userRepo.InsertEmailVerificationTx(ctx, tx, v)
+	if err != nil {
+		return err
+	}
+
+	return u.mailRepo.EnqueueTx(ctx, tx, &domain.Email{
+		To:      email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf(
+			"To confirm your email address open the link below:\n\n%s?token=%s\n\nThe link is valid until %s.\n",
+			u.opts.VerificationURL,
+			token,
+			v.Expiration.Format(user.TripDateTimeFormat),
+		),
+	})
+}
+
+// VerifyEmail confirms the email the token was sent to and makes it the account email
+func (u *Usecase) VerifyEmail(ctx context.Context, req *user.VerifyEmailReq) error {
+	if err := u.validate.RawRequest(req); err != nil {
+		return user.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.userRepo.NewTx(c)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	v, err := u.userRepo.GetEmailVerificationTx(c, tx, hashToken(req.Token))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return user.InvalidVerificationTokenError
+		}
+		return err
+	}
+
+	now := time.Now()
+	if !v.Usable(now) {
+		tx.Rollback()
+		return user.InvalidVerificationTokenError
+	}
+
+	err = u.userRepo.VerifyEmailTx(c, tx, v.UserID, v.Email, now)
+	if err != nil {
+		if err == domain.ErrExists {
+			return user.EmailAlreadyExistsError
+		}
+		return err
+	}
+
+	err = u.userRepo.UseEmailVerificationTx(c, tx, v.ID, now)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResendVerification sends a new verification link to the unverified account email
+func (u *Usecase) ResendVerification(ctx context.Context, uid int) error {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	data, err := u.userRepo.GetData(c, uid)
+	if err != nil {
+		return err
+	}
+
+	if data.EmailVerified {
+		return user.EmailAlreadyVerifiedError
+	}
+
+	tx, err := u.userRepo.NewTx(c)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = u.sendVerificationTx(c, tx, uid, data.Email)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
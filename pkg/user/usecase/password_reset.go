@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/user"
+)
+
+// RequestPasswordReset emails a single-use reset link. Unknown emails
+// are not reported, so the endpoint cannot be used to probe accounts.
+func (u *Usecase) RequestPasswordReset(ctx context.Context, req *user.ForgotPasswordReq) error {
+	if err := u.validate.RawRequest(req); err != nil {
+		return user.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	credentials, err := u.userRepo.GetCredentials(c, req.Email)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return err
+	}
+
+	tx, err := u.userRepo.NewTx(c)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	r := &domain.PasswordReset{
+		UserID:     credentials.ID,
+		Email:      req.Email,
+		TokenHash:  hashToken(token),
+		Expiration: time.Now().Add(u.opts.ResetLifetime),
+	}
+
+	err = u.userRepo.InsertPasswordResetTx(c, tx, r)
+	if err != nil {
+		return err
+	}
+
+	err = u.mailRepo.EnqueueTx(c, tx, &domain.Email{
+		To:      req.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"To set a new password open the link below:\n\n%s?token=%s\n\nThe link is valid until %s. If you did not request a password reset, ignore this email.\n",
+			u.opts.ResetURL,
+			token,
+			r.Expiration.Format(user.TripDateTimeFormat),
+		),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResetPassword sets a new password using a reset token and signs the
+// user out of every session.
+func (u *Usecase) ResetPassword(ctx context.Context, req *user.ResetPasswordReq) error {
+	if err := u.validate.RawRequest(req); err != nil {
+		return user.InvalidInputError
+	}
+
+	hash, err := u.pwHasher.Hash(req.Password)
+	if err != nil {
+		return err
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.userRepo.NewTx(c)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	r, err := u.userRepo.GetPasswordResetTx(c, tx, hashToken(req.Token))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return user.InvalidResetTokenError
+		}
+		return err
+	}
+
+	now := time.Now()
+	if !r.Usable(now) {
+		tx.Rollback()
+		return user.InvalidResetTokenError
+	}
+
+	err = u.userRepo.UpdatePasswordTx(c, tx, r.UserID, hash)
+	if err != nil {
+		return err
+	}
+
+	err = u.userRepo.UsePasswordResetTx(c, tx, r.ID, now)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := u.userRepo.DeleteSessionsTx(c, tx, r.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = u.mailRepo.EnqueueTx(c, tx, &domain.Email{
+		To:      r.Email,
+		Subject: "Your password was changed",
+		Body:    "Your password was changed and all devices were signed out. If it was not you, contact support immediately.\n",
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	u.sessionUcase.NotifyRevoked(ctx, revoked)
+	return nil
+}
@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"crypto/rand"
+
+	"github.com/wascript3r/gocipher/encoder"
+	"github.com/wascript3r/gocipher/sha256"
+)
+
+const tokenSize = 32
+
+// generateToken returns a random hex token, only its hash is stored
+func generateToken() (string, error) {
+	bs := make([]byte, tokenSize)
+
+	_, err := rand.Read(bs)
+	if err != nil {
+		return "", err
+	}
+
+	return string(encoder.HexEncode(bs)), nil
+}
+
+func hashToken(token string) string {
+	return string(encoder.HexEncode(sha256.Compute([]byte(token))))
+}
@@ -0,0 +1,495 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"strings"
+	"time"
+
+	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/repository"
+	"github.com/wascript3r/autonuoma/pkg/user"
+)
+
+const (
+	backupCodeCount  = 10
+	backupCodeLength = 10
+	// Without characters which are easy to confuse
+	backupCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+func generateBackupCodes() ([]string, error) {
+	bs := make([]byte, backupCodeCount*backupCodeLength)
+
+	_, err := rand.Read(bs)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, backupCodeCount)
+	for i := range codes {
+		var b strings.Builder
+		for j, c := range bs[i*backupCodeLength : (i+1)*backupCodeLength] {
+			if j == backupCodeLength/2 {
+				b.WriteByte('-')
+			}
+			b.WriteByte(backupCodeAlphabet[int(c)%len(backupCodeAlphabet)])
+		}
+		codes[i] = b.String()
+	}
+
+	return codes, nil
+}
+
+// isTOTPCode tells TOTP codes apart from backup codes, which always contain letters
+func isTOTPCode(code string) bool {
+	return len(code) == 6 && strings.Trim(code, "0123456789") == ""
+}
+
+func normalizeBackupCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// replaceBackupCodesTx stores the hashes of new backup codes and returns the codes
+func (u *Usecase) replaceBackupCodesTx(ctx context.Context, tx repository.Transaction, uid int) ([]string, error) {
+	codes, err := generateBackupCodes()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = hashToken(normalizeBackupCode(c))
+	}
+
+	err = u.userRepo.ReplaceBackupCodesTx(ctx, tx, uid, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// validateCode checks a TOTP code against the stored secret, codes of
+// already used time steps are rejected
+func (u *Usecase) validateCode(tf *domain.TwoFactor, code string, t time.Time) (int64, error) {
+	if tf.Secret == nil {
+		return 0, user.TwoFactorNotSetUpError
+	}
+
+	secret, err := u.cipher.Decrypt(tf.Secret)
+	if err != nil {
+		return 0, err
+	}
+
+	step, ok := u.otp.Validate(string(secret), code, t)
+	if !ok || step <= tf.LastStep {
+		return 0, user.InvalidTwoFactorCodeError
+	}
+
+	return step, nil
+}
+
+func (u *Usecase) issueChallenge(ctx context.Context, uid int) (*user.TwoFactorChallenge, error) {
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.userRepo.InsertLoginChallenge(ctx, &domain.LoginChallenge{
+		UserID:     uid,
+		TokenHash:  hashToken(token),
+		Expiration: time.Now().Add(u.opts.ChallengeLifetime),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.TwoFactorChallenge{Token: token}, nil
+}
+
+func (u *Usecase) setupTwoFactor(ctx context.Context, uid int) (*user.TwoFactorSetupRes, error) {
+	tf, err := u.userRepo.GetTwoFactor(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if tf.Enabled() {
+		return nil, user.TwoFactorAlreadyEnabledError
+	}
+
+	secret, err := u.otp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := u.cipher.Encrypt([]byte(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.userRepo.SetTwoFactorSecret(ctx, uid, encrypted)
+	if err != nil {
+		if err == domain.ErrExists {
+			return nil, user.TwoFactorAlreadyEnabledError
+		}
+		return nil, err
+	}
+
+	return &user.TwoFactorSetupRes{
+		Secret: secret,
+		URI:    u.otp.URI(secret, tf.Email),
+	}, nil
+}
+
+// SetupTwoFactor generates a new secret, two-factor authentication is
+// enabled only after the first code is confirmed
+func (u *Usecase) SetupTwoFactor(ctx context.Context, uid int) (*user.TwoFactorSetupRes, error) {
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	return u.setupTwoFactor(c, uid)
+}
+
+func (u *Usecase) EnableTwoFactor(ctx context.Context, uid int, req *user.TwoFactorCodeReq) (*user.BackupCodesRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, user.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.userRepo.NewTx(c)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	tf, err := u.userRepo.GetTwoFactorTx(c, tx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if tf.Enabled() {
+		return nil, user.TwoFactorAlreadyEnabledError
+	}
+
+	now := time.Now()
+	step, err := u.validateCode(tf, req.Code, now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.userRepo.EnableTwoFactorTx(c, tx, uid, step, now)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := u.replaceBackupCodesTx(c, tx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &user.BackupCodesRes{BackupCodes: codes}, nil
+}
+
+func (u *Usecase) DisableTwoFactor(ctx context.Context, ss *domain.Session, req *user.TwoFactorCodeReq) error {
+	if err := u.validate.RawRequest(req); err != nil {
+		return user.InvalidInputError
+	}
+
+	if domain.TwoFactorRequired(ss.RoleID) {
+		return user.TwoFactorRequiredError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.userRepo.NewTx(c)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	tf, err := u.userRepo.GetTwoFactorTx(c, tx, ss.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !tf.Enabled() {
+		return user.TwoFactorNotEnabledError
+	}
+
+	_, err = u.validateCode(tf, req.Code, time.Now())
+	if err != nil {
+		return err
+	}
+
+	err = u.userRepo.DisableTwoFactorTx(c, tx, ss.UserID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RegenerateBackupCodes invalidates the remaining backup codes
+func (u *Usecase) RegenerateBackupCodes(ctx context.Context, uid int, req *user.TwoFactorCodeReq) (*user.BackupCodesRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, user.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.userRepo.NewTx(c)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	tf, err := u.userRepo.GetTwoFactorTx(c, tx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tf.Enabled() {
+		return nil, user.TwoFactorNotEnabledError
+	}
+
+	step, err := u.validateCode(tf, req.Code, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.userRepo.SetTwoFactorStepTx(c, tx, uid, step)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := u.replaceBackupCodesTx(c, tx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &user.BackupCodesRes{BackupCodes: codes}, nil
+}
+
+// getChallengeTx locks a usable login challenge, expired and exhausted
+// challenges are deleted
+func (u *Usecase) getChallengeTx(ctx context.Context, tx repository.Transaction, token string) (*domain.LoginChallenge, error) {
+	ch, err := u.userRepo.GetLoginChallengeTx(ctx, tx, hashToken(token))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, user.InvalidLoginChallengeError
+		}
+		return nil, err
+	}
+
+	if !ch.Usable(time.Now(), u.opts.ChallengeAttempts) {
+		err = u.userRepo.DeleteLoginChallengeTx(ctx, tx, ch.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, user.InvalidLoginChallengeError
+	}
+
+	return ch, nil
+}
+
+// SetupTwoFactorChallenge lets users who must use two-factor
+// authentication enrol while signing in
+func (u *Usecase) SetupTwoFactorChallenge(ctx context.Context, req *user.TwoFactorChallengeReq) (*user.TwoFactorSetupRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, user.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.userRepo.NewTx(c)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	ch, err := u.getChallengeTx(c, tx, req.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return u.setupTwoFactor(c, ch.UserID)
+}
+
+// VerifyTwoFactor completes the sign in started by Authenticate. When the
+// user is not enrolled yet, a valid code also enables two-factor authentication.
+func (u *Usecase) VerifyTwoFactor(ctx context.Context, req *user.TwoFactorVerifyReq) (*domain.Session, *user.TwoFactorVerifyRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, nil, user.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.userRepo.NewTx(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer tx.Rollback()
+
+	ch, err := u.getChallengeTx(c, tx, req.Challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tf, err := u.userRepo.GetTwoFactorTx(c, tx, ch.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := u.lockoutUcase.Check(c, tf.Email, req.IP); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		now   = time.Now()
+		codes []string
+	)
+
+	if !tf.Enabled() {
+		var step int64
+		step, err = u.validateCode(tf, req.Code, now)
+		if err == nil {
+			err = u.userRepo.EnableTwoFactorTx(c, tx, tf.UserID, step, now)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			codes, err = u.replaceBackupCodesTx(c, tx, tf.UserID)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	} else if isTOTPCode(req.Code) {
+		var step int64
+		step, err = u.validateCode(tf, req.Code, now)
+		if err == nil {
+			err = u.userRepo.SetTwoFactorStepTx(c, tx, tf.UserID, step)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	} else {
+		err = u.userRepo.UseBackupCodeTx(c, tx, tf.UserID, hashToken(normalizeBackupCode(req.Code)), now)
+		if err == domain.ErrNotFound {
+			err = user.InvalidTwoFactorCodeError
+		} else if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err == user.InvalidTwoFactorCodeError {
+		if err := u.userRepo.IncLoginChallengeAttemptsTx(c, tx, ch.ID); err != nil {
+			return nil, nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, nil, err
+		}
+		if err := u.lockoutUcase.Fail(c, tf.Email, req.IP); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, user.InvalidTwoFactorCodeError
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = u.userRepo.DeleteLoginChallengeTx(c, tx, ch.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := u.lockoutUcase.Succeed(c, tf.Email); err != nil {
+		return nil, nil, err
+	}
+
+	s, err := u.sessionUcase.Create(ctx, tf.UserID, req.IP, req.UserAgent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res := &user.TwoFactorVerifyRes{
+		AuthenticateRes: &user.AuthenticateRes{
+			UserID: tf.UserID,
+			RoleID: tf.RoleID,
+		},
+		BackupCodes: codes,
+	}
+
+	return s, res, nil
+}
+
+// ResetTwoFactor is the admin recovery for users who lost their
+// authenticator and backup codes. The user is signed out everywhere.
+func (u *Usecase) ResetTwoFactor(ctx context.Context, req *user.ResetTwoFactorReq) error {
+	if err := u.validate.RawRequest(req); err != nil {
+		return user.InvalidInputError
+	}
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	tx, err := u.userRepo.NewTx(c)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = u.userRepo.DisableTwoFactorTx(c, tx, req.UserID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return user.UserNotFoundError
+		}
+		return err
+	}
+
+	revoked, err := u.userRepo.DeleteSessionsTx(c, tx, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	u.sessionUcase.NotifyRevoked(ctx, revoked)
+	return nil
+}
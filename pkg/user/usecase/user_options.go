@@ -0,0 +1,68 @@
+package usecase
+
+import "time"
+
+const (
+	DefaultResetLifetime        = time.Hour
+	DefaultVerificationLifetime = 24 * time.Hour
+	DefaultChallengeLifetime    = 5 * time.Minute
+	DefaultChallengeAttempts    = 5
+)
+
+type Options struct {
+	ResetLifetime time.Duration
+	// Links in emails are built by appending the token query parameter to the URLs
+	ResetURL             string
+	VerificationLifetime time.Duration
+	VerificationURL      string
+	// Time to enter the second factor after the password
+	ChallengeLifetime time.Duration
+	ChallengeAttempts int
+}
+
+type Option func(*Options)
+
+func newOptions(opt ...Option) *Options {
+	opts := &Options{}
+
+	for _, o := range opt {
+		o(opts)
+	}
+
+	if opts.ResetLifetime == 0 {
+		opts.ResetLifetime = DefaultResetLifetime
+	}
+	if opts.VerificationLifetime == 0 {
+		opts.VerificationLifetime = DefaultVerificationLifetime
+	}
+
+	if opts.ChallengeLifetime == 0 {
+		opts.ChallengeLifetime = DefaultChallengeLifetime
+	}
+	if opts.ChallengeAttempts == 0 {
+		opts.ChallengeAttempts = DefaultChallengeAttempts
+	}
+
+	return opts
+}
+
+func PasswordReset(lifetime time.Duration, url string) Option {
+	return func(o *Options) {
+		o.ResetLifetime = lifetime
+		o.ResetURL = url
+	}
+}
+
+func EmailVerification(lifetime time.Duration, url string) Option {
+	return func(o *Options) {
+		o.VerificationLifetime = lifetime
+		o.VerificationURL = url
+	}
+}
+
+func LoginChallenge(lifetime time.Duration, attempts int) Option {
+	return func(o *Options) {
+		o.ChallengeLifetime = lifetime
+		o.ChallengeAttempts = attempts
+	}
+}
@@ -2,32 +2,43 @@ package usecase
 
 import (
 	"context"
-	"fmt"
-	"math/rand"
+	"sort"
 	"time"
 
 	"github.com/wascript3r/autonuoma/pkg/domain"
+	"github.com/wascript3r/autonuoma/pkg/lockout"
+	"github.com/wascript3r/autonuoma/pkg/mail"
 	"github.com/wascript3r/autonuoma/pkg/session"
 	"github.com/wascript3r/autonuoma/pkg/user"
 )
 
 type Usecase struct {
 	userRepo   user.Repository
+	mailRepo   mail.Repository
 	ctxTimeout time.Duration
 
 	sessionUcase session.Usecase
 	pwHasher     user.PwHasher
 	validate     user.Validate
+	otp          user.OTP
+	cipher       user.Cipher
+	lockoutUcase lockout.Usecase
+	opts         *Options
 }
 
-func New(ur user.Repository, t time.Duration, su session.Usecase, ph user.PwHasher, v user.Validate) *Usecase {
+func New(ur user.Repository, mr mail.Repository, t time.Duration, su session.Usecase, ph user.PwHasher, v user.Validate, otp user.OTP, c user.Cipher, lu lockout.Usecase, opt ...Option) *Usecase {
 	return &Usecase{
 		userRepo:   ur,
+		mailRepo:   mr,
 		ctxTimeout: t,
 
 		sessionUcase: su,
 		pwHasher:     ph,
 		validate:     v,
+		otp:          otp,
+		cipher:       c,
+		lockoutUcase: lu,
+		opts:         newOptions(opt...),
 	}
 }
 
@@ -58,17 +69,29 @@ func (u *Usecase) Create(ctx context.Context, req *user.CreateReq) error {
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		BirthDate: time.Time(req.BirthDate),
-		Balance:   0,
+		Balance:   domain.Cents(0),
 		PIN:       req.PIN,
 		RoleID:    domain.ClientRole,
 	}
 
-	err = u.userRepo.InsertIfNotExists(c, us)
+	tx, err := u.userRepo.NewTx(c)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	defer tx.Rollback()
+
+	err = u.userRepo.InsertIfNotExistsTx(c, tx, us)
+	if err != nil {
+		return err
+	}
+
+	err = u.sendVerificationTx(c, tx, us.ID, us.Email)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (u *Usecase) Authenticate(ctx context.Context, req *user.AuthenticateReq) (*domain.Session, *user.AuthenticateRes, error) {
@@ -79,20 +102,40 @@ func (u *Usecase) Authenticate(ctx context.Context, req *user.AuthenticateReq) (
 	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
 	defer cancel()
 
+	// Blocked attempts do not reach the password hasher
+	if err := u.lockoutUcase.Check(c, req.Email, req.IP); err != nil {
+		return nil, nil, err
+	}
+
 	credentials, err := u.userRepo.GetCredentials(c, req.Email)
 	if err != nil {
 		if err == domain.ErrNotFound {
-			return nil, nil, user.InvalidCredentialsError
+			return nil, nil, u.failAuthentication(c, req)
 		}
 		return nil, nil, err
 	}
 
 	err = u.pwHasher.Validate(credentials.Password, req.Password)
 	if err != nil {
-		return nil, nil, user.InvalidCredentialsError
+		return nil, nil, u.failAuthentication(c, req)
+	}
+
+	// The failures are forgotten only once the second factor is verified too
+	if credentials.TwoFactor || domain.TwoFactorRequired(credentials.RoleID) {
+		ch, err := u.issueChallenge(c, credentials.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		ch.Enrolment = !credentials.TwoFactor
+
+		return nil, &user.AuthenticateRes{TwoFactor: ch}, nil
 	}
 
-	s, err := u.sessionUcase.Create(ctx, credentials.ID)
+	if err := u.lockoutUcase.Succeed(c, req.Email); err != nil {
+		return nil, nil, err
+	}
+
+	s, err := u.sessionUcase.Create(ctx, credentials.ID, req.IP, req.UserAgent)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -105,6 +148,13 @@ func (u *Usecase) Authenticate(ctx context.Context, req *user.AuthenticateReq) (
 	return s, res, nil
 }
 
+func (u *Usecase) failAuthentication(ctx context.Context, req *user.AuthenticateReq) error {
+	if err := u.lockoutUcase.Fail(ctx, req.Email, req.IP); err != nil {
+		return err
+	}
+	return user.InvalidCredentialsError
+}
+
 func (u *Usecase) GetTempToken(ss *domain.Session) (*user.TempToken, error) {
 	token, err := u.sessionUcase.GenTempToken(ss)
 	if err != nil {
@@ -144,14 +194,40 @@ func (u *Usecase) GetData(ctx context.Context, uid int) (*user.UserProfile, erro
 	return user, nil
 }
 
+// UpdateUser changes the password immediately, a new email only
+// takes effect once it is confirmed using the link sent to it.
 func (u *Usecase) UpdateUser(ctx context.Context, uid int, data *user.UpdateReq) (*user.UpdateRes, error) {
-	if len(data.Email) > 0 {
-		exists, err := u.userRepo.EmailExists(ctx, data.Email)
-		if err != nil || exists {
+	if err := u.validate.RawRequest(data); err != nil {
+		return nil, user.InvalidInputError
+	}
+
+	current, err := u.userRepo.GetData(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data.Email) > 0 && data.Email != current.Email {
+		err := u.validate.EmailUniqueness(ctx, data.Email)
+		if err != nil {
+			if err == user.ErrEmailExists {
+				return nil, user.EmailAlreadyExistsError
+			}
 			return nil, err
 		}
 
-		if err := u.userRepo.UpdateEmail(ctx, uid, data.Email); err != nil {
+		tx, err := u.userRepo.NewTx(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		defer tx.Rollback()
+
+		err = u.sendVerificationTx(ctx, tx, uid, data.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := tx.Commit(); err != nil {
 			return nil, err
 		}
 	}
@@ -172,7 +248,10 @@ func (u *Usecase) UpdateUser(ctx context.Context, uid int, data *user.UpdateReq)
 		return nil, err
 	}
 
-	return &user.UpdateRes{Email: result.Email}, nil
+	return &user.UpdateRes{
+		Email:        result.Email,
+		PendingEmail: result.PendingEmail,
+	}, nil
 }
 
 func (u *Usecase) GetTrips(ctx context.Context, uid int) ([]*user.TripsRes, error) {
@@ -184,50 +263,139 @@ func (u *Usecase) GetTrips(ctx context.Context, uid int) ([]*user.TripsRes, erro
 	trips := make([]*user.TripsRes, 0)
 	for _, t := range res {
 		trips = append(trips, &user.TripsRes{
-			ID:    t.ID,
-			Begin: t.Begin.Format(user.TripDateTimeFormat),
-			End:   t.End.Format(user.TripDateTimeFormat),
-			From:  "",
-			To:    "",
-			Price: t.Price,
+			ID:            t.ID,
+			Begin:         t.Begin.Format(user.TripDateTimeFormat),
+			End:           t.End.Format(user.TripDateTimeFormat),
+			From:          t.From,
+			To:            t.To,
+			Price:         t.Price,
+			PaymentStatus: t.PaymentStatus,
 		})
 	}
 
 	return trips, nil
 }
 
-func (u *Usecase) CheckPayment(ctx context.Context, uid int) (*user.PaymentRes, error) {
-	rand.Seed(time.Now().UnixNano())
+var statementLineTypes = map[domain.JournalEntryType]domain.StatementLineType{
+	domain.RefundJournalEntry:     domain.RefundStatementLine,
+	domain.FeeJournalEntry:        domain.FeeStatementLine,
+	domain.AdjustmentJournalEntry: domain.AdjustmentStatementLine,
+}
+
+// GetStatement summarizes a calendar month (UTC). Balances and totals come from the ledger,
+// trips and top-ups are listed with their current status, even if not settled yet.
+func (u *Usecase) GetStatement(ctx context.Context, uid int, req *user.StatementReq) (*user.StatementRes, error) {
+	if err := u.validate.RawRequest(req); err != nil {
+		return nil, user.InvalidInputError
+	}
 
-	amount := rand.Intn(50) * 100
-	var status string
+	from, err := time.Parse(user.StatementMonthFormat, req.Month)
+	if err != nil {
+		return nil, user.InvalidInputError
+	}
+	to := from.AddDate(0, 1, 0)
+
+	c, cancel := context.WithTimeout(ctx, u.ctxTimeout)
+	defer cancel()
+
+	opening, err := u.userRepo.GetBalanceAt(c, uid, from)
+	if err != nil {
+		return nil, err
+	}
+
+	trips, err := u.userRepo.GetTripsBetween(c, uid, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	topUps, err := u.userRepo.GetTopUpsBetween(c, uid, from, to)
+	if err != nil {
+		return nil, err
+	}
 
-	switch rand.Intn(2) {
-	case 0:
-		status = "successful"
-		if err := u.userRepo.AddPayment(ctx, uid, int64(amount)); err != nil {
+	entries, err := u.userRepo.GetWalletEntriesBetween(c, uid, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := domain.NewStatementTotals(opening.Currency)
+	lines := make([]*user.StatementLine, 0, len(trips)+len(topUps))
+
+	for _, t := range trips {
+		id := t.ID
+		lines = append(lines, &user.StatementLine{
+			Time:        t.End,
+			Type:        domain.TripStatementLine,
+			Reference:   &id,
+			Description: t.From + " - " + t.To,
+			Amount:      t.Price.Neg(),
+			Status:      t.PaymentStatus,
+		})
+	}
+
+	for _, p := range topUps {
+		id := p.ID
+		lines = append(lines, &user.StatementLine{
+			Time:        p.Created,
+			Type:        domain.TopUpStatementLine,
+			Reference:   &id,
+			Description: p.Provider,
+			Amount:      p.Amount,
+			Status:      p.Status,
+		})
+	}
+
+	for _, e := range entries {
+		if err := totals.Add(e); err != nil {
 			return nil, err
 		}
 
-		if err := u.userRepo.AddBalance(ctx, uid, int64(amount)); err != nil {
-			return nil, err
+		t, ok := statementLineTypes[e.Type]
+		if !ok {
+			continue
 		}
-	case 1:
-		status = "cancelled"
-		if err := u.userRepo.AddPayment(ctx, uid, 0); err != nil {
-			return nil, err
+
+		ref := e.TripID
+		if ref == nil {
+			ref = e.PaymentID
 		}
-	default:
-		return nil, fmt.Errorf("unknown action")
+
+		lines = append(lines, &user.StatementLine{
+			Time:        e.CreatedAt,
+			Type:        t,
+			Reference:   ref,
+			Description: e.Description,
+			Amount:      e.Amount,
+		})
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Time.Before(lines[j].Time)
+	})
+
+	sum, err := totals.Sum()
+	if err != nil {
+		return nil, err
 	}
 
-	data, err := u.GetData(ctx, uid)
+	closing, err := opening.Add(sum)
 	if err != nil {
 		return nil, err
 	}
 
-	return &user.PaymentRes{
-		Status:  status,
-		Balance: data.Balance,
+	return &user.StatementRes{
+		Month:          req.Month,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		ClosingBalance: closing,
+		Totals: &user.StatementTotals{
+			Trips:       totals.Trips,
+			TopUps:      totals.TopUps,
+			Refunds:     totals.Refunds,
+			Fees:        totals.Fees,
+			Adjustments: totals.Adjustments,
+		},
+		Lines: lines,
 	}, nil
 }
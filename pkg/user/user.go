@@ -15,11 +15,15 @@ type UserInfo struct {
 }
 
 type UserProfile struct {
-	ID            int       `json:"id"`
-	Balance       float32   `json:"balance"`
-	FirstName     string    `json:"firstName"`
-	LastName      string    `json:"lastName"`
-	Email         string    `json:"email"`
+	ID              int          `json:"id"`
+	Balance         domain.Money `json:"balance"`
+	PendingPayments domain.Money `json:"pendingPayments"`
+	FirstName       string       `json:"firstName"`
+	LastName        string       `json:"lastName"`
+	Email           string       `json:"email"`
+	EmailVerified   bool         `json:"emailVerified"`
+	// New email waiting for confirmation
+	PendingEmail  *string   `json:"pendingEmail,omitempty"`
 	Birthdate     BirthDate `json:"birthdate"`
 	LicenseStatus string    `json:"license,omitempty"`
 }
@@ -65,11 +69,27 @@ func (bd BirthDate) MarshalJSON() ([]byte, error) {
 type AuthenticateReq struct {
 	Email    string `json:"email" validate:"required,u_email"`
 	Password string `json:"password" validate:"required,u_password"`
+	// Non-browser clients get a bearer token instead of the session cookie
+	Bearer bool `json:"bearer"`
+	// Set by the delivery layer for the brute-force protection and the session list
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
+// AuthenticateRes carries TwoFactor instead of the user
+// when the session is issued only after the second step
 type AuthenticateRes struct {
-	UserID int         `json:"userID"`
-	RoleID domain.Role `json:"roleID"`
+	UserID    int                 `json:"userID,omitempty"`
+	RoleID    domain.Role         `json:"roleID,omitempty"`
+	TwoFactor *TwoFactorChallenge `json:"twoFactor,omitempty"`
+	// Set only for bearer requests
+	Token string `json:"token,omitempty"`
+}
+
+type TwoFactorChallenge struct {
+	Token string `json:"token"`
+	// The user has to enrol before verifying the code
+	Enrolment bool `json:"enrolment"`
 }
 
 // TempToken
@@ -81,12 +101,14 @@ type TempToken struct {
 // UpdateUser
 
 type UpdateReq struct {
-	Email    string `json:"email"`
+	Email    string `json:"email" validate:"omitempty,u_email"`
 	Password string `json:"password"`
 }
 
 type UpdateRes struct {
 	Email string `json:"email"`
+	// Set while the new email waits for confirmation
+	PendingEmail *string `json:"pendingEmail,omitempty"`
 }
 
 // GetTrips
@@ -94,17 +116,108 @@ type UpdateRes struct {
 const TripDateTimeFormat = "2006-01-02 15:04:05"
 
 type TripsRes struct {
-	ID    int     `json:"id"`
-	Begin string  `json:"begin_time"`
-	End   string  `json:"end_time"`
-	From  string  `json:"from"`
-	To    string  `json:"to"`
-	Price float32 `json:"price"`
+	ID            int          `json:"id"`
+	Begin         string       `json:"begin_time"`
+	End           string       `json:"end_time"`
+	From          string       `json:"from"`
+	To            string       `json:"to"`
+	Price         domain.Money `json:"price"`
+	PaymentStatus string       `json:"payment_status"`
+}
+
+// GetStatement
+
+const (
+	StatementMonthFormat = "2006-01"
+
+	JSONStatementFormat = "json"
+	CSVStatementFormat  = "csv"
+)
+
+type StatementReq struct {
+	Month  string `json:"month" validate:"required,len=7"`
+	Format string `json:"format" validate:"omitempty,oneof=json csv"`
+}
+
+type StatementTotals struct {
+	Trips       domain.Money `json:"trips"`
+	TopUps      domain.Money `json:"topUps"`
+	Refunds     domain.Money `json:"refunds"`
+	Fees        domain.Money `json:"fees"`
+	Adjustments domain.Money `json:"adjustments"`
+}
+
+type StatementLine struct {
+	Time        time.Time                `json:"time"`
+	Type        domain.StatementLineType `json:"type"`
+	Reference   *int                     `json:"reference"`
+	Description string                   `json:"description"`
+	Amount      domain.Money             `json:"amount"`
+	Status      string                   `json:"status"`
+}
+
+type StatementRes struct {
+	Month          string           `json:"month"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	OpeningBalance domain.Money     `json:"openingBalance"`
+	ClosingBalance domain.Money     `json:"closingBalance"`
+	Totals         *StatementTotals `json:"totals"`
+	Lines          []*StatementLine `json:"lines"`
+}
+
+// EmailVerification
+
+type VerifyEmailReq struct {
+	Token string `json:"token" validate:"required,len=64,hexadecimal"`
 }
 
-// Payment
+// PasswordReset
+
+type ForgotPasswordReq struct {
+	Email string `json:"email" validate:"required,u_email"`
+}
+
+type ResetPasswordReq struct {
+	Token    string `json:"token" validate:"required,len=64,hexadecimal"`
+	Password string `json:"password" validate:"required,u_password"`
+}
+
+// TwoFactor
+
+type TwoFactorSetupRes struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TwoFactorCodeReq struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type TwoFactorChallengeReq struct {
+	Challenge string `json:"challenge" validate:"required,len=64,hexadecimal"`
+}
+
+// Code is either a TOTP code or a backup code
+type TwoFactorVerifyReq struct {
+	Challenge string `json:"challenge" validate:"required,len=64,hexadecimal"`
+	Code      string `json:"code" validate:"required,min=6,max=11"`
+	Bearer    bool   `json:"bearer"`
+	// Set by the delivery layer for the brute-force protection and the session list
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type TwoFactorVerifyRes struct {
+	*AuthenticateRes
+	// Set only when the verification completed the enrolment
+	BackupCodes []string `json:"backupCodes,omitempty"`
+}
+
+type BackupCodesRes struct {
+	BackupCodes []string `json:"backupCodes"`
+}
 
-type PaymentRes struct {
-	Status  string  `json:"status"`
-	Balance float32 `json:"balance"`
+type ResetTwoFactorReq struct {
+	UserID int `json:"userID" validate:"required,gt=0"`
 }